@@ -0,0 +1,553 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/catalog"
+)
+
+// field kinds needing special handling in generated code.
+const (
+	kdScalar  = iota
+	kdDecimal // driver.Decimal: scan destination needs to be initialized
+	kdLob     // driver.Lob: scan destination needs a writer
+	kdSpatial // spatial types: transferred as EWKB
+	kdTable   // table parameters
+)
+
+const (
+	importDriver  = "github.com/SAP/go-hdb/driver"
+	importSpatial = "github.com/SAP/go-hdb/driver/spatial"
+	importBytes   = "bytes"
+	importSQL     = "database/sql"
+	importTime    = "time"
+)
+
+// goType is the go type of a column or parameter.
+type goType struct {
+	name     string
+	kind     int
+	nullable bool
+	imports  []string
+}
+
+func newGoType(typeName string, dt driver.DataType, nullable bool) *goType {
+	choose := func(notNull, null string, imports ...string) *goType {
+		if nullable {
+			return &goType{name: null, nullable: true, imports: imports}
+		}
+		return &goType{name: notNull, imports: imports}
+	}
+
+	switch typeName {
+	case "ST_GEOMETRY", "ST_POINT":
+		return &goType{name: "[]byte", kind: kdSpatial, nullable: nullable, imports: []string{importBytes, importDriver, importSpatial}}
+	case "TABLE_TYPE":
+		return &goType{name: "sql.Rows", kind: kdTable, imports: []string{importSQL}}
+	}
+
+	switch dt {
+	case driver.DtBoolean:
+		return choose("bool", "sql.NullBool", importSQL)
+	case driver.DtTinyint:
+		return choose("uint8", "sql.NullByte", importSQL)
+	case driver.DtSmallint:
+		return choose("int16", "sql.NullInt16", importSQL)
+	case driver.DtInteger:
+		return choose("int32", "sql.NullInt32", importSQL)
+	case driver.DtBigint:
+		return choose("int64", "sql.NullInt64", importSQL)
+	case driver.DtReal:
+		return choose("float32", "sql.NullFloat64", importSQL)
+	case driver.DtDouble:
+		return choose("float64", "sql.NullFloat64", importSQL)
+	case driver.DtDecimal:
+		t := choose("driver.Decimal", "driver.NullDecimal", importDriver)
+		t.kind = kdDecimal
+		return t
+	case driver.DtTime:
+		if nullable {
+			return &goType{name: "sql.NullTime", nullable: true, imports: []string{importSQL}}
+		}
+		return &goType{name: "time.Time", imports: []string{importTime}}
+	case driver.DtBytes:
+		return choose("[]byte", "driver.NullBytes", importDriver)
+	case driver.DtLob:
+		t := choose("driver.Lob", "driver.NullLob", importDriver, importBytes)
+		t.kind = kdLob
+		return t
+	default:
+		return choose("string", "sql.NullString", importSQL)
+	}
+}
+
+// initExpr returns the initialization expression of a scan destination (empty if no initialization is needed).
+func (t *goType) initExpr() string {
+	switch {
+	case t.kind == kdDecimal && t.nullable:
+		return "driver.NullDecimal{Decimal: new(driver.Decimal)}"
+	case t.kind == kdLob && t.nullable:
+		return "driver.NullLob{Lob: driver.NewLob(nil, new(bytes.Buffer))}"
+	case t.kind == kdLob:
+		return "*driver.NewLob(nil, new(bytes.Buffer))"
+	default:
+		return ""
+	}
+}
+
+// goName converts a database object name to an exported go identifier (e.g. ORDER_ITEM_ID -> OrderItemID).
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool { return !(unicode.IsLetter(r) || unicode.IsDigit(r)) })
+	var b strings.Builder
+	for _, part := range parts {
+		upper := strings.ToUpper(part)
+		switch upper {
+		case "ID", "URL", "UUID", "JSON", "XML", "SQL", "API":
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(strings.ToLower(part))
+		r[0] = unicode.ToUpper(r[0])
+		b.WriteString(string(r))
+	}
+	s := b.String()
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// lowerFirst returns s with the first letter converted to lower case.
+func lowerFirst(s string) string {
+	r := []rune(s)
+	for i := 0; i < len(r) && unicode.IsUpper(r[i]); i++ {
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	s = string(r)
+	switch s {
+	case "type", "func", "var", "range", "map", "select", "case", "default", "go", "chan", "const", "package", "import", "interface", "struct", "return", "if", "else", "for", "switch", "break", "continue", "defer", "fallthrough", "goto":
+		return s + "_"
+	}
+	return s
+}
+
+// uniqueNames makes the names unique by appending a number to duplicate names.
+// Generated names do not collide with any other name.
+func uniqueNames(names []string) []string {
+	used := make(map[string]bool, len(names))
+	for _, name := range names {
+		used[name] = true
+	}
+	seen := map[string]int{} // last number per duplicate name
+	for i, name := range names {
+		n, ok := seen[name]
+		if !ok {
+			seen[name] = 1
+			continue
+		}
+		for {
+			n++
+			if s := fmt.Sprintf("%s%d", name, n); !used[s] {
+				names[i] = s
+				used[s] = true
+				break
+			}
+		}
+		seen[name] = n
+	}
+	return names
+}
+
+// genField is a generated struct field or function parameter.
+type genField struct {
+	Name       string // go name
+	DBName     string // database name
+	Type       string
+	Init       string
+	IsSpatial  bool
+	IsTable    bool
+	IsNullable bool
+}
+
+func newGenField(name, dbName string, t *goType) *genField {
+	return &genField{Name: name, DBName: dbName, Type: t.name, Init: t.initExpr(), IsSpatial: t.kind == kdSpatial, IsTable: t.kind == kdTable, IsNullable: t.nullable}
+}
+
+type genTable struct {
+	Name      string // go name
+	DBName    string // quoted, schema qualified database name
+	Fields    []*genField
+	HasInit   bool
+	SelectSQL string // select list
+	InsertSQL string // insert column list
+	ValueSQL  string // insert value list
+}
+
+type genProcedure struct {
+	Name      string
+	DBName    string
+	InFields  []*genField // scalar input parameters
+	OutFields []*genField // output parameters
+	CallSQL   string      // call statement (%s placeholders for table input parameters)
+	TableIns  []*genField // table input parameters (passed as table names)
+	HasInit   bool
+	HasTables bool // procedure has table output parameters
+}
+
+type generator struct {
+	pkg        string
+	imports    map[string]bool
+	tables     []*genTable
+	procedures []*genProcedure
+	spatial    bool
+}
+
+func newGenerator(pkg string) *generator {
+	return &generator{pkg: pkg, imports: map[string]bool{"context": true, importSQL: true}}
+}
+
+func (g *generator) addImports(imports []string) {
+	for _, imp := range imports {
+		g.imports[imp] = true
+	}
+}
+
+func qualifiedName(schema, name string) string {
+	return driver.Identifier(schema).String() + "." + driver.Identifier(name).String()
+}
+
+func (g *generator) addTable(t *catalog.Table) {
+	gt := &genTable{Name: goName(t.Name), DBName: qualifiedName(t.SchemaName, t.Name)}
+
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = goName(c.Name)
+	}
+	names = uniqueNames(names)
+
+	selects := make([]string, len(t.Columns))
+	inserts := make([]string, len(t.Columns))
+	values := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		typ := newGoType(c.TypeName, c.DataType, c.Nullable)
+		g.addImports(typ.imports)
+		f := newGenField(names[i], c.Name, typ)
+		gt.Fields = append(gt.Fields, f)
+		gt.HasInit = gt.HasInit || f.Init != ""
+
+		column := driver.Identifier(c.Name).String()
+		selects[i], inserts[i], values[i] = column, column, "?"
+		if f.IsSpatial {
+			g.spatial = true
+			selects[i], values[i] = column+".st_asewkb()", "st_geomfromewkb(?)"
+		}
+	}
+	gt.SelectSQL = strings.Join(selects, ", ")
+	gt.InsertSQL = strings.Join(inserts, ", ")
+	gt.ValueSQL = strings.Join(values, ", ")
+	g.tables = append(g.tables, gt)
+}
+
+func (g *generator) addProcedure(proc *catalog.Procedure) {
+	gp := &genProcedure{Name: goName(proc.Name), DBName: qualifiedName(proc.SchemaName, proc.Name)}
+
+	names := make([]string, len(proc.Parameters))
+	for i, prm := range proc.Parameters {
+		names[i] = goName(prm.Name)
+	}
+	names = uniqueNames(names)
+
+	args := make([]string, len(proc.Parameters))
+	for i, prm := range proc.Parameters {
+		var typ *goType
+		if prm.IsTable() {
+			typ = &goType{name: "sql.Rows", kind: kdTable}
+		} else {
+			typ = newGoType(prm.TypeName, prm.DataType, prm.Nullable)
+		}
+		f := newGenField(names[i], prm.Name, typ)
+
+		args[i] = "?"
+		if f.IsSpatial {
+			g.spatial = true
+		}
+
+		switch {
+		case prm.Mode == catalog.ParameterIn && f.IsTable:
+			f.Name, f.Type = lowerFirst(f.Name), "driver.Identifier"
+			g.addImports([]string{importDriver})
+			args[i] = "%s"
+			gp.TableIns = append(gp.TableIns, f)
+			continue
+		case f.IsTable:
+			gp.HasTables = true
+		default:
+			g.addImports(typ.imports)
+		}
+
+		if prm.Mode == catalog.ParameterIn || prm.Mode == catalog.ParameterInOut {
+			in := *f
+			in.Name = lowerFirst(f.Name)
+			gp.InFields = append(gp.InFields, &in)
+		}
+		if prm.Mode == catalog.ParameterOut || prm.Mode == catalog.ParameterInOut {
+			gp.OutFields = append(gp.OutFields, f)
+			gp.HasInit = gp.HasInit || f.Init != ""
+		}
+	}
+	gp.CallSQL = fmt.Sprintf("call %s(%s)", gp.DBName, strings.Join(args, ", "))
+	g.procedures = append(g.procedures, gp)
+}
+
+func (g *generator) Package() string { return g.pkg }
+
+// Imports returns the standard library and the external imports.
+func (g *generator) Imports() [][]string {
+	var std, ext []string
+	for imp := range g.imports {
+		if strings.Contains(imp, ".") {
+			ext = append(ext, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(ext)
+	if len(ext) == 0 {
+		return [][]string{std}
+	}
+	return [][]string{std, ext}
+}
+
+func (g *generator) Tables() []*genTable         { return g.tables }
+func (g *generator) Procedures() []*genProcedure { return g.procedures }
+func (g *generator) Spatial() bool               { return g.spatial }
+
+var templ = template.Must(template.New("").Funcs(template.FuncMap{
+	"quote": func(s string) string { return "`" + s + "`" },
+}).Parse(`// Code generated by hdbgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range $i, $group := .Imports}}{{if $i}}
+{{end}}
+{{- range $group}}
+	"{{.}}"
+{{- end}}{{end}}
+)
+
+// DB is implemented by sql.DB, sql.Conn and sql.Tx.
+type DB interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+{{if .Spatial}}
+// spatialValue returns the database value of an EWKB encoded spatial value.
+func spatialValue(ewkb []byte) interface{} {
+	if ewkb == nil {
+		return nil
+	}
+	return driver.NewLob(bytes.NewReader(ewkb), nil)
+}
+
+// spatialScanner scans an EWKB encoded spatial value.
+type spatialScanner struct {
+	ewkb *[]byte
+}
+
+func (s spatialScanner) Scan(src interface{}) error {
+	if src == nil {
+		*s.ewkb = nil
+		return nil
+	}
+	b := new(bytes.Buffer)
+	if err := driver.NewLob(nil, b).Scan(src); err != nil {
+		return err
+	}
+	*s.ewkb = b.Bytes()
+	return nil
+}
+
+// encodeSpatial returns the EWKB encoding of a spatial value.
+func encodeSpatial(g spatial.Geometry, srid int32) ([]byte, error) {
+	if g == nil {
+		return nil, nil
+	}
+	return spatial.EncodeEWKB(g, false, srid)
+}
+{{end}}
+{{- range $t := .Tables}}
+// {{.Name}} represents a row of table {{.DBName}}.
+{{- if .HasInit}}
+// Please use New{{.Name}} to create scan destinations.
+{{- end}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `db:"{{.DBName}}"` + "`" + `{{if .IsSpatial}} // EWKB encoded spatial value{{end}}
+{{- end}}
+}
+{{if .HasInit}}
+// New{{.Name}} returns a new {{.Name}} instance with initialized decimal and large object fields.
+// The content of large object fields is read into a bytes.Buffer (see driver.Lob.Writer).
+func New{{.Name}}() *{{.Name}} {
+	return &{{.Name}}{
+{{- range .Fields}}{{if .Init}}
+		{{.Name}}: {{.Init}},
+{{- end}}{{end}}
+	}
+}
+{{end}}
+{{- range .Fields}}{{if .IsSpatial}}
+// Set{{.Name}} sets the EWKB encoded spatial value of field {{.Name}}.
+func (r *{{$t.Name}}) Set{{.Name}}(g spatial.Geometry, srid int32) (err error) {
+	r.{{.Name}}, err = encodeSpatial(g, srid)
+	return err
+}
+{{end}}{{end}}
+// Insert{{.Name}} inserts r into table {{.DBName}}.
+// Large object values need to be inserted within a transaction.
+func Insert{{.Name}}(ctx context.Context, db DB, r *{{.Name}}) error {
+	_, err := db.ExecContext(ctx, {{quote (printf "insert into %s (%s) values (%s)" .DBName .InsertSQL .ValueSQL)}},
+{{- range .Fields}}
+		{{if .IsSpatial}}spatialValue(r.{{.Name}}){{else}}r.{{.Name}}{{end}},
+{{- end}}
+	)
+	return err
+}
+
+// Select{{.Name}} selects rows of table {{.DBName}}.
+// The optional clause (e.g. "where ... order by ...") is appended to the select statement, args are the clause arguments.
+func Select{{.Name}}(ctx context.Context, db DB, clause string, args ...interface{}) ([]*{{.Name}}, error) {
+	rows, err := db.QueryContext(ctx, {{quote (printf "select %s from %s " .SelectSQL .DBName)}}+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*{{.Name}}
+	for rows.Next() {
+		r := {{if .HasInit}}New{{.Name}}(){{else}}&{{.Name}}{}{{end}}
+		if err := rows.Scan(
+{{- range .Fields}}
+			{{if .IsSpatial}}spatialScanner{&r.{{.Name}}}{{else}}&r.{{.Name}}{{end}},
+{{- end}}
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+{{end}}
+{{- range .Procedures}}
+{{- if .OutFields}}
+// {{.Name}}Out contains the output parameters of procedure {{.DBName}}.
+{{- if .HasTables}}
+// Table output parameters can be read until Close is called.
+{{- end}}
+type {{.Name}}Out struct {
+{{- range .OutFields}}
+	{{.Name}} {{.Type}}{{if .IsSpatial}} // EWKB encoded spatial value{{end}}
+{{- end}}
+{{- if .HasTables}}
+
+	rows *sql.Rows
+{{- end}}
+}
+{{if .HasTables}}
+// Close closes the table output parameters.
+func (o *{{.Name}}Out) Close() error {
+{{- range .OutFields}}{{if .IsTable}}
+	o.{{.Name}}.Close()
+{{- end}}{{end}}
+	return o.rows.Close()
+}
+{{end}}{{end}}
+// Call{{.Name}} calls procedure {{.DBName}}.
+{{- if .TableIns}}
+// Table input parameters are passed as table or view names.
+{{- end}}
+{{- if .HasTables}}
+// The table output parameters require the connector legacy mode to be switched off (default).
+{{- end}}
+func Call{{.Name}}(ctx context.Context, db DB{{range .TableIns}}, {{.Name}} {{.Type}}{{end}}{{range .InFields}}, {{.Name}} {{.Type}}{{end}}) {{if .OutFields}}(*{{.Name}}Out, error){{else}}error{{end}} {
+	query := {{if .TableIns}}fmt.Sprintf({{quote .CallSQL}}{{range .TableIns}}, {{.Name}}{{end}}){{else}}{{quote .CallSQL}}{{end}}
+{{- if not .OutFields}}
+	_, err := db.ExecContext(ctx, query{{range .InFields}}, {{if .IsSpatial}}spatialValue({{.Name}}){{else}}{{.Name}}{{end}}{{end}})
+	return err
+}
+{{- else}}
+	rows, err := db.QueryContext(ctx, query{{range .InFields}}, {{if .IsSpatial}}spatialValue({{.Name}}){{else}}{{.Name}}{{end}}{{end}})
+	if err != nil {
+		return nil, err
+	}
+{{- if not .HasTables}}
+	defer rows.Close()
+{{- end}}
+
+	out := &{{.Name}}Out{
+{{- range .OutFields}}{{if .Init}}
+		{{.Name}}: {{.Init}},
+{{- end}}{{end}}
+{{- if .HasTables}}
+		rows: rows,
+{{- end}}
+	}
+	if !rows.Next() {
+		err := rows.Err()
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+{{- if .HasTables}}
+		rows.Close()
+{{- end}}
+		return nil, err
+	}
+	if err := rows.Scan(
+{{- range .OutFields}}
+		{{if .IsSpatial}}spatialScanner{&out.{{.Name}}}{{else}}&out.{{.Name}}{{end}},
+{{- end}}
+	); err != nil {
+{{- if .HasTables}}
+		rows.Close()
+{{- end}}
+		return nil, err
+	}
+	return out, nil
+}
+{{- end}}
+{{end}}
+`))
+
+// generate returns the formatted go source code.
+func (g *generator) generate() ([]byte, error) {
+	for _, proc := range g.procedures {
+		if len(proc.TableIns) != 0 {
+			g.imports["fmt"] = true
+		}
+	}
+	var b bytes.Buffer
+	if err := templ.Execute(&b, g); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return b.Bytes(), fmt.Errorf("invalid generated code: %w", err)
+	}
+	return src, nil
+}
@@ -0,0 +1,165 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/catalog"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGoName(t *testing.T) {
+	testData := []struct {
+		name, goName string
+	}{
+		{"ORDER_ITEM_ID", "OrderItemID"},
+		{"order_item_id", "OrderItemID"},
+		{"customerName", "Customername"},
+		{"JSON_DOC", "JSONDoc"},
+		{"A.B-C", "ABC"},
+		{"1ST_VALUE", "X1stValue"},
+		{"_", "X"},
+	}
+
+	for _, d := range testData {
+		if goName := goName(d.name); goName != d.goName {
+			t.Fatalf("name %s - got %s - expected %s", d.name, goName, d.goName)
+		}
+	}
+}
+
+func TestLowerFirst(t *testing.T) {
+	testData := []struct {
+		s, lower string
+	}{
+		{"OrderID", "orderID"},
+		{"ID", "id"},
+		{"JSONDoc", "jsonDoc"},
+		{"Type", "type_"},
+		{"Range", "range_"},
+		{"x", "x"},
+	}
+
+	for _, d := range testData {
+		if lower := lowerFirst(d.s); lower != d.lower {
+			t.Fatalf("s %s - got %s - expected %s", d.s, lower, d.lower)
+		}
+	}
+}
+
+func TestUniqueNames(t *testing.T) {
+	names := uniqueNames([]string{"A", "B", "A", "A", "C"})
+	if expected := []string{"A", "B", "A2", "A3", "C"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("got %v - expected %v", names, expected)
+	}
+	// generated names must not collide with other names
+	names = uniqueNames([]string{"A", "A2", "A"})
+	if expected := []string{"A", "A2", "A3"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("got %v - expected %v", names, expected)
+	}
+	names = uniqueNames([]string{"A", "A", "A2"})
+	if expected := []string{"A", "A3", "A2"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("got %v - expected %v", names, expected)
+	}
+}
+
+func testGenerator() *generator {
+	g := newGenerator("model")
+	g.addTable(&catalog.Table{
+		SchemaName: "MYSCHEMA",
+		Name:       "ORDER_ITEMS",
+		Columns: []*catalog.Column{
+			{Name: "ID", TypeName: "BIGINT", DataType: driver.DtBigint},
+			{Name: "ORDER_ID", TypeName: "INTEGER", DataType: driver.DtInteger},
+			{Name: "AMOUNT", TypeName: "DECIMAL", DataType: driver.DtDecimal, Nullable: true},
+			{Name: "NOTE", TypeName: "NCLOB", DataType: driver.DtLob, Nullable: true},
+			{Name: "CREATED_AT", TypeName: "TIMESTAMP", DataType: driver.DtTime},
+			{Name: "LOCATION", TypeName: "ST_POINT", DataType: driver.DtBytes, Nullable: true},
+			{Name: "order-id", TypeName: "NVARCHAR", DataType: driver.DtString},
+		},
+	})
+	g.addProcedure(&catalog.Procedure{
+		SchemaName: "MYSCHEMA",
+		Name:       "GET_ORDERS",
+		Parameters: []*catalog.Parameter{
+			{Name: "IN_TAB", Mode: catalog.ParameterIn, TypeName: "TABLE_TYPE", TableTypeName: "TT_IDS"},
+			{Name: "TYPE", Mode: catalog.ParameterIn, TypeName: "NVARCHAR", DataType: driver.DtString},
+			{Name: "TOTAL", Mode: catalog.ParameterInOut, TypeName: "DECIMAL", DataType: driver.DtDecimal},
+			{Name: "ORDERS", Mode: catalog.ParameterOut, TypeName: "TABLE_TYPE", TableTypeName: "TT_ORDERS"},
+			{Name: "COUNT", Mode: catalog.ParameterOut, TypeName: "INTEGER", DataType: driver.DtInteger, Nullable: true},
+		},
+	})
+	return g
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := testGenerator().generate()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, src)
+	}
+
+	// generated code is formatted
+	formatted, err := format.Source(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(formatted, src) {
+		t.Fatal("generated code is not formatted")
+	}
+
+	// generated code declares the expected types and functions
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "model.go", src, parser.AllErrors)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, src)
+	}
+	if f.Name.Name != "model" {
+		t.Fatalf("package %s - expected model", f.Name.Name)
+	}
+	decls := map[string]bool{}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			decls[decl.Name.Name] = true
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if spec, ok := spec.(*ast.TypeSpec); ok {
+					decls[spec.Name.Name] = true
+				}
+			}
+		}
+	}
+	for _, name := range []string{"DB", "OrderItems", "NewOrderItems", "InsertOrderItems", "SelectOrderItems", "SetLocation", "GetOrdersOut", "CallGetOrders"} {
+		if !decls[name] {
+			t.Fatalf("declaration %s not found in generated code\n%s", name, src)
+		}
+	}
+
+	golden := filepath.Join("testdata", "model.golden")
+	if *update {
+		if err := os.WriteFile(golden, src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, expected) {
+		t.Fatalf("generated code differs from %s (run go test -update to update)\n%s", golden, src)
+	}
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitList(t *testing.T) {
+	testData := []struct {
+		s    string
+		list []string
+	}{
+		{"", nil},
+		{"-", []string{"-"}},
+		{"ORDERS", []string{"ORDERS"}},
+		{" ORDERS, ITEMS ,,", []string{"ORDERS", "ITEMS"}},
+	}
+
+	for _, d := range testData {
+		if list := splitList(d.s); !reflect.DeepEqual(list, d.list) {
+			t.Fatalf("list %q - got %q - expected %q", d.s, list, d.list)
+		}
+	}
+}
+
+func TestSelected(t *testing.T) {
+	testData := []struct {
+		list     []string
+		name     string
+		selected bool
+	}{
+		{nil, "ORDERS", true},
+		{[]string{"ORDERS", "ITEMS"}, "ITEMS", true},
+		{[]string{"ORDERS", "ITEMS"}, "items", false},
+		{[]string{"-"}, "ORDERS", false},
+	}
+
+	for _, d := range testData {
+		if selected := selected(d.list, d.name); selected != d.selected {
+			t.Fatalf("list %q name %s - got %t - expected %t", d.list, d.name, selected, d.selected)
+		}
+	}
+	if !skip([]string{"-"}) || skip(nil) {
+		t.Fatal("unexpected skip result")
+	}
+}
@@ -0,0 +1,237 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+const helpText = `SQL statements are terminated by a semicolon and can span multiple lines.
+
+Commands:
+  \q                 quit
+  \h, \?             show this help
+  \d                 list tables and views of the current schema
+  \d NAME            describe table or view NAME (current schema or SCHEMA.NAME)
+  \dn                list schemas
+  \dp                list procedures of the current schema
+  \ds                list sequences of the current schema
+  \format [FORMAT]   show or set output format (table, csv or json)
+  \timing            toggle display of client and server execution time
+  \lobdir [DIR]      write large objects to files in DIR (no DIR: display large objects)
+  \begin             start a transaction
+  \commit            commit the transaction
+  \rollback          rollback the transaction
+  \i FILE            execute script FILE
+`
+
+var errQuit = errors.New("quit")
+
+// command executes a backslash command.
+func (s *session) command(ctx context.Context, line string) error {
+	fields := strings.Fields(line)
+	cmd, args := fields[0], fields[1:]
+	arg := strings.Join(args, " ")
+
+	switch cmd {
+	case `\q`:
+		return errQuit
+	case `\h`, `\?`:
+		fmt.Fprint(s.out, helpText)
+	case `\d`:
+		if arg == "" {
+			return s.listRelations(ctx)
+		}
+		return s.describe(ctx, arg)
+	case `\dn`:
+		return s.listSchemas(ctx)
+	case `\dp`:
+		return s.listProcedures(ctx)
+	case `\ds`:
+		return s.listSequences(ctx)
+	case `\format`:
+		if arg == "" {
+			fmt.Fprintf(s.out, "output format: %s\n", s.format)
+			return nil
+		}
+		if err := checkFormat(arg); err != nil {
+			return err
+		}
+		s.format = arg
+	case `\timing`:
+		s.timing = !s.timing
+		fmt.Fprintf(s.out, "timing: %t\n", s.timing)
+	case `\lobdir`:
+		if arg != "" {
+			if fi, err := os.Stat(arg); err != nil || !fi.IsDir() {
+				return fmt.Errorf("invalid directory %s", arg)
+			}
+		}
+		s.lobDir = arg
+	case `\begin`:
+		return s.begin(ctx)
+	case `\commit`:
+		return s.commit()
+	case `\rollback`:
+		return s.rollback()
+	case `\i`:
+		if arg == "" {
+			return fmt.Errorf("missing file name")
+		}
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			return err
+		}
+		return s.executeScript(ctx, string(b))
+	default:
+		return fmt.Errorf("invalid command %s - use \\h for help", cmd)
+	}
+	return nil
+}
+
+func (s *session) currentSchema(ctx context.Context) (string, error) {
+	var schema string
+	err := s.queryer().QueryRowContext(ctx, "select current_schema from dummy").Scan(&schema)
+	return schema, err
+}
+
+func (s *session) newTabWriter() *tabwriter.Writer {
+	return tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+}
+
+func (s *session) listRelations(ctx context.Context) error {
+	schema, err := s.currentSchema(ctx)
+	if err != nil {
+		return err
+	}
+	cat := s.catalog()
+	tables, err := cat.Tables(ctx, schema)
+	if err != nil {
+		return err
+	}
+	views, err := cat.Views(ctx, schema)
+	if err != nil {
+		return err
+	}
+	w := s.newTabWriter()
+	fmt.Fprintln(w, "SCHEMA\tNAME\tTYPE")
+	for _, t := range tables {
+		typ := "ROW TABLE"
+		if t.ColumnTable {
+			typ = "COLUMN TABLE"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", t.SchemaName, t.Name, typ)
+	}
+	for _, v := range views {
+		fmt.Fprintf(w, "%s\t%s\tVIEW\n", v.SchemaName, v.Name)
+	}
+	return w.Flush()
+}
+
+// splitName splits a (schema qualified) object name. Unquoted names are converted to upper case.
+func splitName(name string) (string, string) {
+	convert := func(s string) string {
+		if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+			return s[1 : len(s)-1]
+		}
+		return strings.ToUpper(s)
+	}
+	if i := strings.LastIndex(name, "."); i != -1 {
+		return convert(name[:i]), convert(name[i+1:])
+	}
+	return "", convert(name)
+}
+
+func (s *session) describe(ctx context.Context, name string) error {
+	schema, objName := splitName(name)
+	if schema == "" {
+		var err error
+		if schema, err = s.currentSchema(ctx); err != nil {
+			return err
+		}
+	}
+	cols, err := s.catalog().Columns(ctx, schema, objName)
+	if err != nil {
+		return err
+	}
+	if len(cols) == 0 {
+		return fmt.Errorf("table or view %s.%s not found", schema, objName)
+	}
+	w := s.newTabWriter()
+	fmt.Fprintln(w, "COLUMN\tTYPE\tNULLABLE\tDEFAULT")
+	for _, c := range cols {
+		typeName := c.TypeName
+		switch {
+		case c.DataType == driver.DtDecimal && c.Length != 0:
+			typeName = fmt.Sprintf("%s(%d,%d)", typeName, c.Length, c.Scale)
+		case (c.DataType == driver.DtString || c.DataType == driver.DtBytes) && c.Length != 0:
+			typeName = fmt.Sprintf("%s(%d)", typeName, c.Length)
+		}
+		defaultValue := ""
+		if c.DefaultValue.Valid {
+			defaultValue = c.DefaultValue.String
+		}
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\n", c.Name, typeName, c.Nullable, defaultValue)
+	}
+	return w.Flush()
+}
+
+func (s *session) listSchemas(ctx context.Context) error {
+	schemas, err := s.catalog().Schemas(ctx)
+	if err != nil {
+		return err
+	}
+	w := s.newTabWriter()
+	fmt.Fprintln(w, "SCHEMA\tOWNER")
+	for _, schema := range schemas {
+		fmt.Fprintf(w, "%s\t%s\n", schema.Name, schema.Owner)
+	}
+	return w.Flush()
+}
+
+func (s *session) listProcedures(ctx context.Context) error {
+	schema, err := s.currentSchema(ctx)
+	if err != nil {
+		return err
+	}
+	procs, err := s.catalog().Procedures(ctx, schema)
+	if err != nil {
+		return err
+	}
+	w := s.newTabWriter()
+	fmt.Fprintln(w, "SCHEMA\tNAME\tPARAMETERS")
+	for _, proc := range procs {
+		prms := make([]string, len(proc.Parameters))
+		for i, prm := range proc.Parameters {
+			prms[i] = fmt.Sprintf("%s %s %s", prm.Mode, prm.Name, prm.TypeName)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", proc.SchemaName, proc.Name, strings.Join(prms, ", "))
+	}
+	return w.Flush()
+}
+
+func (s *session) listSequences(ctx context.Context) error {
+	schema, err := s.currentSchema(ctx)
+	if err != nil {
+		return err
+	}
+	seqs, err := s.catalog().Sequences(ctx, schema)
+	if err != nil {
+		return err
+	}
+	w := s.newTabWriter()
+	fmt.Fprintln(w, "SCHEMA\tNAME\tSTART\tINCREMENT\tMIN\tMAX\tCYCLE")
+	for _, seq := range seqs {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%t\n", seq.SchemaName, seq.Name, seq.StartValue, seq.IncrementBy, seq.MinValue, seq.MaxValue, seq.Cycle)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,235 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/SAP/go-hdb/driver"
+	"github.com/SAP/go-hdb/driver/export"
+)
+
+// output formats.
+const (
+	fmtTable = "table"
+	fmtCSV   = "csv"
+	fmtJSON  = "json"
+)
+
+func checkFormat(format string) error {
+	switch format {
+	case fmtTable, fmtCSV, fmtJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %s (table, csv or json)", format)
+	}
+}
+
+const (
+	nullText        = "?"
+	maxColumnWidth  = 64
+	timestampFormat = "2006-01-02 15:04:05.0000000"
+)
+
+var (
+	lobType     = reflect.TypeOf((*driver.Lob)(nil)).Elem()
+	decimalType = reflect.TypeOf((*driver.Decimal)(nil)).Elem()
+)
+
+// cell is the scan destination of a field value.
+type cell struct {
+	v interface{}
+}
+
+func (c *cell) Scan(src interface{}) error { c.v = src; return nil }
+
+// column formats the values of a result column.
+type column struct {
+	name     string
+	typeName string
+	isLob    bool
+	scale    int
+}
+
+func newColumn(ct *sql.ColumnType) *column {
+	c := &column{name: ct.Name(), typeName: ct.DatabaseTypeName(), scale: -1}
+	switch ct.ScanType() {
+	case lobType:
+		c.isLob = true
+	case decimalType:
+		if _, scale, ok := ct.DecimalSize(); ok {
+			c.scale = int(scale)
+		}
+	}
+	return c
+}
+
+func (c *column) isCharLob() bool {
+	switch c.typeName {
+	case "CLOB", "NCLOB", "TEXT", "NLOCATOR":
+		return true
+	default:
+		return false
+	}
+}
+
+// text returns the text representation of a value. Large objects are written to a file in lobDir
+// if lobDir is set.
+func (c *column) text(v interface{}, lobDir string, row int) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return nullText, nil
+	case string:
+		return v, nil
+	case []byte:
+		return strings.ToUpper(hex.EncodeToString(v)), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case *big.Rat:
+		if c.scale >= 0 && c.typeName != "SMALLDECIMAL" {
+			return v.FloatString(c.scale), nil
+		}
+		return strings.TrimRight(strings.TrimRight(v.FloatString(34), "0"), "."), nil
+	case time.Time:
+		switch c.typeName {
+		case "DATE", "DAYDATE":
+			return v.Format("2006-01-02"), nil
+		case "TIME", "SECONDTIME":
+			return v.Format("15:04:05"), nil
+		default:
+			return v.Format(timestampFormat), nil
+		}
+	}
+	if !c.isLob {
+		return fmt.Sprintf("%v", v), nil
+	}
+
+	if lobDir != "" {
+		name := filepath.Join(lobDir, fmt.Sprintf("%s_%d.lob", c.name, row))
+		f, err := os.Create(name)
+		if err != nil {
+			return "", err
+		}
+		if err := driver.NewLob(nil, f).Scan(v); err != nil {
+			f.Close()
+			return "", err
+		}
+		return name, f.Close()
+	}
+	b := &bytes.Buffer{}
+	if err := driver.NewLob(nil, b).Scan(v); err != nil {
+		return "", err
+	}
+	if c.isCharLob() {
+		return b.String(), nil
+	}
+	return strings.ToUpper(hex.EncodeToString(b.Bytes())), nil
+}
+
+// truncate truncates s to the maximum column width and replaces line breaks.
+func truncate(s string) string {
+	s = strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(s)
+	if utf8.RuneCountInString(s) <= maxColumnWidth {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:maxColumnWidth-3]) + "..."
+}
+
+// writeTable writes the rows as text table and returns the number of rows.
+func writeTable(w io.Writer, rows *sql.Rows, lobDir string) (int64, error) {
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	cols := make([]*column, len(cts))
+	widths := make([]int, len(cts))
+	for i, ct := range cts {
+		cols[i] = newColumn(ct)
+		widths[i] = utf8.RuneCountInString(cols[i].name)
+	}
+
+	cells := make([]cell, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range cells {
+		dest[i] = &cells[i]
+	}
+
+	var records [][]string
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return 0, err
+		}
+		record := make([]string, len(cols))
+		for i, c := range cols {
+			s, err := c.text(cells[i].v, lobDir, len(records)+1)
+			if err != nil {
+				return 0, err
+			}
+			record[i] = truncate(s)
+			if n := utf8.RuneCountInString(record[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	wr := bufio.NewWriter(w)
+	writeRecord := func(record []string) {
+		for i, s := range record {
+			if i > 0 {
+				wr.WriteString(" | ")
+			}
+			wr.WriteString(s)
+			wr.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(s)))
+		}
+		wr.WriteByte('\n')
+	}
+
+	header := make([]string, len(cols))
+	separator := make([]string, len(cols))
+	for i, c := range cols {
+		header[i] = c.name
+		separator[i] = strings.Repeat("-", widths[i])
+	}
+	writeRecord(header)
+	writeRecord(separator)
+	for _, record := range records {
+		writeRecord(record)
+	}
+	return int64(len(records)), wr.Flush()
+}
+
+// writeRows writes the rows in the output format and returns the number of rows.
+func writeRows(w io.Writer, rows *sql.Rows, format, lobDir string) (int64, error) {
+	switch format {
+	case fmtCSV:
+		return export.Rows(w, rows, &export.Options{Format: export.FormatCSV, Header: true})
+	case fmtJSON:
+		return export.Rows(w, rows, &export.Options{Format: export.FormatJSONLines})
+	default:
+		return writeTable(w, rows, lobDir)
+	}
+}
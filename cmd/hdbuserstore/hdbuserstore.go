@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/SAP/go-hdb/driver"
+	"golang.org/x/term"
+)
+
+const (
+	cmdSet    = "set"
+	cmdList   = "list"
+	cmdDelete = "delete"
+)
+
+type cliArgs struct {
+	dir        string
+	command    string
+	key        string
+	entry      *driver.UserStoreEntry
+	passphrase bool
+}
+
+func cli() *cliArgs {
+	const usageText = `
+%[1]s maintains the encrypted user store holding connection data and credentials by key.
+Connectors are created from user store keys by driver.NewUserStoreConnector.
+
+Commands:
+  set <key>     add or replace key (the password is read from standard input unless
+                client certificate or token files are provided)
+  list          list keys with host and username
+  delete <key>  delete key
+
+Example:
+  %[1]s -host host:port -user myuser set prod
+
+Usage of %[1]s:
+`
+	a := &cliArgs{entry: &driver.UserStoreEntry{}}
+
+	defaultDir, _ := driver.DefaultUserStoreDir() // ignore error - dir is checked below
+
+	args := flag.NewFlagSet("", flag.ExitOnError)
+	args.Usage = func() {
+		fmt.Fprintf(args.Output(), usageText, os.Args[0])
+		args.PrintDefaults()
+	}
+	args.StringVar(&a.dir, "dir", defaultDir, fmt.Sprintf("<directory>: User store directory (environment variable %s).", driver.EnvUserStore))
+	args.StringVar(&a.entry.Host, "host", "", "<host:port>: set only: Database host.")
+	args.StringVar(&a.entry.Username, "user", "", "<username>: set only: Database user.")
+	args.StringVar(&a.entry.DefaultSchema, "schema", "", "<schema>: set only: Default schema.")
+	args.StringVar(&a.entry.ClientCertFile, "certFile", "", "<file>: set only: X509 client certificate PEM file.")
+	args.StringVar(&a.entry.ClientKeyFile, "keyFile", "", "<file>: set only: X509 client key PEM file.")
+	args.StringVar(&a.entry.PKCS12File, "pkcs12File", "", "<file>: set only: X509 client certificate PKCS#12 file.")
+	args.StringVar(&a.entry.TokenFile, "tokenFile", "", "<file>: set only: JWT token file.")
+	args.BoolVar(&a.passphrase, "passphrase", false, "set only: Read the client key passphrase from standard input.")
+
+	args.Parse(os.Args[1:])
+
+	usageError := func(format string, v ...interface{}) {
+		fmt.Fprintf(args.Output(), format+"\n", v...)
+		args.Usage()
+		os.Exit(2)
+	}
+
+	if a.dir == "" {
+		usageError("missing user store directory")
+	}
+	if args.NArg() == 0 {
+		usageError("missing command")
+	}
+
+	a.command = args.Arg(0)
+	switch a.command {
+	case cmdSet, cmdDelete:
+		if args.NArg() != 2 {
+			usageError("command %s requires a key", a.command)
+		}
+		a.key = args.Arg(1)
+	case cmdList:
+		if args.NArg() != 1 {
+			usageError("too many arguments")
+		}
+	default:
+		usageError("invalid command %s", a.command)
+	}
+	return a
+}
+
+// readSecret prompts for a secret and reads it from the terminal without echo
+// or, if standard input is not a terminal (input redirection), from the first line of r.
+func readSecret(r *bufio.Reader, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) { // read without echo
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", strings.TrimSuffix(prompt, ": "), err)
+		}
+		return string(b), nil
+	}
+	s, err := r.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && s != "") {
+		return "", fmt.Errorf("read %s: %w", strings.TrimSuffix(prompt, ": "), err)
+	}
+	return strings.TrimRight(s, "\r\n"), nil
+}
+
+func set(s *driver.UserStore, a *cliArgs) error {
+	e := a.entry
+	r := bufio.NewReader(os.Stdin)
+	if e.ClientCertFile == "" && e.PKCS12File == "" && e.TokenFile == "" {
+		password, err := readSecret(r, "password: ")
+		if err != nil {
+			return err
+		}
+		e.Password = password
+	}
+	if a.passphrase {
+		passphrase, err := readSecret(r, "passphrase: ")
+		if err != nil {
+			return err
+		}
+		e.ClientKeyPassphrase = passphrase
+	}
+	return s.Set(a.key, e)
+}
+
+func list(s *driver.UserStore) error {
+	keys, err := s.Keys()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		e, err := s.Get(key)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%-20s %-40s %s\n", key, e.Host, e.Username)
+	}
+	return nil
+}
+
+func main() {
+	a := cli()
+
+	s, err := driver.OpenUserStore(a.dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch a.command {
+	case cmdSet:
+		err = set(s, a)
+	case cmdList:
+		err = list(s)
+	case cmdDelete:
+		err = s.Delete(a.key)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
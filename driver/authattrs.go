@@ -8,6 +8,7 @@ import (
 	"bytes"
 	"strings"
 	"sync"
+	"time"
 
 	p "github.com/SAP/go-hdb/driver/internal/protocol"
 )
@@ -20,9 +21,19 @@ type authAttrs struct {
 	_token                  string // JWT
 	_logonname              string // session cookie login does need logon name provided by JWT authentication.
 	_sessionCookie          []byte // authentication via session cookie (HDB currently does support only SAML and JWT - go-hdb JWT)
+	_cookieClientID         string // client id the session cookie was issued for
+	_cookieStore            CookieStore
+	_clientCertFile         string // client certificate file in case the client certificate is loaded from files
+	_clientKeyFile          string
+	_tokenFile              string // token file in case the token is loaded from a file
 	_refreshPassword        func() (password string, ok bool)
 	_refreshClientCert      func() (clientCert, clientKey []byte, ok bool)
 	_refreshToken           func() (token string, ok bool)
+	_tokenSource            *tokenCache
+	_authMethods            []func() AuthMethod // custom authentication methods
+	_allowedMethods         []string            // allowed authentication method types in priority order (nil: all)
+	_passwordChange         func(reason PasswordChangeReason, hdbErr Error) (newPassword string, ok bool)
+	_logonWarning           func(warning Error)
 }
 
 func (a *authAttrs) username() string { a.mu.RLock(); defer a.mu.RUnlock(); return a._username }
@@ -45,12 +56,23 @@ func (a *authAttrs) setClientCert(clientCert, clientKey []byte) {
 }
 func (a *authAttrs) token() string         { a.mu.RLock(); defer a.mu.RUnlock(); return a._token }
 func (a *authAttrs) setToken(token string) { a.mu.Lock(); defer a.mu.Unlock(); a._token = token }
-func (a *authAttrs) setSessionCookie(logonname string, cookie []byte) {
+func (a *authAttrs) setSessionCookie(logonname, clientID string, cookie []byte) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a._logonname = logonname
+	a._cookieClientID = clientID
 	a._sessionCookie = cookie
 }
+func (a *authAttrs) cookieStore() CookieStore {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a._cookieStore
+}
+func (a *authAttrs) setCookieStore(cookieStore CookieStore) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a._cookieStore = cookieStore
+}
 func (a *authAttrs) refreshPassword() func() (password string, ok bool) {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
@@ -82,9 +104,167 @@ func (a *authAttrs) setRefreshToken(refreshToken func() (token string, ok bool))
 	a._refreshToken = refreshToken
 }
 
+func (a *authAttrs) tokenSource() *tokenCache {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a._tokenSource
+}
+func (a *authAttrs) setTokenSource(tokenSource TokenSource) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if tokenSource == nil {
+		a._tokenSource = nil
+		return
+	}
+	a._tokenSource = newTokenCache(tokenSource)
+}
+
+func (a *authAttrs) addAuthMethod(newMethod func() AuthMethod) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a._authMethods = append(a._authMethods, newMethod)
+}
+
+func (a *authAttrs) allowedMethods() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a._allowedMethods
+}
+func (a *authAttrs) setAllowedMethods(mts []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a._allowedMethods = mts
+}
+
+// isAllowed returns true if the authentication method type is allowed.
+func (a *authAttrs) isAllowed(mt string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if a._allowedMethods == nil {
+		return true
+	}
+	for _, allowed := range a._allowedMethods {
+		if allowed == mt {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *authAttrs) passwordChange() func(reason PasswordChangeReason, hdbErr Error) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a._passwordChange
+}
+func (a *authAttrs) setPasswordChange(passwordChange func(reason PasswordChangeReason, hdbErr Error) (string, bool)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a._passwordChange = passwordChange
+}
+func (a *authAttrs) logonWarning() func(warning Error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a._logonWarning
+}
+func (a *authAttrs) setLogonWarning(logonWarning func(warning Error)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a._logonWarning = logonWarning
+}
+
+func (a *authAttrs) logonHooks() *logonHooks {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return &logonHooks{passwordChange: a._passwordChange, logonWarning: a._logonWarning, setPassword: a.setPassword}
+}
+
 func isJWTToken(token string) bool { return strings.HasPrefix(token, "ey") }
 
-func (a *authAttrs) cookieAuth() *p.Auth {
+// _tokenSubject returns the subject of the JWT token used for authentication.
+func (a *authAttrs) _tokenSubject() string {
+	token := a._token
+	if token == "" && a._username == "" && isJWTToken(a._password) { // password used as token
+		token = a._password
+	}
+	return jwtSubject(token)
+}
+
+// loadSessionCookie loads the session cookie for host from the cookie store.
+// If the username is set the cookie of the username is used, otherwise (JWT authentication)
+// the cookie issued for the subject of the JWT token. In case the logon identity is unknown
+// (no username and no token subject) no cookie is loaded.
+func (a *authAttrs) loadSessionCookie(host string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a._sessionCookie != nil || a._cookieStore == nil {
+		return
+	}
+	subject := ""
+	if a._username == "" {
+		if subject = a._tokenSubject(); subject == "" {
+			return
+		}
+	}
+	cookies, err := a._cookieStore.Load(host)
+	if err != nil {
+		dlog.Printf("load session cookie of host %s: %s", host, err)
+		return
+	}
+	var cookie *SessionCookie
+	for _, c := range cookies {
+		if a._username != "" && !strings.EqualFold(a._username, c.Logonname) {
+			continue
+		}
+		if subject != "" && subject != c.Subject {
+			continue
+		}
+		if cookie == nil || c.Expires.After(cookie.Expires) {
+			cookie = c
+		}
+	}
+	if cookie != nil {
+		a._logonname, a._cookieClientID, a._sessionCookie = cookie.Logonname, cookie.ClientID, cookie.Cookie
+	}
+}
+
+// saveSessionCookie sets the session cookie and saves it in the cookie store.
+func (a *authAttrs) saveSessionCookie(host, logonname string, cookie []byte) {
+	a.setSessionCookie(logonname, clientID, cookie)
+
+	a.mu.RLock()
+	store, subject := a._cookieStore, a._tokenSubject()
+	a.mu.RUnlock()
+
+	if store != nil {
+		if err := store.Save(&SessionCookie{Host: host, Logonname: logonname, Subject: subject, ClientID: clientID, Cookie: cookie}); err != nil {
+			dlog.Printf("save session cookie of host %s: %s", host, err)
+		}
+	}
+}
+
+// invalidateSessionCookie removes the session cookie after a failed session cookie logon.
+func (a *authAttrs) invalidateSessionCookie(host string) {
+	a.mu.Lock()
+	logonname := a._logonname
+	a._logonname, a._cookieClientID, a._sessionCookie = "", "", nil
+	store := a._cookieStore
+	a.mu.Unlock()
+
+	if store != nil {
+		if err := store.Delete(host, logonname); err != nil {
+			dlog.Printf("delete session cookie of host %s: %s", host, err)
+		}
+	}
+}
+
+func (a *authAttrs) cookieAuth(host string) *p.Auth {
+	if !a.isAllowed(AuthMethodSessionCookie) {
+		return nil
+	}
+
+	a.loadSessionCookie(host)
+
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
@@ -93,7 +273,7 @@ func (a *authAttrs) cookieAuth() *p.Auth {
 	}
 
 	auth := p.NewAuth(a._logonname) // important: for session cookie auth we do need the logonname from JWT auth.
-	auth.AddSessionCookie(a._sessionCookie, clientID)
+	auth.AddSessionCookie(a._sessionCookie, a._cookieClientID)
 	return auth
 }
 
@@ -109,16 +289,48 @@ func (a *authAttrs) auth() *p.Auth {
 		auth.AddJWT(a._token)
 	}
 	// mimic standard drivers and use password as token if user is empty
-	if a._token == "" && a._username == "" && isJWTToken(a._password) {
+	// (disabled in case the authentication methods are restricted)
+	if a._allowedMethods == nil && a._token == "" && a._username == "" && isJWTToken(a._password) {
 		auth.AddJWT(a._password)
 	}
 	if a._password != "" {
 		auth.AddBasic(a._username, a._password)
 	}
+	for _, newMethod := range a._authMethods {
+		auth.AddCustom(newMethod())
+	}
+	if a._allowedMethods != nil {
+		auth.Restrict(a._allowedMethods)
+	}
 	return auth
 }
 
-func (a *authAttrs) refresh(auth *p.Auth) bool {
+// refreshExpiringToken refreshes the JWT token before connecting
+// - by the token source (the token source cache refreshes tokens only shortly before they expire) or
+// - by the refresh token function if the token expires within the expiry margin.
+func (a *authAttrs) refreshExpiringToken(m *metrics) error {
+	if ts := a.tokenSource(); ts != nil {
+		token, err := ts.get(m)
+		if err != nil {
+			return err
+		}
+		if token != a.token() {
+			a.setToken(token)
+		}
+		return nil
+	}
+	if fn := a.refreshToken(); fn != nil && tokenExpires(a.token(), time.Now()) {
+		m.addCounterValue(counterTokenRefresh, 1)
+		if token, ok := fn(); ok && token != "" {
+			a.setToken(token)
+		} else {
+			m.addCounterValue(counterTokenRefreshError, 1)
+		}
+	}
+	return nil
+}
+
+func (a *authAttrs) refresh(auth *p.Auth, m *metrics) bool {
 	switch method := auth.Method().(type) {
 
 	case p.AuthPasswordSetter:
@@ -130,8 +342,22 @@ func (a *authAttrs) refresh(auth *p.Auth) bool {
 			}
 		}
 	case p.AuthTokenSetter:
+		if ts := a.tokenSource(); ts != nil {
+			ts.invalidate()
+			if token, err := ts.get(m); err == nil && a._token != token {
+				a.setToken(token)
+				method.SetToken(token)
+				return true
+			}
+			return false
+		}
 		if fn := a.refreshToken(); fn != nil {
-			if token, ok := fn(); ok && a._token != token {
+			m.addCounterValue(counterTokenRefresh, 1)
+			token, ok := fn()
+			if !ok {
+				m.addCounterValue(counterTokenRefreshError, 1)
+			}
+			if ok && a._token != token {
 				a.setToken(token)
 				method.SetToken(token)
 				return true
@@ -145,6 +371,8 @@ func (a *authAttrs) refresh(auth *p.Auth) bool {
 				return true
 			}
 		}
+	case p.AuthRefresher:
+		return method.Refresh()
 	}
 	return false
 }
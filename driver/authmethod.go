@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+
+	p "github.com/SAP/go-hdb/driver/internal/protocol"
+	"github.com/SAP/go-hdb/driver/internal/protocol/auth"
+)
+
+// Authentication method types of the built-in authentication methods (see Connector.SetAuthMethods).
+const (
+	AuthMethodSessionCookie     = auth.MtSessionCookie
+	AuthMethodX509              = auth.MtX509
+	AuthMethodJWT               = auth.MtJWT
+	AuthMethodSAML              = mtSAML
+	AuthMethodSCRAMPBKDF2SHA256 = auth.MtSCRAMPBKDF2SHA256
+	AuthMethodSCRAMSHA256       = auth.MtSCRAMSHA256
+)
+
+// ErrAuthMethodNotAllowed is returned by connect in case no allowed authentication method is available
+// or the database selected an authentication method which is not allowed (see Connector.SetAuthMethods).
+var ErrAuthMethodNotAllowed = p.ErrAuthMethodNotAllowed
+
+/*
+An AuthMethod is an authentication method taking part in the database logon.
+
+The logon consists of two round trips:
+  - initial request: for each authentication method of the connector the method type (Typ) and the
+    method specific data (InitRequest) is sent to the database ordered by Order.
+    The built-in methods are ordered as follows: session cookie (0), X509 (1), JWT (2), SAML (3),
+    SCRAMPBKDF2SHA256 (4) and SCRAMSHA256 (5). Methods with equal order are ordered by method type.
+  - initial reply: the database selects one method and replies the method specific data (InitReply).
+  - final request: the selected method provides the final request parameters (FinalRequest). Parameters need to be of
+    type string (sent CESU-8 encoded) or []byte.
+  - final reply: the database replies the method specific data (FinalReply) like a session cookie.
+
+An authentication method might implement the AuthRefresher interface to refresh its credentials in case of an
+authentication failure and the AuthCookieGetter interface to enable reconnects via session cookie.
+*/
+type AuthMethod interface {
+	Typ() string
+	Order() byte
+	InitRequest() ([]byte, error)
+	InitReply(data []byte) error
+	FinalRequest() ([]interface{}, error)
+	FinalReply(data []byte) error
+}
+
+// AuthRefresher is implemented by authentication methods supporting the refresh of credentials.
+// Refresh is called in case of an authentication failure and returns true if the credentials were
+// refreshed so that the logon is retried.
+type AuthRefresher interface {
+	Refresh() bool
+}
+
+// AuthCookieGetter is implemented by authentication methods receiving a session cookie in the final reply.
+// The logon name and the cookie are used by the connector to reconnect via session cookie authentication.
+type AuthCookieGetter interface {
+	Cookie() (logonname string, cookie []byte)
+}
+
+// check if the public interface is compatible with the custom protocol method.
+var _ auth.CustomMethod = (AuthMethod)(nil)
+
+// SAML authentication method type.
+const mtSAML = "SAML"
+
+// SAMLAuth implements SAML (assertion) authentication.
+type SAMLAuth struct {
+	assertionFn func() (string, error)
+	assertion   string
+	logonname   string
+	cookie      []byte
+}
+
+var (
+	_ AuthMethod       = (*SAMLAuth)(nil)
+	_ AuthRefresher    = (*SAMLAuth)(nil)
+	_ AuthCookieGetter = (*SAMLAuth)(nil)
+)
+
+// NewSAMLAuth returns a new SAML authentication method instance.
+// The assertion function is called to get the (base64 encoded) SAML assertion before the logon
+// and to refresh the assertion in case of an authentication failure.
+func NewSAMLAuth(assertion func() (string, error)) *SAMLAuth {
+	return &SAMLAuth{assertionFn: assertion}
+}
+
+// Typ implements the AuthMethod interface.
+func (a *SAMLAuth) Typ() string { return mtSAML }
+
+// Order implements the AuthMethod interface.
+func (a *SAMLAuth) Order() byte { return auth.MoSAML }
+
+// InitRequest implements the AuthMethod interface.
+func (a *SAMLAuth) InitRequest() ([]byte, error) {
+	if a.assertion == "" {
+		assertion, err := a.assertionFn()
+		if err != nil {
+			return nil, fmt.Errorf("saml assertion: %w", err)
+		}
+		a.assertion = assertion
+	}
+	if a.assertion == "" {
+		return nil, errors.New("saml assertion: empty assertion")
+	}
+	return []byte(a.assertion), nil
+}
+
+// InitReply implements the AuthMethod interface.
+func (a *SAMLAuth) InitReply(data []byte) error {
+	a.logonname = string(data)
+	return nil
+}
+
+// FinalRequest implements the AuthMethod interface.
+func (a *SAMLAuth) FinalRequest() ([]interface{}, error) {
+	return []interface{}{a.logonname, []byte(a.Typ()), []byte{}}, nil
+}
+
+// FinalReply implements the AuthMethod interface.
+func (a *SAMLAuth) FinalReply(data []byte) error {
+	a.cookie = data
+	return nil
+}
+
+// Refresh implements the AuthRefresher interface.
+func (a *SAMLAuth) Refresh() bool {
+	assertion, err := a.assertionFn()
+	if err != nil || assertion == "" || assertion == a.assertion {
+		return false
+	}
+	a.assertion = assertion
+	return true
+}
+
+// Cookie implements the AuthCookieGetter interface.
+func (a *SAMLAuth) Cookie() (string, []byte) { return a.logonname, a.cookie }
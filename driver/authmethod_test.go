@@ -0,0 +1,99 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSAMLAuth(t *testing.T) {
+	assertions := []string{"assertion1", "assertion1", "assertion2"}
+	i := 0
+	a := NewSAMLAuth(func() (string, error) { assertion := assertions[i]; i++; return assertion, nil })
+
+	data, err := a.InitRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "assertion1" {
+		t.Fatalf("init request %s - expected %s", data, "assertion1")
+	}
+
+	if err := a.InitReply([]byte("USER123")); err != nil {
+		t.Fatal(err)
+	}
+	prms, err := a.FinalRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []interface{}{"USER123", []byte("SAML"), []byte{}}; !reflect.DeepEqual(prms, expected) {
+		t.Fatalf("final request %v - expected %v", prms, expected)
+	}
+
+	if err := a.FinalReply([]byte("cookie")); err != nil {
+		t.Fatal(err)
+	}
+	if logonname, cookie := a.Cookie(); logonname != "USER123" || string(cookie) != "cookie" {
+		t.Fatalf("cookie %s %s - expected USER123 cookie", logonname, cookie)
+	}
+
+	if a.Refresh() { // same assertion
+		t.Fatal("unexpected refresh")
+	}
+	if !a.Refresh() {
+		t.Fatal("missing refresh")
+	}
+	if data, _ := a.InitRequest(); string(data) != "assertion2" {
+		t.Fatalf("init request %s - expected %s", data, "assertion2")
+	}
+}
+
+func TestAuthMethods(t *testing.T) {
+	c := NewBasicAuthConnector("localhost:39013", "", "eyJhbGciOiJub25lIn0.e30.c2ln")
+
+	if err := c.SetAuthMethods(AuthMethodX509, ""); err == nil {
+		t.Fatal("expected error for empty method type")
+	}
+	if err := c.SetAuthMethods(AuthMethodJWT, AuthMethodJWT); err == nil {
+		t.Fatal("expected error for duplicate method type")
+	}
+	if c.AuthMethods() != nil {
+		t.Fatalf("auth methods %v - expected nil", c.AuthMethods())
+	}
+
+	// password as token heuristic
+	if _, err := c.authAttrs.auth().InitRequest(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.SetAuthMethods(AuthMethodSCRAMPBKDF2SHA256); err != nil {
+		t.Fatal(err)
+	}
+	if mts := c.AuthMethods(); !reflect.DeepEqual(mts, []string{AuthMethodSCRAMPBKDF2SHA256}) {
+		t.Fatalf("auth methods %v - expected %v", mts, []string{AuthMethodSCRAMPBKDF2SHA256})
+	}
+
+	c.authAttrs.setSessionCookie("USER123", clientID, []byte("cookie"))
+	if c.authAttrs.cookieAuth("localhost:39013") != nil {
+		t.Fatal("session cookie authentication should not be allowed")
+	}
+
+	if err := c.SetAuthMethods(AuthMethodJWT); err != nil {
+		t.Fatal(err)
+	}
+	// password is not used as token
+	if _, err := c.authAttrs.auth().InitRequest(); !errors.Is(err, ErrAuthMethodNotAllowed) {
+		t.Fatalf("expected error %v - got %v", ErrAuthMethodNotAllowed, err)
+	}
+
+	if err := c.SetAuthMethods(); err != nil {
+		t.Fatal(err)
+	}
+	if c.authAttrs.cookieAuth("localhost:39013") == nil {
+		t.Fatal("session cookie authentication should be allowed")
+	}
+}
@@ -9,6 +9,7 @@ package driver_test
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -367,6 +368,169 @@ end
 	}
 }
 
+func testCallHelper(db *sql.DB, t *testing.T) {
+	const procHelper = `create procedure %[1]s (in a integer, in b nvarchar(25), out c integer, out d nvarchar(25), out t %[2]s)
+language SQLSCRIPT as
+begin
+  c := a * 2;
+  d := b;
+  create local temporary table #test like %[2]s;
+  insert into #test values(:a, :b);
+  insert into #test values(:c, :d);
+  t = select * from #test;
+  drop table #test;
+end
+`
+	tableType := driver.RandomIdentifier("tt3_")
+	proc := driver.RandomIdentifier("procHelper_")
+
+	if _, err := db.Exec(fmt.Sprintf("create type %s as table (i integer, x nvarchar(25))", tableType)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(fmt.Sprintf(procHelper, proc, tableType)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r, err := driver.Call(ctx, conn, proc.String(), sql.Named("B", "Hello"), sql.Named("A", 21))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var out struct {
+		C int
+		D string
+	}
+	if err := r.ScanOut(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.C != 42 || out.D != "Hello" {
+		t.Fatalf("output parameters %v - expected {42 Hello}", out)
+	}
+
+	table, err := r.Table("T")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table == nil {
+		t.Fatal("missing table output parameter T")
+	}
+	numRow := 0
+	for table.Next() {
+		var i int
+		var x string
+		if err := table.Scan(&i, &x); err != nil {
+			t.Fatal(err)
+		}
+		numRow++
+	}
+	if err := table.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if numRow != 2 {
+		t.Fatalf("number of rows %d - expected %d", numRow, 2)
+	}
+}
+
+func testCallHelperOrder(db *sql.DB, t *testing.T) {
+	// table output parameter declared before and in between scalar output parameters
+	const procHelperOrder = `create procedure %[1]s (out t1 %[2]s, in a integer, out c integer, out t2 %[2]s, in b nvarchar(25), out d nvarchar(25))
+language SQLSCRIPT as
+begin
+  c := a * 2;
+  d := b;
+  t1 = select :a as i, :b as x from dummy;
+  t2 = select :c as i, :d as x from dummy union all select :c as i, :d as x from dummy;
+end
+`
+	tableType := driver.RandomIdentifier("tt4_")
+	proc := driver.RandomIdentifier("procHelperOrder_")
+
+	if _, err := db.Exec(fmt.Sprintf("create type %s as table (i integer, x nvarchar(25))", tableType)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(fmt.Sprintf(procHelperOrder, proc, tableType)); err != nil {
+		t.Fatal(err)
+	}
+
+	checkTable := func(r *driver.CallResult, name string, numRow int, i int, x string, t *testing.T) {
+		table, err := r.Table(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if table == nil {
+			t.Fatalf("missing table output parameter %s", name)
+		}
+		n := 0
+		for table.Next() {
+			var ri int
+			var rx string
+			if err := table.Scan(&ri, &rx); err != nil {
+				t.Fatal(err)
+			}
+			if ri != i || rx != x {
+				t.Fatalf("table %s row %d - got %d %s - expected %d %s", name, n, ri, rx, i, x)
+			}
+			n++
+		}
+		if err := table.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if n != numRow {
+			t.Fatalf("table %s number of rows %d - expected %d", name, n, numRow)
+		}
+	}
+
+	for _, legacy := range []bool{false, true} {
+		t.Run(fmt.Sprintf("legacy %t", legacy), func(t *testing.T) {
+			connector := driver.NewTestConnector()
+			connector.SetLegacy(legacy)
+			db := sql.OpenDB(connector)
+			defer db.Close()
+
+			ctx := context.Background()
+			conn, err := db.Conn(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			r, err := driver.Call(ctx, conn, proc.String(), 21, "Hello")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+
+			var out struct {
+				C int
+				D string
+			}
+			if err := r.ScanOut(&out); err != nil {
+				t.Fatal(err)
+			}
+			if out.C != 42 || out.D != "Hello" {
+				t.Fatalf("output parameters %v - expected {42 Hello}", out)
+			}
+			if len(r.Tables) != 2 || r.Tables[0].Name != "T1" || r.Tables[1].Name != "T2" {
+				t.Fatalf("unexpected table output parameters %v", r.Tables)
+			}
+			// tables are read one after the other (legacy mode: opening a table closes the previous one)
+			checkTable(r, "T1", 1, 21, "Hello", t)
+			checkTable(r, "T2", 2, 42, "Hello", t)
+			if legacy { // reopen
+				checkTable(r, "T1", 1, 21, "Hello", t)
+			}
+		})
+	}
+}
+
 func TestCall(t *testing.T) {
 	tests := []struct {
 		name string
@@ -377,6 +541,8 @@ func TestCall(t *testing.T) {
 		{"tableOut", testCallTableOut},
 		{"noPrm", testCallNoPrm},
 		{"noOut", testCallNoOut},
+		{"helper", testCallHelper},
+		{"helperOrder", testCallHelperOrder},
 	}
 
 	db := sql.OpenDB(driver.NewTestConnector())
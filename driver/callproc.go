@@ -0,0 +1,528 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	queryProcParams = `select parameter_name, parameter_type, data_type_name, table_type_name
+from sys.procedure_parameters where schema_name = %s and procedure_name = ? order by position`
+	queryProcExists = `select count(*) from sys.procedures where schema_name = %s and procedure_name = ?`
+)
+
+// procParam is a stored procedure parameter.
+type procParam struct {
+	name    string
+	in, out bool
+	table   bool
+}
+
+// splitProcName splits a (schema qualified) procedure name into schema and procedure name.
+// Unquoted names are converted to upper case, quoted names are used as is.
+func splitProcName(s string) (string, string, error) {
+	invalid := fmt.Errorf("invalid procedure name %q", s)
+
+	var parts []string
+	for s != "" {
+		var part string
+		if s[0] == '"' {
+			var b strings.Builder
+			i := 1
+			for ; i < len(s); i++ {
+				if s[i] != '"' {
+					b.WriteByte(s[i])
+					continue
+				}
+				if i+1 < len(s) && s[i+1] == '"' { // escaped quote
+					b.WriteByte('"')
+					i++
+					continue
+				}
+				break
+			}
+			if i == len(s) { // missing closing quote
+				return "", "", invalid
+			}
+			part, s = b.String(), s[i+1:]
+		} else {
+			i := strings.IndexByte(s, '.')
+			if i == -1 {
+				i = len(s)
+			}
+			part, s = strings.ToUpper(s[:i]), s[i:]
+			if strings.ContainsRune(part, '"') {
+				return "", "", invalid
+			}
+		}
+		if part == "" {
+			return "", "", invalid
+		}
+		parts = append(parts, part)
+
+		if s != "" {
+			if s[0] != '.' || len(s) == 1 {
+				return "", "", invalid
+			}
+			s = s[1:]
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return "", parts[0], nil
+	case 2:
+		return parts[0], parts[1], nil
+	default:
+		return "", "", invalid
+	}
+}
+
+// procParams reads the procedure parameters from the database catalog.
+func procParams(ctx context.Context, conn *sql.Conn, schema, name string) ([]*procParam, error) {
+	schemaExpr, args := "current_schema", []interface{}{name}
+	if schema != "" {
+		schemaExpr, args = "?", []interface{}{schema, name}
+	}
+
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(queryProcParams, schemaExpr), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prms []*procParam
+	for rows.Next() {
+		var prmName, prmType, typeName string
+		var tableTypeName sql.NullString
+		if err := rows.Scan(&prmName, &prmType, &typeName, &tableTypeName); err != nil {
+			return nil, err
+		}
+		prms = append(prms, &procParam{
+			name:  prmName,
+			in:    prmType == "IN" || prmType == "INOUT",
+			out:   prmType == "OUT" || prmType == "INOUT",
+			table: typeName == "TABLE_TYPE" || tableTypeName.String != "",
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(prms) != 0 {
+		return prms, nil
+	}
+
+	// procedure without parameters or unknown procedure?
+	var cnt int
+	if err := conn.QueryRowContext(ctx, fmt.Sprintf(queryProcExists, schemaExpr), args...).Scan(&cnt); err != nil {
+		return nil, err
+	}
+	if cnt == 0 {
+		return nil, fmt.Errorf("procedure %s not found", Identifier(name))
+	}
+	return nil, nil
+}
+
+// bindProcArgs assigns the arguments to the procedure input parameters.
+// Arguments are either all sql.NamedArg (bound by parameter name) or all positional.
+func bindProcArgs(prms []*procParam, args []interface{}) ([]interface{}, error) {
+	var inPrms []*procParam
+	for _, prm := range prms {
+		if prm.in {
+			inPrms = append(inPrms, prm)
+		}
+	}
+
+	numNamed := 0
+	for _, arg := range args {
+		if _, ok := arg.(sql.NamedArg); ok {
+			numNamed++
+		}
+	}
+
+	switch {
+	case numNamed == 0:
+		if len(args) != len(inPrms) {
+			return nil, fmt.Errorf("invalid number of arguments %d - expected %d", len(args), len(inPrms))
+		}
+		return args, nil
+	case numNamed != len(args):
+		return nil, errors.New("named and positional arguments cannot be mixed")
+	}
+
+	inArgs := make([]interface{}, len(inPrms))
+	bound := make([]bool, len(inPrms))
+	for _, arg := range args {
+		namedArg := arg.(sql.NamedArg)
+		found := false
+		for i, prm := range inPrms {
+			if strings.EqualFold(prm.name, namedArg.Name) {
+				if bound[i] {
+					return nil, fmt.Errorf("duplicate argument %s", namedArg.Name)
+				}
+				inArgs[i], bound[i], found = namedArg.Value, true, true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown input parameter %s", namedArg.Name)
+		}
+	}
+	for i, prm := range inPrms {
+		if !bound[i] {
+			return nil, fmt.Errorf("missing argument for input parameter %s", prm.name)
+		}
+	}
+	return inArgs, nil
+}
+
+// callQuery returns the call statement and the arguments to be bound.
+// Table input parameters cannot be bound and are inserted into the statement as table names.
+func callQuery(schema, name string, prms []*procParam, inArgs []interface{}) (string, []interface{}, error) {
+	var b strings.Builder
+	if schema != "" {
+		b.WriteString(Identifier(schema).String())
+		b.WriteByte('.')
+	}
+	b.WriteString(Identifier(name).String())
+	b.WriteByte('(')
+
+	var args []interface{}
+	j := 0
+	for i, prm := range prms {
+		if i != 0 {
+			b.WriteString(", ")
+		}
+		if !(prm.in && prm.table) {
+			b.WriteByte('?')
+			if prm.in {
+				args = append(args, inArgs[j])
+				j++
+			}
+			continue
+		}
+		switch tableName := inArgs[j].(type) {
+		case string:
+			b.WriteString(Identifier(tableName).String())
+		case Identifier:
+			b.WriteString(tableName.String())
+		default:
+			return "", nil, fmt.Errorf("invalid table argument type %T for parameter %s - expected table name", inArgs[j], prm.name)
+		}
+		j++
+	}
+	b.WriteByte(')')
+	return "call " + b.String(), args, nil
+}
+
+// CallTable is a table output parameter of a stored procedure call.
+type CallTable struct {
+	Name string // Parameter name.
+	// Rows is the row set of the table. In legacy mode Rows is nil until the table is opened by CallResult.Table.
+	*sql.Rows
+	query string // legacy mode: result set query of the table
+}
+
+// CallResult is the result of a stored procedure call.
+type CallResult struct {
+	// Out contains the values of the scalar OUT and INOUT parameters by parameter name.
+	// Large object values can only be scanned (see ScanOut) until the result is closed.
+	Out map[string]interface{}
+	// Tables contains the table output parameters in parameter order.
+	Tables []*CallTable
+	rows   *sql.Rows
+
+	// legacy mode
+	ctx     context.Context
+	sqlConn *sql.Conn
+	open    *CallTable // table with open row set
+}
+
+// Table returns the table output parameter with name name (case insensitive) or nil if not available.
+//
+// In legacy mode the row set of the table is queried by Table. As only one query can be open on a
+// connection at a time the row set of the previously opened table is closed, so tables need to be
+// read one after the other.
+func (r *CallResult) Table(name string) (*CallTable, error) {
+	for _, t := range r.Tables {
+		if strings.EqualFold(t.Name, name) {
+			if t.query == "" || t == r.open { // non-legacy mode or already opened
+				return t, nil
+			}
+			if r.open != nil {
+				r.open.Rows.Close()
+				r.open.Rows, r.open = nil, nil
+			}
+			rows, err := r.sqlConn.QueryContext(r.ctx, t.query)
+			if err != nil {
+				return nil, err
+			}
+			t.Rows, r.open = rows, t
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// ScanOut assigns the scalar output parameter values to the fields of the struct dest is pointing to.
+// Fields are matched to parameters by the 'db' field tag or by the field name (case insensitive).
+// Fields implementing the sql.Scanner interface (like Decimal or Lob) are assigned via Scan,
+// all other fields need to be assignable from the parameter value. Numeric values are converted
+// to numeric fields of different type if the value can be represented without loss.
+func (r *CallResult) ScanOut(dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("invalid scan destination %T - expected struct pointer", dest)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		name := sf.Name
+		if tag, ok := sf.Tag.Lookup("db"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		v, ok := r.outValue(name)
+		if !ok {
+			continue
+		}
+		if err := assignOut(rv.Field(i), v); err != nil {
+			return fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *CallResult) outValue(name string) (interface{}, bool) {
+	if v, ok := r.Out[name]; ok {
+		return v, true
+	}
+	for k, v := range r.Out {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func assignOut(fv reflect.Value, v interface{}) error {
+	if scanner, ok := fv.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(v)
+	}
+	if v == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+	vv := reflect.ValueOf(v)
+	switch {
+	case vv.Type().AssignableTo(fv.Type()):
+		fv.Set(vv)
+	case isNumberKind(vv.Kind()) && isNumberKind(fv.Kind()):
+		return assignNumber(fv, vv)
+	case vv.Type().ConvertibleTo(fv.Type()) && vv.Kind() != reflect.String && fv.Kind() != reflect.String:
+		fv.Set(vv.Convert(fv.Type()))
+	case vv.Kind() == reflect.Slice && fv.Kind() == reflect.String: // []byte
+		fv.SetString(string(vv.Bytes()))
+	case vv.Kind() == reflect.String && fv.Kind() == reflect.String:
+		fv.SetString(vv.String())
+	default:
+		return fmt.Errorf("cannot assign value of type %T to %s", v, fv.Type())
+	}
+	return nil
+}
+
+func isNumberKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// assignNumber assigns a number to a numeric field like database/sql does for scans (convertAssign):
+// the value is converted via its string representation, so that values out of the field range and
+// fractional values assigned to integer fields are rejected instead of being truncated.
+func assignNumber(fv, vv reflect.Value) error {
+	var s string
+	switch vv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		s = strconv.FormatFloat(vv.Float(), 'f', -1, vv.Type().Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(vv.Uint(), 10)
+	default:
+		s = strconv.FormatInt(vv.Int(), 10)
+	}
+
+	var err error
+	switch fv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, fv.Type().Bits()); err == nil {
+			fv.SetFloat(f)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, fv.Type().Bits()); err == nil {
+			fv.SetUint(u)
+		}
+	default:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, fv.Type().Bits()); err == nil {
+			fv.SetInt(i)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("cannot assign value %s to %s: %w", s, fv.Type(), err)
+	}
+	return nil
+}
+
+// Close closes the table output parameters and releases the call result.
+func (r *CallResult) Close() error {
+	for _, t := range r.Tables {
+		if t.Rows != nil {
+			t.Close()
+		}
+	}
+	if r.rows == nil {
+		return nil
+	}
+	return r.rows.Close()
+}
+
+// Call calls the stored procedure procedure on connection sqlConn.
+//
+// The procedure name might be schema qualified (default: current schema). Unquoted names are
+// converted to upper case, quoted names (e.g. "MySchema"."MyProc") are used as is.
+// The procedure signature is read from the database catalog. Input parameters are bound
+// either by position (in parameter order) or by name passing sql.NamedArg arguments (see sql.Named).
+// Table input parameters are passed as table names (string or Identifier).
+//
+// The scalar output parameters are returned in CallResult.Out, the table output parameters
+// as row sets in CallResult.Tables. Call supports both, the legacy and the non-legacy table
+// output parameter mode (see Connector.SetLegacy). In legacy mode the table row sets are opened
+// one at a time by CallResult.Table. The call result needs to be closed after usage.
+func Call(ctx context.Context, sqlConn *sql.Conn, procedure string, args ...interface{}) (*CallResult, error) {
+	schema, name, err := splitProcName(procedure)
+	if err != nil {
+		return nil, err
+	}
+
+	prms, err := procParams(ctx, sqlConn, schema, name)
+	if err != nil {
+		return nil, err
+	}
+
+	inArgs, err := bindProcArgs(prms, args)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := callQuery(schema, name, prms, inArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var outPrms []*procParam // scalar and table output parameters in declaration order
+	for _, prm := range prms {
+		if prm.out {
+			outPrms = append(outPrms, prm)
+		}
+	}
+
+	result := &CallResult{Out: map[string]interface{}{}}
+
+	if len(outPrms) == 0 {
+		if _, err := sqlConn.ExecContext(ctx, query, args...); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	var legacy bool
+	if err := sqlConn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*conn)
+		if !ok {
+			return fmt.Errorf("invalid driver connection type %T", driverConn)
+		}
+		legacy = c.legacy
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	rows, err := sqlConn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	result.rows = rows
+
+	if !rows.Next() {
+		err := rows.Err()
+		if err == nil {
+			err = sql.ErrNoRows
+		}
+		rows.Close()
+		return nil, err
+	}
+
+	values := make([]interface{}, len(outPrms))
+	tableQueries := make([]string, len(outPrms))
+	dest := make([]interface{}, len(outPrms))
+	for i, prm := range outPrms {
+		switch {
+		case !prm.table:
+			dest[i] = &values[i]
+		case legacy:
+			dest[i] = &tableQueries[i]
+		default:
+			t := &CallTable{Name: prm.name, Rows: new(sql.Rows)}
+			result.Tables = append(result.Tables, t)
+			dest[i] = t.Rows
+		}
+	}
+
+	if err := rows.Scan(dest...); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	for i, prm := range outPrms {
+		if !prm.table {
+			result.Out[prm.name] = values[i]
+		}
+	}
+
+	if legacy {
+		// table output parameters are queried by separate queries (see CallResult.Table)
+		// after closing the call result (nested queries are not supported)
+		result.rows = nil
+		if err := rows.Close(); err != nil {
+			return nil, err
+		}
+		result.ctx, result.sqlConn = ctx, sqlConn
+		for i, prm := range outPrms {
+			if prm.table {
+				result.Tables = append(result.Tables, &CallTable{Name: prm.name, query: tableQueries[i]})
+			}
+		}
+	}
+	return result, nil
+}
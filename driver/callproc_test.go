@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestSplitProcName(t *testing.T) {
+	testData := []struct {
+		s      string
+		schema string
+		name   string
+	}{
+		{"proc", "", "PROC"},
+		{"s.proc", "S", "PROC"},
+		{`"MySchema"."MyProc"`, "MySchema", "MyProc"},
+		{`s."My.Proc"`, "S", "My.Proc"},
+		{`"a""b"`, "", `a"b`},
+	}
+	for _, d := range testData {
+		schema, name, err := splitProcName(d.s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if schema != d.schema || name != d.name {
+			t.Fatalf("%s: schema %s name %s - expected schema %s name %s", d.s, schema, name, d.schema, d.name)
+		}
+	}
+
+	for _, s := range []string{"", ".", "s.", ".p", "a.b.c", `"p`, `"p"x`, `p"x"`} {
+		if _, _, err := splitProcName(s); err == nil {
+			t.Fatalf("%q: expected error", s)
+		}
+	}
+}
+
+func TestCallQuery(t *testing.T) {
+	prms := []*procParam{
+		{name: "IT", in: true, table: true},
+		{name: "A", in: true},
+		{name: "B", in: true, out: true},
+		{name: "C", out: true},
+		{name: "OT", out: true, table: true},
+	}
+
+	for _, args := range [][]interface{}{
+		{"t", 1, 2},
+		{sql.Named("b", 2), sql.Named("A", 1), sql.Named("it", "t")},
+	} {
+		inArgs, err := bindProcArgs(prms, args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query, args, err := callQuery("S", "P", prms, inArgs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != `call S.P("t", ?, ?, ?, ?)` {
+			t.Fatalf("query %s", query)
+		}
+		if !reflect.DeepEqual(args, []interface{}{1, 2}) {
+			t.Fatalf("args %v - expected %v", args, []interface{}{1, 2})
+		}
+	}
+
+	for _, args := range [][]interface{}{
+		{"t", 1},
+		{"t", sql.Named("A", 1), 2},
+		{sql.Named("A", 1), sql.Named("B", 2)},
+		{sql.Named("A", 1), sql.Named("B", 2), sql.Named("X", "t")},
+	} {
+		if _, err := bindProcArgs(prms, args); err == nil {
+			t.Fatalf("%v: expected error", args)
+		}
+	}
+}
+
+func TestCallResultScanOut(t *testing.T) {
+	r := &CallResult{Out: map[string]interface{}{"COUNT": int64(42), "NAME": []byte("go-hdb"), "RATE": nil}}
+
+	var dest struct {
+		Count int
+		Label string `db:"NAME"`
+		Rate  sql.NullFloat64
+	}
+	if err := r.ScanOut(&dest); err != nil {
+		t.Fatal(err)
+	}
+	if dest.Count != 42 || dest.Label != "go-hdb" || dest.Rate.Valid {
+		t.Fatalf("invalid result %v", dest)
+	}
+}
+
+func TestCallResultScanOutConversion(t *testing.T) {
+	r := &CallResult{Out: map[string]interface{}{"I": int64(300), "F": 1.5, "G": float64(1e8), "N": int64(-1)}}
+
+	var dest struct {
+		I int16
+		F float32
+		G int64
+		N int64
+	}
+	if err := r.ScanOut(&dest); err != nil {
+		t.Fatal(err)
+	}
+	if dest.I != 300 || dest.F != 1.5 || dest.G != 1e8 || dest.N != -1 {
+		t.Fatalf("invalid result %v", dest)
+	}
+
+	// lossy conversions are rejected
+	testData := []interface{}{
+		&struct{ I int8 }{}, // out of range
+		&struct{ F int }{},  // fractional value
+		&struct{ N uint }{}, // negative value
+	}
+	for i, dest := range testData {
+		if err := r.ScanOut(dest); err == nil {
+			t.Fatalf("%d: expected conversion error for %T", i, dest)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package catalog provides typed descriptions of database objects read from the SAP HANA system views
+(SYS.SCHEMAS, SYS.TABLES, SYS.VIEWS, SYS.TABLE_COLUMNS, SYS.VIEW_COLUMNS, SYS.CONSTRAINTS,
+SYS.REFERENTIAL_CONSTRAINTS, SYS.INDEXES, SYS.INDEX_COLUMNS, SYS.SEQUENCES, SYS.PROCEDURES and
+SYS.PROCEDURE_PARAMETERS).
+
+The catalog only returns objects the database user has privileges for. Database object names are case sensitive
+and need to be provided as stored in the catalog (unquoted identifiers are stored in upper case).
+*/
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// A Queryer is implemented by sql.DB, sql.Conn and sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Catalog provides access to the database catalog.
+type Catalog struct {
+	q Queryer
+}
+
+// New returns a new Catalog instance reading the catalog via q.
+func New(q Queryer) *Catalog { return &Catalog{q: q} }
+
+// query executes the query and calls fn for each row.
+func (c *Catalog) query(ctx context.Context, fn func(rows *sql.Rows) error, query string, args ...interface{}) error {
+	rows, err := c.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// isTrue returns true for the catalog boolean value 'TRUE'.
+func isTrue(s string) bool { return strings.EqualFold(s, "TRUE") }
+
+// lobString is a scan destination for character based lobs.
+type lobString struct {
+	b strings.Builder
+}
+
+func (s *lobString) Scan(src interface{}) error {
+	s.b.Reset()
+	if src == nil {
+		return nil
+	}
+	return driver.NewLob(nil, &s.b).Scan(src)
+}
+
+func (s *lobString) String() string { return s.b.String() }
+
+// Schema describes a database schema.
+type Schema struct {
+	Name  string
+	Owner string
+}
+
+const querySchemas = "select schema_name, schema_owner from sys.schemas order by schema_name"
+
+// Schemas returns the database schemas.
+func (c *Catalog) Schemas(ctx context.Context) ([]*Schema, error) {
+	var schemas []*Schema
+	err := c.query(ctx, func(rows *sql.Rows) error {
+		s := &Schema{}
+		var owner sql.NullString
+		if err := rows.Scan(&s.Name, &owner); err != nil {
+			return err
+		}
+		s.Owner = owner.String
+		schemas = append(schemas, s)
+		return nil
+	}, querySchemas)
+	return schemas, err
+}
+
+// Sequence describes a database sequence.
+type Sequence struct {
+	SchemaName  string
+	Name        string
+	StartValue  int64
+	MinValue    int64
+	MaxValue    int64
+	IncrementBy int64
+	Cycle       bool
+}
+
+const querySequences = `select schema_name, sequence_name, to_bigint(start_number), to_bigint(min_value), to_bigint(max_value), to_bigint(increment_by), is_cycled
+from sys.sequences where schema_name = ? order by sequence_name`
+
+// Sequences returns the sequences of a schema.
+func (c *Catalog) Sequences(ctx context.Context, schemaName string) ([]*Sequence, error) {
+	var sequences []*Sequence
+	err := c.query(ctx, func(rows *sql.Rows) error {
+		s := &Sequence{}
+		var cycle string
+		if err := rows.Scan(&s.SchemaName, &s.Name, &s.StartValue, &s.MinValue, &s.MaxValue, &s.IncrementBy, &cycle); err != nil {
+			return err
+		}
+		s.Cycle = isTrue(cycle)
+		sequences = append(sequences, s)
+		return nil
+	}, querySequences, schemaName)
+	return sequences, err
+}
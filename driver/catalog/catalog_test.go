@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"testing"
+)
+
+func TestIsTrue(t *testing.T) {
+	testData := []struct {
+		s string
+		b bool
+	}{
+		{"TRUE", true},
+		{"true", true},
+		{"FALSE", false},
+		{"", false},
+	}
+
+	for _, d := range testData {
+		if b := isTrue(d.s); b != d.b {
+			t.Fatalf("value %s - got %t - expected %t", d.s, b, d.b)
+		}
+	}
+}
+
+func TestIsIdentity(t *testing.T) {
+	testData := []struct {
+		generationType string
+		identity       bool
+	}{
+		{"ALWAYS AS IDENTITY", true},
+		{"BY DEFAULT AS IDENTITY", true},
+		{"ALWAYS AS", false},
+		{"", false},
+	}
+
+	for _, d := range testData {
+		if identity := isIdentity(d.generationType); identity != d.identity {
+			t.Fatalf("generation type %s - got %t - expected %t", d.generationType, identity, d.identity)
+		}
+	}
+}
+
+func TestParameterIsTable(t *testing.T) {
+	if !(&Parameter{TypeName: "TABLE_TYPE"}).IsTable() {
+		t.Fatal("expected table parameter")
+	}
+	if !(&Parameter{TableTypeSchema: "S", TableTypeName: "TT"}).IsTable() {
+		t.Fatal("expected table parameter")
+	}
+	if (&Parameter{TypeName: "NVARCHAR"}).IsTable() {
+		t.Fatal("unexpected table parameter")
+	}
+}
@@ -0,0 +1,224 @@
+//go:build !unit
+// +build !unit
+
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+const envDSN = "GOHDBDSN"
+
+// testDB creates a test schema with the catalog test objects and returns the database handle and the schema name.
+// The schema is dropped at the end of the test.
+func testDB(t *testing.T) (*sql.DB, string) {
+	dsn, ok := os.LookupEnv(envDSN)
+	if !ok {
+		t.Fatalf("environment variable %s not set", envDSN)
+	}
+	connector, err := driver.NewDSNConnector(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() { db.Close() })
+
+	schema := fmt.Sprintf("GOHDBTESTCATALOG_%d", time.Now().UnixNano())
+	stmts := []string{
+		fmt.Sprintf("create schema %s", schema),
+		fmt.Sprintf("create column table %s.parent (id integer not null, name nvarchar(40), primary key (id))", schema),
+		fmt.Sprintf(`create column table %s.child (
+  id bigint generated by default as identity,
+  parent_id integer not null,
+  amount decimal(10,2) default 0,
+  primary key (id),
+  constraint fk_parent foreign key (parent_id) references %[1]s.parent (id) on delete cascade)`, schema),
+		fmt.Sprintf("create index idx_child_amount on %s.child (amount desc)", schema),
+		fmt.Sprintf("create view %s.child_view as select id, amount from %[1]s.child", schema),
+		fmt.Sprintf("create sequence %s.seq start with 10 increment by 5 maxvalue 1000 cycle", schema),
+		fmt.Sprintf(`create procedure %s.proc (in a integer, out b nvarchar(10), out c %[1]s.parent) reads sql data as
+begin
+  b = 'x';
+  c = select * from %[1]s.parent;
+end`, schema),
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %s", stmt, err)
+		}
+	}
+	t.Cleanup(func() {
+		if _, err := db.Exec(fmt.Sprintf("drop schema %s cascade", schema)); err != nil {
+			t.Log(err)
+		}
+	})
+	return db, schema
+}
+
+func testCatalogTable(t *testing.T, c *Catalog, schema string) {
+	tbl, err := c.Table(context.Background(), schema, "CHILD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbl.SchemaName != schema || tbl.Name != "CHILD" || !tbl.ColumnTable || tbl.Temporary {
+		t.Fatalf("unexpected table %#v", tbl)
+	}
+
+	if len(tbl.Columns) != 3 {
+		t.Fatalf("number of columns %d - expected 3", len(tbl.Columns))
+	}
+	id, parentID, amount := tbl.Columns[0], tbl.Columns[1], tbl.Columns[2]
+	if id.Name != "ID" || id.Position != 1 || id.TypeName != "BIGINT" || id.DataType != driver.DtBigint || !id.Identity || id.Nullable {
+		t.Fatalf("unexpected column %#v", id)
+	}
+	if parentID.Name != "PARENT_ID" || parentID.DataType != driver.DtInteger || parentID.Nullable || parentID.Identity {
+		t.Fatalf("unexpected column %#v", parentID)
+	}
+	if amount.Name != "AMOUNT" || amount.TypeName != "DECIMAL" || amount.DataType != driver.DtDecimal || amount.Length != 10 || amount.Scale != 2 || !amount.Nullable || !amount.DefaultValue.Valid {
+		t.Fatalf("unexpected column %#v", amount)
+	}
+
+	if tbl.PrimaryKey == nil || !reflect.DeepEqual(tbl.PrimaryKey.ColumnNames, []string{"ID"}) {
+		t.Fatalf("unexpected primary key %#v", tbl.PrimaryKey)
+	}
+
+	if len(tbl.ForeignKeys) != 1 {
+		t.Fatalf("number of foreign keys %d - expected 1", len(tbl.ForeignKeys))
+	}
+	expectedFK := &ForeignKey{
+		Name:                  "FK_PARENT",
+		ColumnNames:           []string{"PARENT_ID"},
+		ReferencedSchemaName:  schema,
+		ReferencedTableName:   "PARENT",
+		ReferencedColumnNames: []string{"ID"},
+		UpdateRule:            "RESTRICT",
+		DeleteRule:            "CASCADE",
+	}
+	if fk := tbl.ForeignKeys[0]; !reflect.DeepEqual(fk, expectedFK) {
+		t.Fatalf("foreign key %#v - expected %#v", fk, expectedFK)
+	}
+
+	var index *Index
+	for _, idx := range tbl.Indexes {
+		if idx.Name == "IDX_CHILD_AMOUNT" {
+			index = idx
+		}
+	}
+	if index == nil {
+		t.Fatalf("index IDX_CHILD_AMOUNT not found in %v", tbl.Indexes)
+	}
+	if index.Constraint != "" || len(index.Columns) != 1 || index.Columns[0].Name != "AMOUNT" || index.Columns[0].Ascending {
+		t.Fatalf("unexpected index %#v", index)
+	}
+
+	tables, err := c.Tables(context.Background(), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 || tables[0].Name != "CHILD" || tables[1].Name != "PARENT" {
+		t.Fatalf("unexpected tables %v", tables)
+	}
+}
+
+func testCatalogColumns(t *testing.T, c *Catalog, schema string) {
+	// table columns
+	cols, err := c.Columns(context.Background(), schema, "PARENT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != 2 || cols[1].Name != "NAME" || cols[1].TypeName != "NVARCHAR" || cols[1].DataType != driver.DtString || cols[1].Length != 40 {
+		t.Fatalf("unexpected columns %v", cols)
+	}
+	// view columns
+	if cols, err = c.Columns(context.Background(), schema, "CHILD_VIEW"); err != nil {
+		t.Fatal(err)
+	}
+	if len(cols) != 2 || cols[0].Name != "ID" || cols[1].Name != "AMOUNT" || cols[1].DataType != driver.DtDecimal {
+		t.Fatalf("unexpected columns %v", cols)
+	}
+}
+
+func testCatalogViews(t *testing.T, c *Catalog, schema string) {
+	views, err := c.Views(context.Background(), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 1 || views[0].Name != "CHILD_VIEW" || views[0].SchemaName != schema || views[0].Definition == "" {
+		t.Fatalf("unexpected views %v", views)
+	}
+	v, err := c.View(context.Background(), schema, "CHILD_VIEW")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Columns) != 2 {
+		t.Fatalf("number of view columns %d - expected 2", len(v.Columns))
+	}
+}
+
+func testCatalogProcedures(t *testing.T, c *Catalog, schema string) {
+	procs, err := c.Procedures(context.Background(), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(procs) != 1 || procs[0].Name != "PROC" || !procs[0].ReadOnly {
+		t.Fatalf("unexpected procedures %v", procs)
+	}
+	prms := procs[0].Parameters
+	if len(prms) != 3 {
+		t.Fatalf("number of parameters %d - expected 3", len(prms))
+	}
+	if a := prms[0]; a.Name != "A" || a.Position != 1 || a.Mode != ParameterIn || a.DataType != driver.DtInteger || a.IsTable() {
+		t.Fatalf("unexpected parameter %#v", a)
+	}
+	if b := prms[1]; b.Name != "B" || b.Mode != ParameterOut || b.TypeName != "NVARCHAR" || b.Length != 10 || b.IsTable() {
+		t.Fatalf("unexpected parameter %#v", b)
+	}
+	if p := prms[2]; p.Name != "C" || p.Mode != ParameterOut || !p.IsTable() {
+		t.Fatalf("unexpected parameter %#v", p)
+	}
+}
+
+func testCatalogSequences(t *testing.T, c *Catalog, schema string) {
+	seqs, err := c.Sequences(context.Background(), schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*Sequence{{SchemaName: schema, Name: "SEQ", StartValue: 10, MinValue: 1, MaxValue: 1000, IncrementBy: 5, Cycle: true}}
+	if !reflect.DeepEqual(seqs, expected) {
+		t.Fatalf("sequences %v - expected %v", seqs, expected)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	db, schema := testDB(t)
+	c := New(db)
+
+	tests := []struct {
+		name string
+		fct  func(t *testing.T, c *Catalog, schema string)
+	}{
+		{"table", testCatalogTable},
+		{"columns", testCatalogColumns},
+		{"views", testCatalogViews},
+		{"procedures", testCatalogProcedures},
+		{"sequences", testCatalogSequences},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t, c, schema)
+		})
+	}
+}
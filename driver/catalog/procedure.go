@@ -0,0 +1,96 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// ParameterMode is the mode of a procedure parameter.
+type ParameterMode string
+
+// ParameterMode constants.
+const (
+	ParameterIn    ParameterMode = "IN"
+	ParameterInOut ParameterMode = "INOUT"
+	ParameterOut   ParameterMode = "OUT"
+)
+
+// Parameter describes a procedure parameter.
+type Parameter struct {
+	Name            string
+	Position        int
+	Mode            ParameterMode
+	TypeName        string          // Database type name (e.g. NVARCHAR, TABLE_TYPE).
+	DataType        driver.DataType // go-hdb data type (DtUnknown for table parameters).
+	Length          int64           // Length of character and binary types, precision of decimal types.
+	Scale           int64           // Scale of decimal types.
+	Nullable        bool
+	HasDefault      bool
+	TableTypeSchema string // Schema of the table type (table parameters only).
+	TableTypeName   string // Name of the table type (table parameters only).
+}
+
+// IsTable returns true for table parameters.
+func (p *Parameter) IsTable() bool { return p.TableTypeName != "" || p.TypeName == "TABLE_TYPE" }
+
+// Procedure describes a database procedure.
+type Procedure struct {
+	SchemaName string
+	Name       string
+	ReadOnly   bool
+	Parameters []*Parameter // in positional order
+}
+
+const (
+	queryProcedures = `select schema_name, procedure_name, read_only
+from sys.procedures where schema_name = ? order by procedure_name`
+	queryProcedureParameters = `select procedure_name, parameter_name, position, parameter_type, data_type_name, length, scale, is_nullable, has_default_value, table_type_schema, table_type_name
+from sys.procedure_parameters where schema_name = ? order by procedure_name, position`
+)
+
+// Procedures returns the procedures of a schema including their parameters.
+func (c *Catalog) Procedures(ctx context.Context, schemaName string) ([]*Procedure, error) {
+	var procs []*Procedure
+	procMap := map[string]*Procedure{}
+	if err := c.query(ctx, func(rows *sql.Rows) error {
+		proc := &Procedure{}
+		var readOnly string
+		if err := rows.Scan(&proc.SchemaName, &proc.Name, &readOnly); err != nil {
+			return err
+		}
+		proc.ReadOnly = isTrue(readOnly)
+		procs = append(procs, proc)
+		procMap[proc.Name] = proc
+		return nil
+	}, queryProcedures, schemaName); err != nil {
+		return nil, err
+	}
+
+	if err := c.query(ctx, func(rows *sql.Rows) error {
+		prm := &Parameter{}
+		var procName, mode, nullable, hasDefault string
+		var length, scale sql.NullInt64
+		var tableTypeSchema, tableTypeName sql.NullString
+		if err := rows.Scan(&procName, &prm.Name, &prm.Position, &mode, &prm.TypeName, &length, &scale, &nullable, &hasDefault, &tableTypeSchema, &tableTypeName); err != nil {
+			return err
+		}
+		prm.Mode = ParameterMode(mode)
+		prm.DataType = driver.DataTypeOfTypeName(prm.TypeName)
+		prm.Length, prm.Scale = length.Int64, scale.Int64
+		prm.Nullable, prm.HasDefault = isTrue(nullable), isTrue(hasDefault)
+		prm.TableTypeSchema, prm.TableTypeName = tableTypeSchema.String, tableTypeName.String
+		if proc, ok := procMap[procName]; ok {
+			proc.Parameters = append(proc.Parameters, prm)
+		}
+		return nil
+	}, queryProcedureParameters, schemaName); err != nil {
+		return nil, err
+	}
+	return procs, nil
+}
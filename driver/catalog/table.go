@@ -0,0 +1,307 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// Column describes a table or view column.
+type Column struct {
+	Name         string
+	Position     int
+	TypeName     string          // Database type name (e.g. NVARCHAR, DECIMAL).
+	DataType     driver.DataType // go-hdb data type.
+	Length       int64           // Length of character and binary types, precision of decimal types.
+	Scale        int64           // Scale of decimal types.
+	Nullable     bool
+	DefaultValue sql.NullString
+	Identity     bool // Column values are generated as identity.
+	Comment      string
+}
+
+// Table describes a database table.
+type Table struct {
+	SchemaName  string
+	Name        string
+	ColumnTable bool // Column store (true) or row store (false) table.
+	Temporary   bool
+	Comment     string
+
+	// The following fields are only provided by Catalog.Table.
+	Columns     []*Column
+	PrimaryKey  *Key // nil if the table does not have a primary key.
+	UniqueKeys  []*Key
+	ForeignKeys []*ForeignKey
+	Indexes     []*Index
+}
+
+// View describes a database view.
+type View struct {
+	SchemaName string
+	Name       string
+	ViewType   string // e.g. ROW, COLUMN, JOIN, CALC.
+	Definition string // View definition (SQL).
+	Comment    string
+
+	// Columns are only provided by Catalog.View.
+	Columns []*Column
+}
+
+// Key describes a primary or unique key constraint.
+type Key struct {
+	Name        string
+	ColumnNames []string // in key order
+}
+
+// ForeignKey describes a referential constraint.
+type ForeignKey struct {
+	Name                  string
+	ColumnNames           []string
+	ReferencedSchemaName  string
+	ReferencedTableName   string
+	ReferencedColumnNames []string
+	UpdateRule            string // e.g. RESTRICT, CASCADE, SET NULL, SET DEFAULT.
+	DeleteRule            string
+}
+
+// IndexColumn describes a column of an index.
+type IndexColumn struct {
+	Name      string
+	Ascending bool
+}
+
+// Index describes a table index.
+type Index struct {
+	Name       string
+	IndexType  string // e.g. BTREE, CPBTREE, INVERTED VALUE.
+	Constraint string // PRIMARY KEY, UNIQUE or empty.
+	Columns    []*IndexColumn
+}
+
+const (
+	queryTables = `select schema_name, table_name, is_column_table, is_temporary, comments
+from sys.tables where schema_name = ? order by table_name`
+	queryTable = `select schema_name, table_name, is_column_table, is_temporary, comments
+from sys.tables where schema_name = ? and table_name = ?`
+	queryViews = `select schema_name, view_name, view_type, definition, comments
+from sys.views where schema_name = ? order by view_name`
+	queryView = `select schema_name, view_name, view_type, definition, comments
+from sys.views where schema_name = ? and view_name = ?`
+	queryTableColumns = `select column_name, position, data_type_name, length, scale, is_nullable, default_value, generation_type, comments
+from sys.table_columns where schema_name = ? and table_name = ? order by position`
+	queryViewColumns = `select column_name, position, data_type_name, length, scale, is_nullable, default_value, null, comments
+from sys.view_columns where schema_name = ? and view_name = ? order by position`
+	queryKeys = `select constraint_name, column_name, is_primary_key
+from sys.constraints where schema_name = ? and table_name = ? and (is_primary_key = 'TRUE' or is_unique_key = 'TRUE')
+order by constraint_name, position`
+	queryForeignKeys = `select constraint_name, column_name, referenced_schema_name, referenced_table_name, referenced_column_name, update_rule, delete_rule
+from sys.referential_constraints where schema_name = ? and table_name = ? order by constraint_name, position`
+	queryIndexes = `select i.index_name, i.index_type, i.constraint, c.column_name, c.ascending_order
+from sys.indexes i join sys.index_columns c on c.schema_name = i.schema_name and c.table_name = i.table_name and c.index_name = i.index_name
+where i.schema_name = ? and i.table_name = ? order by i.index_name, c.position`
+)
+
+func scanTable(rows *sql.Rows) (*Table, error) {
+	t := &Table{}
+	var columnTable, temporary string
+	var comment sql.NullString
+	if err := rows.Scan(&t.SchemaName, &t.Name, &columnTable, &temporary, &comment); err != nil {
+		return nil, err
+	}
+	t.ColumnTable, t.Temporary, t.Comment = isTrue(columnTable), isTrue(temporary), comment.String
+	return t, nil
+}
+
+// Tables returns the tables of a schema. Columns, keys and indexes are not provided.
+func (c *Catalog) Tables(ctx context.Context, schemaName string) ([]*Table, error) {
+	var tables []*Table
+	err := c.query(ctx, func(rows *sql.Rows) error {
+		t, err := scanTable(rows)
+		if err != nil {
+			return err
+		}
+		tables = append(tables, t)
+		return nil
+	}, queryTables, schemaName)
+	return tables, err
+}
+
+// Table returns the table including columns, keys and indexes.
+func (c *Catalog) Table(ctx context.Context, schemaName, tableName string) (*Table, error) {
+	var t *Table
+	err := c.query(ctx, func(rows *sql.Rows) (err error) {
+		t, err = scanTable(rows)
+		return err
+	}, queryTable, schemaName, tableName)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, fmt.Errorf("table %s.%s not found", schemaName, tableName)
+	}
+
+	if t.Columns, err = c.columns(ctx, queryTableColumns, schemaName, tableName); err != nil {
+		return nil, err
+	}
+	if err := c.keys(ctx, t); err != nil {
+		return nil, err
+	}
+	if t.ForeignKeys, err = c.foreignKeys(ctx, schemaName, tableName); err != nil {
+		return nil, err
+	}
+	if t.Indexes, err = c.indexes(ctx, schemaName, tableName); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func scanView(rows *sql.Rows) (*View, error) {
+	v := &View{}
+	var viewType, comment sql.NullString
+	definition := &lobString{}
+	if err := rows.Scan(&v.SchemaName, &v.Name, &viewType, definition, &comment); err != nil {
+		return nil, err
+	}
+	v.ViewType, v.Definition, v.Comment = viewType.String, definition.String(), comment.String
+	return v, nil
+}
+
+// Views returns the views of a schema. Columns are not provided.
+func (c *Catalog) Views(ctx context.Context, schemaName string) ([]*View, error) {
+	var views []*View
+	err := c.query(ctx, func(rows *sql.Rows) error {
+		v, err := scanView(rows)
+		if err != nil {
+			return err
+		}
+		views = append(views, v)
+		return nil
+	}, queryViews, schemaName)
+	return views, err
+}
+
+// View returns the view including columns.
+func (c *Catalog) View(ctx context.Context, schemaName, viewName string) (*View, error) {
+	var v *View
+	err := c.query(ctx, func(rows *sql.Rows) (err error) {
+		v, err = scanView(rows)
+		return err
+	}, queryView, schemaName, viewName)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return nil, fmt.Errorf("view %s.%s not found", schemaName, viewName)
+	}
+	if v.Columns, err = c.columns(ctx, queryViewColumns, schemaName, viewName); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Columns returns the columns of a table or view.
+func (c *Catalog) Columns(ctx context.Context, schemaName, name string) ([]*Column, error) {
+	cols, err := c.columns(ctx, queryTableColumns, schemaName, name)
+	if err != nil || len(cols) != 0 {
+		return cols, err
+	}
+	return c.columns(ctx, queryViewColumns, schemaName, name)
+}
+
+func (c *Catalog) columns(ctx context.Context, query, schemaName, name string) ([]*Column, error) {
+	var cols []*Column
+	err := c.query(ctx, func(rows *sql.Rows) error {
+		col := &Column{}
+		var length, scale sql.NullInt64
+		var nullable string
+		var generationType, comment sql.NullString
+		if err := rows.Scan(&col.Name, &col.Position, &col.TypeName, &length, &scale, &nullable, &col.DefaultValue, &generationType, &comment); err != nil {
+			return err
+		}
+		col.DataType = driver.DataTypeOfTypeName(col.TypeName)
+		col.Length, col.Scale = length.Int64, scale.Int64
+		col.Nullable = isTrue(nullable)
+		col.Identity = isIdentity(generationType.String)
+		col.Comment = comment.String
+		cols = append(cols, col)
+		return nil
+	}, query, schemaName, name)
+	return cols, err
+}
+
+// isIdentity returns true for identity generation types (ALWAYS AS IDENTITY, BY DEFAULT AS IDENTITY).
+func isIdentity(generationType string) bool {
+	return generationType == "ALWAYS AS IDENTITY" || generationType == "BY DEFAULT AS IDENTITY"
+}
+
+func (c *Catalog) keys(ctx context.Context, t *Table) error {
+	var key *Key
+	return c.query(ctx, func(rows *sql.Rows) error {
+		var name, columnName, primaryKey string
+		if err := rows.Scan(&name, &columnName, &primaryKey); err != nil {
+			return err
+		}
+		if key == nil || key.Name != name {
+			key = &Key{Name: name}
+			if isTrue(primaryKey) {
+				t.PrimaryKey = key
+			} else {
+				t.UniqueKeys = append(t.UniqueKeys, key)
+			}
+		}
+		key.ColumnNames = append(key.ColumnNames, columnName)
+		return nil
+	}, queryKeys, t.SchemaName, t.Name)
+}
+
+func (c *Catalog) foreignKeys(ctx context.Context, schemaName, tableName string) ([]*ForeignKey, error) {
+	var fks []*ForeignKey
+	var fk *ForeignKey
+	err := c.query(ctx, func(rows *sql.Rows) error {
+		var name, columnName, refSchemaName, refTableName, refColumnName string
+		var updateRule, deleteRule sql.NullString
+		if err := rows.Scan(&name, &columnName, &refSchemaName, &refTableName, &refColumnName, &updateRule, &deleteRule); err != nil {
+			return err
+		}
+		if fk == nil || fk.Name != name {
+			fk = &ForeignKey{
+				Name:                 name,
+				ReferencedSchemaName: refSchemaName,
+				ReferencedTableName:  refTableName,
+				UpdateRule:           updateRule.String,
+				DeleteRule:           deleteRule.String,
+			}
+			fks = append(fks, fk)
+		}
+		fk.ColumnNames = append(fk.ColumnNames, columnName)
+		fk.ReferencedColumnNames = append(fk.ReferencedColumnNames, refColumnName)
+		return nil
+	}, queryForeignKeys, schemaName, tableName)
+	return fks, err
+}
+
+func (c *Catalog) indexes(ctx context.Context, schemaName, tableName string) ([]*Index, error) {
+	var indexes []*Index
+	var index *Index
+	err := c.query(ctx, func(rows *sql.Rows) error {
+		var name, columnName, ascending string
+		var indexType, constraint sql.NullString
+		if err := rows.Scan(&name, &indexType, &constraint, &columnName, &ascending); err != nil {
+			return err
+		}
+		if index == nil || index.Name != name {
+			index = &Index{Name: name, IndexType: indexType.String, Constraint: constraint.String}
+			indexes = append(indexes, index)
+		}
+		index.Columns = append(index.Columns, &IndexColumn{Name: columnName, Ascending: isTrue(ascending)})
+		return nil
+	}, queryIndexes, schemaName, tableName)
+	return indexes, err
+}
@@ -0,0 +1,347 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/internal/pkcs"
+	"golang.org/x/crypto/pkcs12"
+)
+
+// ErrIncorrectPassphrase is returned if an encrypted client key or PKCS#12 bundle cannot be decrypted.
+var ErrIncorrectPassphrase = pkcs.ErrIncorrectPassphrase
+
+// DefaultClientCertWatchInterval is the default interval the client certificate files are checked for changes.
+const DefaultClientCertWatchInterval = 10 * time.Second
+
+// A ClientCertLoader loads the X509 client certificate (chain) and the client key.
+// The certificate chain and the key are returned PEM encoded and unencrypted.
+type ClientCertLoader interface {
+	Load() (clientCert, clientKey []byte, err error)
+	// Files returns the files the client certificate and key are loaded from (see Connector.WatchClientCert).
+	Files() []string
+}
+
+type pemClientCertLoader struct {
+	certFile, keyFile, passphrase string
+}
+
+// NewPEMClientCertLoader returns a ClientCertLoader loading the client certificate and key from PEM files.
+// The certificate file may contain the certificate chain. The key file may contain an encrypted
+// PKCS#8 key (ENCRYPTED PRIVATE KEY) or a legacy encrypted PEM key (Proc-Type: 4,ENCRYPTED)
+// which are decrypted with passphrase.
+func NewPEMClientCertLoader(certFile, keyFile, passphrase string) ClientCertLoader {
+	return &pemClientCertLoader{certFile: certFile, keyFile: keyFile, passphrase: passphrase}
+}
+
+func (l *pemClientCertLoader) Files() []string { return []string{l.certFile, l.keyFile} }
+func (l *pemClientCertLoader) Load() ([]byte, []byte, error) {
+	cert, err := os.ReadFile(l.certFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := os.ReadFile(l.keyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParsePEMClientCert(cert, key, l.passphrase)
+}
+
+type pkcs12ClientCertLoader struct {
+	file, passphrase string
+}
+
+// NewPKCS12ClientCertLoader returns a ClientCertLoader loading the client certificate chain and key from
+// a PKCS#12 (.p12, .pfx) file (see ParsePKCS12ClientCert).
+func NewPKCS12ClientCertLoader(file, passphrase string) ClientCertLoader {
+	return &pkcs12ClientCertLoader{file: file, passphrase: passphrase}
+}
+
+func (l *pkcs12ClientCertLoader) Files() []string { return []string{l.file} }
+func (l *pkcs12ClientCertLoader) Load() ([]byte, []byte, error) {
+	data, err := os.ReadFile(l.file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParsePKCS12ClientCert(data, l.passphrase)
+}
+
+// pemKey returns the (decrypted) private key of a PEM block in PKCS#8 DER encoding.
+func pemKey(block *pem.Block, passphrase string) ([]byte, error) {
+	encrypted := block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block) //nolint:staticcheck
+	if encrypted && passphrase == "" {
+		return nil, errors.New("client key is password encrypted: passphrase required")
+	}
+
+	switch {
+	case block.Type == "ENCRYPTED PRIVATE KEY":
+		return pkcs.DecryptPKCS8(block.Bytes, passphrase)
+	case encrypted:
+		der, err := x509.DecryptPEMBlock(block, []byte(passphrase)) //nolint:staticcheck
+		if err != nil {
+			return nil, ErrIncorrectPassphrase
+		}
+		return derKey(block.Type, der)
+	default:
+		return derKey(block.Type, block.Bytes)
+	}
+}
+
+// derKey converts a DER encoded PKCS#1, EC or PKCS#8 private key into PKCS#8.
+func derKey(typ string, der []byte) ([]byte, error) {
+	var key interface{}
+	var err error
+	switch typ {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(der)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(der)
+	case "PRIVATE KEY":
+		_, err = x509.ParsePKCS8PrivateKey(der)
+		return der, err
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// clientCertPEM orders the certificate chain starting with the certificate matching the key followed by the
+// issuing certificates and returns the PEM encoded certificate chain and key.
+func clientCertPEM(certs [][]byte, key []byte) ([]byte, []byte, error) {
+	k, err := x509.ParsePKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	signer, ok := k.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported key type %T", k)
+	}
+	pub, ok := signer.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported public key type %T", signer.Public())
+	}
+
+	parsed := make([]*x509.Certificate, 0, len(certs))
+	leaf := -1
+	for i, der := range certs {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, nil, err
+		}
+		if leaf == -1 && pub.Equal(cert.PublicKey) {
+			leaf = i
+		}
+		parsed = append(parsed, cert)
+	}
+	if leaf == -1 {
+		return nil, nil, errors.New("client key does not match any client certificate")
+	}
+
+	chain := []*x509.Certificate{parsed[leaf]}
+	used := map[int]bool{leaf: true}
+	for cert := parsed[leaf]; ; {
+		next := -1
+		for i, c := range parsed {
+			if !used[i] && bytes.Equal(cert.RawIssuer, c.RawSubject) {
+				next = i
+				break
+			}
+		}
+		if next == -1 {
+			break
+		}
+		used[next] = true
+		cert = parsed[next]
+		chain = append(chain, cert)
+	}
+	for i, c := range parsed { // append unrelated certificates in original order
+		if !used[i] {
+			chain = append(chain, c)
+		}
+	}
+
+	var certBuf bytes.Buffer
+	for _, c := range chain {
+		if err := pem.Encode(&certBuf, &pem.Block{Type: "CERTIFICATE", Bytes: c.Raw}); err != nil {
+			return nil, nil, err
+		}
+	}
+	return certBuf.Bytes(), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), nil
+}
+
+// ParsePEMClientCert parses a PEM encoded client certificate (chain) and a PEM encoded, optionally encrypted,
+// client key (see NewPEMClientCertLoader) and returns the certificate chain ordered from the client
+// certificate to the root and the unencrypted PKCS#8 client key.
+func ParsePEMClientCert(cert, key []byte, passphrase string) (clientCert, clientKey []byte, err error) {
+	var certs [][]byte
+	for block, rest := pem.Decode(cert); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, block.Bytes)
+		}
+	}
+	if len(certs) == 0 {
+		return nil, nil, errors.New("invalid client cert: no certificate found")
+	}
+
+	var der []byte
+	for block, rest := pem.Decode(key); block != nil; block, rest = pem.Decode(rest) {
+		if !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			continue // e.g. EC PARAMETERS
+		}
+		if der != nil {
+			return nil, nil, errors.New("invalid client key: more than one key found")
+		}
+		if der, err = pemKey(block, passphrase); err != nil {
+			return nil, nil, err
+		}
+	}
+	if der == nil {
+		return nil, nil, errors.New("invalid client key: no key found")
+	}
+	return clientCertPEM(certs, der)
+}
+
+// ParsePKCS12ClientCert parses a PKCS#12 bundle and returns the certificate chain ordered from the client
+// certificate to the root and the unencrypted PKCS#8 client key. Supported are bundles encrypted with PBES2
+// (PBKDF2 with AES or 3DES, default of OpenSSL 3) and the legacy PKCS#12 3DES and RC2 schemes.
+func ParsePKCS12ClientCert(data []byte, passphrase string) (clientCert, clientKey []byte, err error) {
+	key, certs, err := pkcs.DecodePKCS12(data, passphrase)
+	if pkcs.IsLegacyRC2(err) {
+		key, certs, err = decodeLegacyPKCS12(data, passphrase)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return clientCertPEM(certs, key)
+}
+
+func decodeLegacyPKCS12(data []byte, passphrase string) ([]byte, [][]byte, error) {
+	blocks, err := pkcs12.ToPEM(data, passphrase)
+	if err != nil {
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) || errors.Is(err, pkcs12.ErrDecryption) {
+			return nil, nil, ErrIncorrectPassphrase
+		}
+		return nil, nil, err
+	}
+	var key []byte
+	var certs [][]byte
+	for _, block := range blocks {
+		switch block.Type {
+		case "CERTIFICATE":
+			certs = append(certs, block.Bytes)
+		case "PRIVATE KEY": // PKCS#1 or EC key
+			if key, err = derKey("RSA PRIVATE KEY", block.Bytes); err != nil {
+				if key, err = derKey("EC PRIVATE KEY", block.Bytes); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+	if key == nil {
+		return nil, nil, errors.New("PKCS#12 data does not contain a private key")
+	}
+	return key, certs, nil
+}
+
+// NewX509AuthConnectorByLoader creates a connector for X509 (client certificate) authentication
+// loading the client certificate and key by loader. The loader is used as well to refresh the client
+// certificate in case of an authentication failure (see SetRefreshClientCert).
+func NewX509AuthConnectorByLoader(host string, loader ClientCertLoader) (*Connector, error) {
+	clientCert, clientKey, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+	c := NewX509AuthConnector(host, clientCert, clientKey)
+	c.SetRefreshClientCert(clientCertRefresher(loader))
+	return c, nil
+}
+
+// clientCertRefresher returns a client certificate refresh function based on loader.
+func clientCertRefresher(loader ClientCertLoader) func() ([]byte, []byte, bool) {
+	return func() ([]byte, []byte, bool) {
+		clientCert, clientKey, err := loader.Load()
+		if err != nil {
+			dlog.Printf("refresh client certificate: %s", err)
+			return nil, nil, false
+		}
+		return clientCert, clientKey, true
+	}
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func statFiles(files []string) []fileStat {
+	stats := make([]fileStat, len(files))
+	for i, file := range files {
+		if fi, err := os.Stat(file); err == nil {
+			stats[i] = fileStat{modTime: fi.ModTime(), size: fi.Size()}
+		}
+	}
+	return stats
+}
+
+func equalFileStats(a, b []fileStat) bool {
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+	return true
+}
+
+// WatchClientCert checks the files of loader every interval (default DefaultClientCertWatchInterval if
+// interval <= 0) for changes and reloads the client certificate and key of the connector after a change.
+// As certificate and key files might not be updated at once, failed reloads (e.g. key not matching the
+// certificate) are retried in the next interval. Call the returned stop function to end watching.
+func (c *Connector) WatchClientCert(loader ClientCertLoader, interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = DefaultClientCertWatchInterval
+	}
+	files := loader.Files()
+	stats := statFiles(files)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				current := statFiles(files)
+				if equalFileStats(stats, current) {
+					continue
+				}
+				clientCert, clientKey, err := loader.Load()
+				if err != nil {
+					dlog.Printf("reload client certificate: %s", err)
+					continue
+				}
+				c.authAttrs.setClientCert(clientCert, clientKey)
+				stats = current
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, cn string, issuer *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  issuer == nil,
+		BasicConstraintsValid: true,
+	}
+	parent, parentKey := template, key
+	if issuer != nil {
+		parent, parentKey = issuer.cert, issuer.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) certPEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+}
+
+func (c *testCert) keyPEM(t *testing.T, passphrase string) []byte {
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passphrase == "" {
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	}
+	block, err := x509.EncryptPEMBlock(rand.Reader, "EC PRIVATE KEY", der, []byte(passphrase), x509.PEMCipherAES256) //nolint:staticcheck
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(block)
+}
+
+func testParsePEMClientCert(t *testing.T) {
+	ca := newTestCert(t, "Test CA", nil)
+	leaf := newTestCert(t, "TESTUSER", ca)
+	other := newTestCert(t, "OTHER", ca)
+
+	// chain in reverse order
+	certs := append(ca.certPEM(), leaf.certPEM()...)
+
+	clientCert, clientKey, err := ParsePEMClientCert(certs, leaf.keyPEM(t, ""), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := append(leaf.certPEM(), ca.certPEM()...); !bytes.Equal(clientCert, expected) {
+		t.Fatalf("client cert\n%s\nexpected\n%s", clientCert, expected)
+	}
+	if block, _ := pem.Decode(clientKey); block == nil || block.Type != "PRIVATE KEY" {
+		t.Fatal("client key: PKCS#8 PEM block expected")
+	}
+
+	// encrypted key
+	if _, _, err := ParsePEMClientCert(certs, leaf.keyPEM(t, "secret"), ""); err == nil {
+		t.Fatal("expected passphrase required error")
+	}
+	if _, _, err := ParsePEMClientCert(certs, leaf.keyPEM(t, "secret"), "wrong"); !errors.Is(err, ErrIncorrectPassphrase) {
+		t.Fatalf("expected error %v - got %v", ErrIncorrectPassphrase, err)
+	}
+	if _, _, err := ParsePEMClientCert(certs, leaf.keyPEM(t, "secret"), "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// key not matching certificate
+	if _, _, err := ParsePEMClientCert(certs, other.keyPEM(t, ""), ""); err == nil {
+		t.Fatal("expected key mismatch error")
+	}
+}
+
+func testWatchClientCert(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+
+	write := func(c *testCert) {
+		if err := os.WriteFile(certFile, c.certPEM(), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(keyFile, c.keyPEM(t, "secret"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cert1 := newTestCert(t, "TESTUSER", nil)
+	write(cert1)
+
+	loader := NewPEMClientCertLoader(certFile, keyFile, "secret")
+	c, err := NewX509AuthConnectorByLoader("localhost:39013", loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clientCert, _ := c.ClientCert(); !bytes.Equal(clientCert, cert1.certPEM()) {
+		t.Fatal("unexpected client certificate")
+	}
+
+	stop := c.WatchClientCert(loader, 10*time.Millisecond)
+	defer stop()
+
+	cert2 := newTestCert(t, "TESTUSER", nil)
+	write(cert2)
+	// ensure modification is detected on file systems with coarse modification time granularity
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		if clientCert, _ := c.ClientCert(); bytes.Equal(clientCert, cert2.certPEM()) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("client certificate not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// refresh callback
+	if _, _, ok := c.RefreshClientCert()(); !ok {
+		t.Fatal("refresh client certificate failed")
+	}
+}
+
+func TestClientCert(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"parsePEMClientCert", testParsePEMClientCert},
+		{"watchClientCert", testWatchClientCert},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
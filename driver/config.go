@@ -0,0 +1,698 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	p "github.com/SAP/go-hdb/driver/internal/protocol"
+)
+
+// Config environment variables (see ConfigFromEnv).
+const (
+	EnvConfigFile              = "HDB_CONFIG_FILE"                // Path,- filename to a JSON configuration file (see LoadConfig).
+	EnvHost                    = "HDB_HOST"                       // Host (<host address>:<port number>).
+	EnvUser                    = "HDB_USER"                       // Username.
+	EnvPassword                = "HDB_PASSWORD"                   // Password.
+	EnvPasswordFile            = "HDB_PASSWORD_FILE"              // Path,- filename to a password file.
+	EnvDefaultSchema           = "HDB_DEFAULT_SCHEMA"             // Database default schema.
+	EnvAuthMethods             = "HDB_AUTH_METHODS"               // Comma separated list of allowed authentication methods.
+	EnvClientCertFile          = "HDB_CLIENT_CERT_FILE"           // Path,- filename to the X509 client certificate (chain) PEM file.
+	EnvClientKeyFile           = "HDB_CLIENT_KEY_FILE"            // Path,- filename to the X509 client key PEM file.
+	EnvPKCS12File              = "HDB_PKCS12_FILE"                // Path,- filename to a PKCS#12 client certificate file.
+	EnvClientKeyPassphrase     = "HDB_CLIENT_KEY_PASSPHRASE"      // Passphrase of the client key or PKCS#12 file.
+	EnvClientKeyPassphraseFile = "HDB_CLIENT_KEY_PASSPHRASE_FILE" // Path,- filename to a file containing the client key passphrase.
+	EnvToken                   = "HDB_TOKEN"                      // JWT token.
+	EnvTokenFile               = "HDB_TOKEN_FILE"                 // Path,- filename to a JWT token file.
+	EnvTLSServerName           = "HDB_TLS_SERVER_NAME"            // ServerName to verify the hostname.
+	EnvTLSInsecureSkipVerify   = "HDB_TLS_INSECURE_SKIP_VERIFY"   // Controls whether a client verifies the server's certificate chain and host name.
+	EnvTLSRootCAFile           = "HDB_TLS_ROOT_CA_FILE"           // List of root certificate files separated by the OS path list separator.
+	EnvTimeout                 = "HDB_TIMEOUT"                    // Driver side connection timeout.
+	EnvPingInterval            = "HDB_PING_INTERVAL"              // Connection ping interval.
+	EnvTCPKeepAlive            = "HDB_TCP_KEEP_ALIVE"             // TCP keep-alive interval.
+	EnvBufferSize              = "HDB_BUFFER_SIZE"                // Size of the connection read and write buffers in bytes.
+	EnvFetchSize               = "HDB_FETCH_SIZE"                 // Number of rows fetched per database round trip.
+	EnvBulkSize                = "HDB_BULK_SIZE"                  // Number of rows sent per database round trip in bulk operations.
+	EnvLobChunkSize            = "HDB_LOB_CHUNK_SIZE"             // Size of lob chunks in bytes.
+	EnvLocale                  = "HDB_LOCALE"                     // Client locale.
+	EnvApplicationName         = "HDB_APPLICATION_NAME"           // Application name reported to the database.
+	EnvDfv                     = "HDB_DFV"                        // Data format version.
+	EnvLegacy                  = "HDB_LEGACY"                     // Legacy mode.
+	EnvMaxOpenConns            = "HDB_MAX_OPEN_CONNS"             // Maximum number of open connections of the connection pool.
+	EnvMaxIdleConns            = "HDB_MAX_IDLE_CONNS"             // Maximum number of idle connections of the connection pool.
+	EnvConnMaxLifetime         = "HDB_CONN_MAX_LIFETIME"          // Maximum amount of time a connection may be reused.
+	EnvConnMaxIdleTime         = "HDB_CONN_MAX_IDLE_TIME"         // Maximum amount of time a connection may be idle.
+)
+
+/*
+EnvSessionVariablePrefix is the prefix of session variable environment variables.
+A session variable is set by the environment variable <prefix><variable name>=<value>, e.g.
+
+	HDB_SESSION_VARIABLE_APPLICATIONUSER=user
+*/
+const EnvSessionVariablePrefix = "HDB_SESSION_VARIABLE_"
+
+/*
+A Duration is a time.Duration which is represented in JSON and environment variables
+either as duration string (e.g. "90s" or "1m30s") or as number of seconds.
+*/
+type Duration time.Duration
+
+func parseDuration(s string) (Duration, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if i > math.MaxInt64/int64(time.Second) || i < math.MinInt64/int64(time.Second) {
+			return 0, fmt.Errorf("duration %s out of range", s)
+		}
+		return Duration(time.Duration(i) * time.Second), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %s", s)
+	}
+	return Duration(d), nil
+}
+
+// String implements the fmt.Stringer interface.
+func (d Duration) String() string { return time.Duration(d).String() }
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d Duration) MarshalJSON() ([]byte, error) { return json.Marshal(d.String()) }
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case string:
+		pd, err := parseDuration(v)
+		if err != nil {
+			return err
+		}
+		*d = pd
+	case float64:
+		if v != math.Trunc(v) {
+			return fmt.Errorf("invalid duration %s - number of seconds expected", b)
+		}
+		pd, err := parseDuration(strconv.FormatFloat(v, 'f', -1, 64))
+		if err != nil {
+			return err
+		}
+		*d = pd
+	default:
+		return fmt.Errorf("invalid duration %s", b)
+	}
+	return nil
+}
+
+// PoolConfig is holding the connection pool settings of a Config (see sql.DB).
+type PoolConfig struct {
+	MaxOpenConns    int      `json:"maxOpenConns,omitempty"`    // see sql.DB.SetMaxOpenConns (0: unlimited)
+	MaxIdleConns    int      `json:"maxIdleConns,omitempty"`    // see sql.DB.SetMaxIdleConns (0: database/sql default)
+	ConnMaxLifetime Duration `json:"connMaxLifetime,omitempty"` // see sql.DB.SetConnMaxLifetime (0: unlimited)
+	ConnMaxIdleTime Duration `json:"connMaxIdleTime,omitempty"` // see sql.DB.SetConnMaxIdleTime (0: unlimited)
+}
+
+// Apply applies the pool settings to db. Zero values keep the database/sql defaults.
+func (c *PoolConfig) Apply(db *sql.DB) {
+	if c.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(c.MaxOpenConns)
+	}
+	if c.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(c.MaxIdleConns)
+	}
+	if c.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(time.Duration(c.ConnMaxLifetime))
+	}
+	if c.ConnMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(time.Duration(c.ConnMaxIdleTime))
+	}
+}
+
+/*
+A Config is a declarative connector configuration which can be loaded from a JSON file (see LoadConfig)
+or from environment variables (see ConfigFromEnv). Zero values keep the connector defaults.
+
+Example JSON configuration:
+
+	{
+		"host": "localhost:39013",
+		"username": "myuser",
+		"passwordFile": "/run/secrets/hdb-password",
+		"tls": {"serverName": "hostname", "rootCAFiles": ["trust.pem"]},
+		"fetchSize": 1000,
+		"timeout": "60s",
+		"sessionVariables": {"APPLICATIONUSER": "user"},
+		"pool": {"maxOpenConns": 10, "connMaxLifetime": "1h"}
+	}
+
+Credentials are provided by exactly one source per authentication method:
+  - basic authentication: password or passwordFile (requires username),
+  - X509 authentication: clientCertFile and clientKeyFile or pkcs12File, optionally with
+    clientKeyPassphrase or clientKeyPassphraseFile,
+  - JWT authentication: token or tokenFile.
+
+Files are re-read if the database rejects the credentials, so that rotated secrets are picked up
+without restarting the application.
+*/
+type Config struct {
+	Host          string `json:"host"`
+	Username      string `json:"username,omitempty"`
+	Password      string `json:"password,omitempty"`
+	PasswordFile  string `json:"passwordFile,omitempty"`
+	DefaultSchema string `json:"defaultSchema,omitempty"`
+
+	AuthMethods             []string `json:"authMethods,omitempty"`
+	ClientCertFile          string   `json:"clientCertFile,omitempty"`
+	ClientKeyFile           string   `json:"clientKeyFile,omitempty"`
+	PKCS12File              string   `json:"pkcs12File,omitempty"`
+	ClientKeyPassphrase     string   `json:"clientKeyPassphrase,omitempty"`
+	ClientKeyPassphraseFile string   `json:"clientKeyPassphraseFile,omitempty"`
+	Token                   string   `json:"token,omitempty"`
+	TokenFile               string   `json:"tokenFile,omitempty"`
+
+	TLS *TLSPrms `json:"tls,omitempty"`
+
+	Timeout          Duration          `json:"timeout,omitempty"`
+	PingInterval     Duration          `json:"pingInterval,omitempty"`
+	TCPKeepAlive     *Duration         `json:"tcpKeepAlive,omitempty"`
+	BufferSize       int               `json:"bufferSize,omitempty"`
+	FetchSize        int               `json:"fetchSize,omitempty"`
+	BulkSize         int               `json:"bulkSize,omitempty"`
+	LobChunkSize     int               `json:"lobChunkSize,omitempty"`
+	Locale           string            `json:"locale,omitempty"`
+	ApplicationName  string            `json:"applicationName,omitempty"`
+	Dfv              int               `json:"dfv,omitempty"`
+	Legacy           *bool             `json:"legacy,omitempty"`
+	SessionVariables map[string]string `json:"sessionVariables,omitempty"`
+
+	Pool PoolConfig `json:"pool,omitempty"`
+}
+
+// ConfigError is the error returned in case a Config is invalid.
+type ConfigError struct {
+	field string
+	err   error
+}
+
+func configError(field, format string, a ...interface{}) error {
+	return &ConfigError{field: field, err: fmt.Errorf(format, a...)}
+}
+
+func (e *ConfigError) Error() string { return fmt.Sprintf("invalid config %s: %s", e.field, e.err) }
+
+// Unwrap returns the nested error.
+func (e *ConfigError) Unwrap() error { return e.err }
+
+// Field returns the name of the invalid configuration field (JSON name or environment variable).
+func (e *ConfigError) Field() string { return e.field }
+
+// ParseConfig parses a JSON configuration. Unknown fields are reported as error.
+func ParseConfig(data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	cfg := &Config{}
+	if err := dec.Decode(cfg); err != nil {
+		return nil, &ConfigError{field: "json", err: err}
+	}
+	return cfg, nil
+}
+
+// LoadConfig loads a JSON configuration from file.
+func LoadConfig(file string) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+// ConfigFromEnv returns a configuration based on the Env* environment variables. If EnvConfigFile
+// is set, the configuration file is loaded first and the environment variables override the file settings.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{}
+	if file, ok := os.LookupEnv(EnvConfigFile); ok && file != "" {
+		var err error
+		if cfg, err = LoadConfig(file); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.setEnv(os.LookupEnv, os.Environ()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func envInt(k, v string, dst *int) error {
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return configError(k, "invalid integer %s", v)
+	}
+	*dst = i
+	return nil
+}
+
+func envBool(k, v string) (bool, error) {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, configError(k, "invalid boolean %s", v)
+	}
+	return b, nil
+}
+
+func envDuration(k, v string, dst *Duration) error {
+	d, err := parseDuration(v)
+	if err != nil {
+		return &ConfigError{field: k, err: err}
+	}
+	*dst = d
+	return nil
+}
+
+func (cfg *Config) tlsPrms() *TLSPrms {
+	if cfg.TLS == nil {
+		cfg.TLS = &TLSPrms{}
+	}
+	return cfg.TLS
+}
+
+func (cfg *Config) setEnv(lookup func(string) (string, bool), environ []string) error {
+	strs := []struct {
+		k   string
+		dst *string
+	}{
+		{EnvHost, &cfg.Host},
+		{EnvUser, &cfg.Username},
+		{EnvPassword, &cfg.Password},
+		{EnvPasswordFile, &cfg.PasswordFile},
+		{EnvDefaultSchema, &cfg.DefaultSchema},
+		{EnvClientCertFile, &cfg.ClientCertFile},
+		{EnvClientKeyFile, &cfg.ClientKeyFile},
+		{EnvPKCS12File, &cfg.PKCS12File},
+		{EnvClientKeyPassphrase, &cfg.ClientKeyPassphrase},
+		{EnvClientKeyPassphraseFile, &cfg.ClientKeyPassphraseFile},
+		{EnvToken, &cfg.Token},
+		{EnvTokenFile, &cfg.TokenFile},
+		{EnvLocale, &cfg.Locale},
+		{EnvApplicationName, &cfg.ApplicationName},
+	}
+	for _, s := range strs {
+		if v, ok := lookup(s.k); ok {
+			*s.dst = v
+		}
+	}
+
+	ints := []struct {
+		k   string
+		dst *int
+	}{
+		{EnvBufferSize, &cfg.BufferSize},
+		{EnvFetchSize, &cfg.FetchSize},
+		{EnvBulkSize, &cfg.BulkSize},
+		{EnvLobChunkSize, &cfg.LobChunkSize},
+		{EnvDfv, &cfg.Dfv},
+		{EnvMaxOpenConns, &cfg.Pool.MaxOpenConns},
+		{EnvMaxIdleConns, &cfg.Pool.MaxIdleConns},
+	}
+	for _, i := range ints {
+		if v, ok := lookup(i.k); ok {
+			if err := envInt(i.k, v, i.dst); err != nil {
+				return err
+			}
+		}
+	}
+
+	durations := []struct {
+		k   string
+		dst *Duration
+	}{
+		{EnvTimeout, &cfg.Timeout},
+		{EnvPingInterval, &cfg.PingInterval},
+		{EnvConnMaxLifetime, &cfg.Pool.ConnMaxLifetime},
+		{EnvConnMaxIdleTime, &cfg.Pool.ConnMaxIdleTime},
+	}
+	for _, d := range durations {
+		if v, ok := lookup(d.k); ok {
+			if err := envDuration(d.k, v, d.dst); err != nil {
+				return err
+			}
+		}
+	}
+
+	if v, ok := lookup(EnvTCPKeepAlive); ok {
+		var d Duration
+		if err := envDuration(EnvTCPKeepAlive, v, &d); err != nil {
+			return err
+		}
+		cfg.TCPKeepAlive = &d
+	}
+	if v, ok := lookup(EnvLegacy); ok {
+		b, err := envBool(EnvLegacy, v)
+		if err != nil {
+			return err
+		}
+		cfg.Legacy = &b
+	}
+	if v, ok := lookup(EnvAuthMethods); ok {
+		cfg.AuthMethods = nil
+		for _, mt := range strings.Split(v, ",") {
+			cfg.AuthMethods = append(cfg.AuthMethods, strings.TrimSpace(mt))
+		}
+	}
+
+	if v, ok := lookup(EnvTLSServerName); ok {
+		cfg.tlsPrms().ServerName = v
+	}
+	if v, ok := lookup(EnvTLSInsecureSkipVerify); ok {
+		b, err := envBool(EnvTLSInsecureSkipVerify, v)
+		if err != nil {
+			return err
+		}
+		cfg.tlsPrms().InsecureSkipVerify = b
+	}
+	if v, ok := lookup(EnvTLSRootCAFile); ok {
+		cfg.tlsPrms().RootCAFiles = filepath.SplitList(v)
+	}
+
+	for _, kv := range environ {
+		i := strings.IndexByte(kv, '=')
+		if i < 0 || !strings.HasPrefix(kv, EnvSessionVariablePrefix) {
+			continue
+		}
+		k, v := kv[:i], kv[i+1:]
+		if cfg.SessionVariables == nil {
+			cfg.SessionVariables = map[string]string{}
+		}
+		cfg.SessionVariables[k[len(EnvSessionVariablePrefix):]] = v
+	}
+	return nil
+}
+
+func checkExclusive(fields ...string) error {
+	var set []string
+	for i := 0; i < len(fields); i += 2 {
+		if fields[i+1] != "" {
+			set = append(set, fields[i])
+		}
+	}
+	if len(set) > 1 {
+		return configError(set[0], "must not be combined with %s", strings.Join(set[1:], ", "))
+	}
+	return nil
+}
+
+func checkIntRange(field string, v, min, max int) error {
+	if v != 0 && (v < min || v > max) {
+		return configError(field, "value %d out of range %d - %d", v, min, max)
+	}
+	return nil
+}
+
+func checkFile(field, file string) error {
+	if file == "" {
+		return nil
+	}
+	fi, err := os.Stat(file)
+	if err != nil {
+		return &ConfigError{field: field, err: err}
+	}
+	if fi.IsDir() {
+		return configError(field, "%s is a directory", file)
+	}
+	return nil
+}
+
+// Validate checks the configuration for consistency. Errors are reported as ConfigError.
+func (cfg *Config) Validate() error {
+	if cfg.Host == "" {
+		return configError("host", "host is empty")
+	}
+	if _, port, err := net.SplitHostPort(cfg.Host); err != nil {
+		return &ConfigError{field: "host", err: err}
+	} else if n, err := strconv.Atoi(port); err != nil || n < 1 || n > math.MaxUint16 {
+		return configError("host", "invalid port %s", port)
+	}
+
+	// credentials
+	if err := checkExclusive("password", cfg.Password, "passwordFile", cfg.PasswordFile); err != nil {
+		return err
+	}
+	if (cfg.Password != "" || cfg.PasswordFile != "") && cfg.Username == "" {
+		return configError("username", "username is required for password authentication")
+	}
+	if (cfg.ClientCertFile == "") != (cfg.ClientKeyFile == "") {
+		return configError("clientCertFile", "clientCertFile and clientKeyFile need to be set together")
+	}
+	if err := checkExclusive("clientCertFile", cfg.ClientCertFile, "pkcs12File", cfg.PKCS12File); err != nil {
+		return err
+	}
+	if err := checkExclusive("clientKeyPassphrase", cfg.ClientKeyPassphrase, "clientKeyPassphraseFile", cfg.ClientKeyPassphraseFile); err != nil {
+		return err
+	}
+	x509 := cfg.ClientCertFile != "" || cfg.PKCS12File != ""
+	if !x509 && (cfg.ClientKeyPassphrase != "" || cfg.ClientKeyPassphraseFile != "") {
+		return configError("clientKeyPassphrase", "passphrase requires clientKeyFile or pkcs12File")
+	}
+	if err := checkExclusive("token", cfg.Token, "tokenFile", cfg.TokenFile); err != nil {
+		return err
+	}
+	basic := cfg.Password != "" || cfg.PasswordFile != ""
+	jwt := cfg.Token != "" || cfg.TokenFile != ""
+	if !basic && !x509 && !jwt {
+		return configError("username", "no credentials - password, client certificate or token required")
+	}
+	for _, f := range []struct{ field, file string }{
+		{"passwordFile", cfg.PasswordFile},
+		{"clientCertFile", cfg.ClientCertFile},
+		{"clientKeyFile", cfg.ClientKeyFile},
+		{"pkcs12File", cfg.PKCS12File},
+		{"clientKeyPassphraseFile", cfg.ClientKeyPassphraseFile},
+		{"tokenFile", cfg.TokenFile},
+	} {
+		if err := checkFile(f.field, f.file); err != nil {
+			return err
+		}
+	}
+
+	// authentication methods
+	if len(cfg.AuthMethods) != 0 {
+		seen := make(map[string]bool, len(cfg.AuthMethods))
+		available := false
+		for _, mt := range cfg.AuthMethods {
+			if seen[mt] {
+				return configError("authMethods", "duplicate authentication method %s", mt)
+			}
+			seen[mt] = true
+			switch mt {
+			case AuthMethodSCRAMPBKDF2SHA256, AuthMethodSCRAMSHA256:
+				available = available || basic
+			case AuthMethodX509:
+				available = available || x509
+			case AuthMethodJWT:
+				available = available || jwt
+			case AuthMethodSessionCookie:
+			default:
+				return configError("authMethods", "unsupported authentication method %q", mt)
+			}
+		}
+		if !available {
+			return configError("authMethods", "no credentials configured for authentication methods %s", strings.Join(cfg.AuthMethods, ", "))
+		}
+	}
+
+	// tls
+	if cfg.TLS != nil {
+		for _, file := range cfg.TLS.RootCAFiles {
+			if err := checkFile("tls.rootCAFiles", file); err != nil {
+				return err
+			}
+		}
+	}
+
+	// connection settings
+	if cfg.Timeout < 0 {
+		return configError("timeout", "negative timeout %s", cfg.Timeout)
+	}
+	if cfg.PingInterval < 0 {
+		return configError("pingInterval", "negative ping interval %s", cfg.PingInterval)
+	}
+	if err := checkIntRange("bufferSize", cfg.BufferSize, 1, math.MaxInt); err != nil {
+		return err
+	}
+	if err := checkIntRange("fetchSize", cfg.FetchSize, minFetchSize, math.MaxInt); err != nil {
+		return err
+	}
+	if err := checkIntRange("bulkSize", cfg.BulkSize, minBulkSize, maxBulkSize); err != nil {
+		return err
+	}
+	if err := checkIntRange("lobChunkSize", cfg.LobChunkSize, minLobChunkSize, maxLobChunkSize); err != nil {
+		return err
+	}
+	if cfg.Dfv != 0 && !p.IsSupportedDfv(cfg.Dfv) {
+		return configError("dfv", "unsupported data format version %d", cfg.Dfv)
+	}
+	for k := range cfg.SessionVariables {
+		if k == "" {
+			return configError("sessionVariables", "empty session variable name")
+		}
+	}
+
+	// pool
+	if cfg.Pool.MaxOpenConns < 0 {
+		return configError("pool.maxOpenConns", "negative value %d", cfg.Pool.MaxOpenConns)
+	}
+	if cfg.Pool.MaxIdleConns < 0 {
+		return configError("pool.maxIdleConns", "negative value %d", cfg.Pool.MaxIdleConns)
+	}
+	if cfg.Pool.MaxOpenConns > 0 && cfg.Pool.MaxIdleConns > cfg.Pool.MaxOpenConns {
+		return configError("pool.maxIdleConns", "value %d exceeds maxOpenConns %d", cfg.Pool.MaxIdleConns, cfg.Pool.MaxOpenConns)
+	}
+	if cfg.Pool.ConnMaxLifetime < 0 {
+		return configError("pool.connMaxLifetime", "negative duration %s", cfg.Pool.ConnMaxLifetime)
+	}
+	if cfg.Pool.ConnMaxIdleTime < 0 {
+		return configError("pool.connMaxIdleTime", "negative duration %s", cfg.Pool.ConnMaxIdleTime)
+	}
+	return nil
+}
+
+func readSecretFile(file string) (string, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// passwordFileRefresher returns a password refresh function re-reading the password file.
+func passwordFileRefresher(file string) func() (string, bool) {
+	return func() (string, bool) {
+		password, err := readSecretFile(file)
+		if err != nil {
+			dlog.Printf("refresh password: %s", err)
+			return "", false
+		}
+		return password, true
+	}
+}
+
+/*
+NewConnectorFromConfig validates cfg and creates a connector from the configuration.
+
+Pool settings are not part of the connector and need to be applied to the database handle:
+
+	connector, err := driver.NewConnectorFromConfig(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db := sql.OpenDB(connector)
+	cfg.Pool.Apply(db)
+*/
+func NewConnectorFromConfig(cfg *Config) (*Connector, error) {
+	if cfg == nil {
+		return nil, errors.New("config is nil")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	dsn := &DSN{
+		host:             cfg.Host,
+		username:         cfg.Username,
+		password:         cfg.Password,
+		defaultSchema:    cfg.DefaultSchema,
+		timeout:          time.Duration(cfg.Timeout),
+		pingInterval:     time.Duration(cfg.PingInterval),
+		bufferSize:       cfg.BufferSize,
+		fetchSize:        cfg.FetchSize,
+		bulkSize:         cfg.BulkSize,
+		lobChunkSize:     cfg.LobChunkSize,
+		locale:           cfg.Locale,
+		applicationName:  cfg.ApplicationName,
+		dfv:              cfg.Dfv,
+		legacy:           cfg.Legacy,
+		sessionVariables: cfg.SessionVariables,
+	}
+	if cfg.Timeout == 0 {
+		dsn.timeout = defaultTimeout
+	}
+	if cfg.TLS != nil {
+		dsn.tls = &TLSPrms{ServerName: cfg.TLS.ServerName, InsecureSkipVerify: cfg.TLS.InsecureSkipVerify, RootCAFiles: cloneStringSlice(cfg.TLS.RootCAFiles)}
+	}
+	if cfg.TCPKeepAlive != nil {
+		tcpKeepAlive := time.Duration(*cfg.TCPKeepAlive)
+		dsn.tcpKeepAlive = &tcpKeepAlive
+	}
+
+	c, err := newDSNConnector(dsn)
+	if err != nil { // dsn without files: tls root certificates only
+		return nil, &ConfigError{field: "tls", err: err}
+	}
+
+	if cfg.PasswordFile != "" {
+		password, err := readSecretFile(cfg.PasswordFile)
+		if err != nil {
+			return nil, &ConfigError{field: "passwordFile", err: err}
+		}
+		c.authAttrs._password = password
+		c.authAttrs._refreshPassword = passwordFileRefresher(cfg.PasswordFile)
+	}
+
+	if cfg.Token != "" {
+		c.authAttrs._token = cfg.Token
+	}
+	if cfg.TokenFile != "" {
+		src := fileTokenSource(cfg.TokenFile)
+		if _, err := src.Token(); err != nil {
+			return nil, &ConfigError{field: "tokenFile", err: err}
+		}
+		c.authAttrs._tokenSource = newTokenCache(src)
+		c.authAttrs._tokenFile = cfg.TokenFile
+	}
+
+	passphrase := cfg.ClientKeyPassphrase
+	if cfg.ClientKeyPassphraseFile != "" {
+		if passphrase, err = readSecretFile(cfg.ClientKeyPassphraseFile); err != nil {
+			return nil, &ConfigError{field: "clientKeyPassphraseFile", err: err}
+		}
+	}
+	var loader ClientCertLoader
+	field := "clientCertFile"
+	switch {
+	case cfg.ClientCertFile != "":
+		loader = NewPEMClientCertLoader(cfg.ClientCertFile, cfg.ClientKeyFile, passphrase)
+		c.authAttrs._clientCertFile, c.authAttrs._clientKeyFile = cfg.ClientCertFile, cfg.ClientKeyFile
+	case cfg.PKCS12File != "":
+		loader = NewPKCS12ClientCertLoader(cfg.PKCS12File, passphrase)
+		field = "pkcs12File"
+	}
+	if loader != nil {
+		clientCert, clientKey, err := loader.Load()
+		if err != nil {
+			return nil, &ConfigError{field: field, err: err}
+		}
+		c.authAttrs._clientCert, c.authAttrs._clientKey = clientCert, clientKey
+		c.authAttrs._refreshClientCert = clientCertRefresher(loader)
+	}
+
+	if len(cfg.AuthMethods) != 0 {
+		if err := c.SetAuthMethods(cfg.AuthMethods...); err != nil {
+			return nil, &ConfigError{field: "authMethods", err: err}
+		}
+	}
+	return c, nil
+}
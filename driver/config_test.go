@@ -0,0 +1,217 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	file := filepath.Join(dir, name)
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func testParseConfig(t *testing.T) {
+	cfg, err := ParseConfig([]byte(`{
+		"host": "localhost:39013",
+		"username": "myuser",
+		"password": "mypassword",
+		"tls": {"serverName": "hostname", "insecureSkipVerify": true},
+		"timeout": "1m",
+		"pingInterval": 30,
+		"tcpKeepAlive": "-1s",
+		"fetchSize": 1000,
+		"legacy": true,
+		"sessionVariables": {"APPLICATIONUSER": "appuser"},
+		"pool": {"maxOpenConns": 10, "maxIdleConns": 5, "connMaxLifetime": "1h"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != Duration(time.Minute) || cfg.PingInterval != Duration(30*time.Second) || *cfg.TCPKeepAlive != Duration(-time.Second) {
+		t.Fatalf("unexpected durations timeout %s ping interval %s tcp keep-alive %s", cfg.Timeout, cfg.PingInterval, cfg.TCPKeepAlive)
+	}
+	if cfg.Pool.MaxOpenConns != 10 || cfg.Pool.MaxIdleConns != 5 || cfg.Pool.ConnMaxLifetime != Duration(time.Hour) {
+		t.Fatalf("unexpected pool config %#v", cfg.Pool)
+	}
+
+	c, err := NewConnectorFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Host() != "localhost:39013" || c.Timeout() != time.Minute || c.FetchSize() != 1000 || !c.Legacy() || c.TLSConfig() == nil {
+		t.Fatal("unexpected connector settings")
+	}
+	if sv := c.SessionVariables(); sv["APPLICATIONUSER"] != "appuser" {
+		t.Fatalf("unexpected session variables %v", sv)
+	}
+
+	for _, data := range []string{
+		`{"host": "localhost:39013", "unknown": 1}`,
+		`{"host": "localhost:39013", "timeout": "abc"}`,
+		`{"host": "localhost:39013", "timeout": 1.5}`,
+	} {
+		var cfgErr *ConfigError
+		if _, err := ParseConfig([]byte(data)); !errors.As(err, &cfgErr) {
+			t.Fatalf("config %s: expected config error - got %v", data, err)
+		}
+	}
+}
+
+func testConfigFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := writeTestFile(t, dir, "password", []byte("secret\n"))
+
+	t.Setenv(EnvHost, "localhost:39013")
+	t.Setenv(EnvUser, "myuser")
+	t.Setenv(EnvPasswordFile, passwordFile)
+	t.Setenv(EnvTimeout, "60")
+	t.Setenv(EnvTCPKeepAlive, "30s")
+	t.Setenv(EnvLegacy, "true")
+	t.Setenv(EnvAuthMethods, "SCRAMPBKDF2SHA256, SCRAMSHA256")
+	t.Setenv(EnvMaxOpenConns, "8")
+	t.Setenv(EnvSessionVariablePrefix+"APPLICATIONUSER", "appuser")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "localhost:39013" || cfg.Username != "myuser" || cfg.PasswordFile != passwordFile || cfg.Pool.MaxOpenConns != 8 {
+		t.Fatalf("unexpected config %#v", cfg)
+	}
+
+	c, err := NewConnectorFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Password() != "secret" || c.Timeout() != time.Minute || c.TCPKeepAlive() != 30*time.Second || !c.Legacy() {
+		t.Fatal("unexpected connector settings")
+	}
+	if mts := c.AuthMethods(); len(mts) != 2 || mts[0] != AuthMethodSCRAMPBKDF2SHA256 || mts[1] != AuthMethodSCRAMSHA256 {
+		t.Fatalf("unexpected authentication methods %v", mts)
+	}
+	if sv := c.SessionVariables(); sv["APPLICATIONUSER"] != "appuser" {
+		t.Fatalf("unexpected session variables %v", sv)
+	}
+
+	// password file rotation
+	writeTestFile(t, dir, "password", []byte("rotated\n"))
+	if password, ok := c.RefreshPassword()(); !ok || password != "rotated" {
+		t.Fatalf("refresh password %s - expected rotated", password)
+	}
+
+	// environment overrides config file
+	cfgFile := writeTestFile(t, dir, "config.json", []byte(`{"host": "otherhost:30015", "fetchSize": 500}`))
+	t.Setenv(EnvConfigFile, cfgFile)
+	if cfg, err = ConfigFromEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "localhost:39013" || cfg.FetchSize != 500 {
+		t.Fatalf("unexpected config host %s fetch size %d", cfg.Host, cfg.FetchSize)
+	}
+
+	t.Setenv(EnvFetchSize, "many")
+	var cfgErr *ConfigError
+	if _, err := ConfigFromEnv(); !errors.As(err, &cfgErr) || cfgErr.Field() != EnvFetchSize {
+		t.Fatalf("expected config error for %s - got %v", EnvFetchSize, err)
+	}
+}
+
+func testConfigX509(t *testing.T) {
+	dir := t.TempDir()
+	cert := newTestCert(t, "TESTUSER", nil)
+	cfg := &Config{
+		Host:                    "localhost:39013",
+		ClientCertFile:          writeTestFile(t, dir, "client.crt", cert.certPEM()),
+		ClientKeyFile:           writeTestFile(t, dir, "client.key", cert.keyPEM(t, "secret")),
+		ClientKeyPassphraseFile: writeTestFile(t, dir, "passphrase", []byte("secret\n")),
+	}
+	c, err := NewConnectorFromConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clientCert, _ := c.ClientCert(); !bytes.Equal(clientCert, cert.certPEM()) {
+		t.Fatal("unexpected client certificate")
+	}
+	if c.RefreshClientCert() == nil {
+		t.Fatal("client certificate refresh expected")
+	}
+
+	cfg.ClientKeyPassphraseFile = writeTestFile(t, dir, "wrong", []byte("wrong"))
+	if _, err := NewConnectorFromConfig(cfg); !errors.Is(err, ErrIncorrectPassphrase) {
+		t.Fatalf("expected error %v - got %v", ErrIncorrectPassphrase, err)
+	}
+}
+
+func testConfigValidate(t *testing.T) {
+	dir := t.TempDir()
+	file := writeTestFile(t, dir, "secret", []byte("secret"))
+
+	tests := []struct {
+		field string
+		cfg   Config
+	}{
+		{"host", Config{Username: "u", Password: "p"}},
+		{"host", Config{Host: "localhost", Username: "u", Password: "p"}},
+		{"host", Config{Host: "localhost:99999", Username: "u", Password: "p"}},
+		{"password", Config{Host: "localhost:39013", Username: "u", Password: "p", PasswordFile: file}},
+		{"username", Config{Host: "localhost:39013", Password: "p"}},
+		{"username", Config{Host: "localhost:39013"}},
+		{"passwordFile", Config{Host: "localhost:39013", Username: "u", PasswordFile: filepath.Join(dir, "missing")}},
+		{"passwordFile", Config{Host: "localhost:39013", Username: "u", PasswordFile: dir}},
+		{"clientCertFile", Config{Host: "localhost:39013", ClientCertFile: file}},
+		{"clientCertFile", Config{Host: "localhost:39013", ClientCertFile: file, ClientKeyFile: file, PKCS12File: file}},
+		{"clientKeyPassphrase", Config{Host: "localhost:39013", Token: "t", ClientKeyPassphrase: "p"}},
+		{"token", Config{Host: "localhost:39013", Token: "t", TokenFile: file}},
+		{"authMethods", Config{Host: "localhost:39013", Token: "t", AuthMethods: []string{AuthMethodX509}}},
+		{"authMethods", Config{Host: "localhost:39013", Token: "t", AuthMethods: []string{AuthMethodJWT, AuthMethodJWT}}},
+		{"authMethods", Config{Host: "localhost:39013", Token: "t", AuthMethods: []string{"unknown"}}},
+		{"tls.rootCAFiles", Config{Host: "localhost:39013", Token: "t", TLS: &TLSPrms{RootCAFiles: []string{filepath.Join(dir, "missing")}}}},
+		{"timeout", Config{Host: "localhost:39013", Token: "t", Timeout: -1}},
+		{"bulkSize", Config{Host: "localhost:39013", Token: "t", BulkSize: maxBulkSize + 1}},
+		{"lobChunkSize", Config{Host: "localhost:39013", Token: "t", LobChunkSize: -1}},
+		{"dfv", Config{Host: "localhost:39013", Token: "t", Dfv: 5}},
+		{"sessionVariables", Config{Host: "localhost:39013", Token: "t", SessionVariables: map[string]string{"": "v"}}},
+		{"pool.maxIdleConns", Config{Host: "localhost:39013", Token: "t", Pool: PoolConfig{MaxOpenConns: 1, MaxIdleConns: 2}}},
+		{"pool.connMaxLifetime", Config{Host: "localhost:39013", Token: "t", Pool: PoolConfig{ConnMaxLifetime: -1}}},
+	}
+
+	for _, test := range tests {
+		err := test.cfg.Validate()
+		var cfgErr *ConfigError
+		if !errors.As(err, &cfgErr) {
+			t.Fatalf("config %#v: expected config error - got %v", test.cfg, err)
+		}
+		if cfgErr.Field() != test.field {
+			t.Fatalf("config %#v: error field %s - expected %s (%s)", test.cfg, cfgErr.Field(), test.field, err)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"parseConfig", testParseConfig},
+		{"configFromEnv", testConfigFromEnv},
+		{"configX509", testConfigX509},
+		{"configValidate", testConfigValidate},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
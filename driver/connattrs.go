@@ -44,6 +44,7 @@ const (
 	minFetchSize    = 1       // Minimal fetchSize value.
 	minLobChunkSize = 128     // Minimal lobChunkSize
 	maxLobChunkSize = 1 << 14 // Maximal lobChunkSize (TODO check)
+	maxPrefetch     = 16      // Maximal number of prefetched resultset chunks.
 )
 
 // connAttrs is holding connection relevant attributes.
@@ -56,6 +57,7 @@ type connAttrs struct {
 	_bulkSize      int
 	_tcpKeepAlive  time.Duration // see net.Dialer
 	_tlsConfig     *tls.Config
+	_tlsPrms       *TLSPrms // TLS parameters in case the TLS configuration is created by setTLS
 	_defaultSchema string
 	_dialer        dial.Dialer
 
@@ -63,6 +65,8 @@ type connAttrs struct {
 	_sessionVariables map[string]string
 	_locale           string
 	_fetchSize        int
+	_fetchTargetSize  int
+	_prefetch         int
 	_lobChunkSize     int
 	_dfv              int
 	_legacy           bool
@@ -151,6 +155,7 @@ func (a *connAttrs) setTLSConfig(tlsConfig *tls.Config) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a._tlsConfig = tlsConfig.Clone()
+	a._tlsPrms = nil
 }
 func (a *connAttrs) _setTLS(serverName string, insecureSkipVerify bool, rootCAFiles []string) error {
 	a._tlsConfig = &tls.Config{
@@ -173,6 +178,7 @@ func (a *connAttrs) _setTLS(serverName string, insecureSkipVerify bool, rootCAFi
 	if certPool != nil {
 		a._tlsConfig.RootCAs = certPool
 	}
+	a._tlsPrms = &TLSPrms{ServerName: serverName, InsecureSkipVerify: insecureSkipVerify, RootCAFiles: append([]string(nil), rootCAFiles...)}
 	return nil
 }
 func (a *connAttrs) setTLS(serverName string, insecureSkipVerify bool, rootCAFiles []string) error {
@@ -225,6 +231,31 @@ func (a *connAttrs) setFetchSize(fetchSize int) {
 	defer a.mu.Unlock()
 	a._setFetchSize(fetchSize)
 }
+func (a *connAttrs) fetchTargetSize() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a._fetchTargetSize
+}
+func (a *connAttrs) setFetchTargetSize(fetchTargetSize int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if fetchTargetSize < 0 {
+		fetchTargetSize = 0
+	}
+	a._fetchTargetSize = fetchTargetSize
+}
+func (a *connAttrs) prefetch() int { a.mu.RLock(); defer a.mu.RUnlock(); return a._prefetch }
+func (a *connAttrs) setPrefetch(prefetch int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch {
+	case prefetch < 0:
+		prefetch = 0
+	case prefetch > maxPrefetch:
+		prefetch = maxPrefetch
+	}
+	a._prefetch = prefetch
+}
 func (a *connAttrs) lobChunkSize() int { a.mu.RLock(); defer a.mu.RUnlock(); return a._lobChunkSize }
 func (a *connAttrs) setLobChunkSize(lobChunkSize int) {
 	a.mu.Lock()
@@ -65,6 +65,10 @@ var ErrNestedTransaction = errors.New("nested transactions are not supported")
 // Example: execute sql statement before rows of previous select statement are closed.
 var ErrNestedQuery = errors.New("nested sql queries are not supported")
 
+// ErrExplainInTransaction is the error raised if Explain is called within a transaction, as the removal of
+// the explain plan entries commits the transaction.
+var ErrExplainInTransaction = errors.New("explain plan is not supported within a transaction")
+
 // queries
 const (
 	dummyQuery        = "select 1 from dummy"
@@ -241,6 +245,9 @@ type Conn interface {
 	HDBVersion() *Version
 	DatabaseName() string
 	DBConnectInfo(ctx context.Context, databaseName string) (*DBConnectInfo, error)
+	DescribeStmt(ctx context.Context, query string) (*StmtDescr, error)
+	Explain(ctx context.Context, query string) (*PlanOperator, error)
+	ServerTime() time.Duration
 }
 
 // Conn is the implementation of the database/sql/driver Conn interface.
@@ -275,19 +282,22 @@ type conn struct {
 	// after go.17 support: delete serverOptions and define it again direcly here
 	serverOptions connectOptions
 	hdbVersion    *Version
+	logonWarnings *p.HdbErrors // warnings returned by the logon (e.g. password expiry)
 
 	pr *p.Reader
 	pw *p.Writer
 
-	bulkSize     int
-	lobChunkSize int
-	fetchSize    int
-	legacy       bool
-	cesu8Decoder func() transform.Transformer
-	cesu8Encoder func() transform.Transformer
+	bulkSize        int
+	lobChunkSize    int
+	fetchSize       int
+	fetchTargetSize int
+	prefetch        int
+	legacy          bool
+	cesu8Decoder    func() transform.Transformer
+	cesu8Encoder    func() transform.Transformer
 }
 
-func newConn(ctx context.Context, metrics *metrics, attrs *connAttrs, auth *p.Auth) (driver.Conn, error) {
+func newConn(ctx context.Context, metrics *metrics, attrs *connAttrs, auth *p.Auth, hooks *logonHooks) (_ driver.Conn, err error) {
 	// lock attributes
 	attrs.mu.RLock()
 	defer attrs.mu.RUnlock()
@@ -303,21 +313,28 @@ func newConn(ctx context.Context, metrics *metrics, attrs *connAttrs, auth *p.Au
 	}
 
 	dbConn := &dbConn{metrics: metrics, conn: netConn, timeout: attrs._timeout}
+	defer func() {
+		if err != nil {
+			dbConn.close() // ignore error
+		}
+	}()
 	// buffer connection
 	rw := bufio.NewReadWriter(bufio.NewReaderSize(dbConn, attrs._bufferSize), bufio.NewWriterSize(dbConn, attrs._bufferSize))
 
 	c := &conn{
-		metrics:      metrics,
-		dbConn:       dbConn,
-		scanner:      &scanner.Scanner{},
-		closed:       make(chan struct{}),
-		trace:        sqltrace.On(),
-		bulkSize:     attrs._bulkSize,
-		lobChunkSize: attrs._lobChunkSize,
-		fetchSize:    attrs._fetchSize,
-		legacy:       attrs._legacy,
-		cesu8Decoder: attrs._cesu8Decoder,
-		cesu8Encoder: attrs._cesu8Encoder,
+		metrics:         metrics,
+		dbConn:          dbConn,
+		scanner:         &scanner.Scanner{},
+		closed:          make(chan struct{}),
+		trace:           sqltrace.On(),
+		bulkSize:        attrs._bulkSize,
+		lobChunkSize:    attrs._lobChunkSize,
+		fetchSize:       attrs._fetchSize,
+		fetchTargetSize: attrs._fetchTargetSize,
+		prefetch:        attrs._prefetch,
+		legacy:          attrs._legacy,
+		cesu8Decoder:    attrs._cesu8Decoder,
+		cesu8Encoder:    attrs._cesu8Encoder,
 	}
 	//c.Attrs = connAttrs // TODO rework
 
@@ -333,16 +350,29 @@ func newConn(ctx context.Context, metrics *metrics, attrs *connAttrs, auth *p.Au
 
 	c.sessionID = defaultSessionID
 
-	if c.sessionID, c.serverOptions, err = c._authenticate(auth, attrs._applicationName, attrs._dfv, attrs._locale); err != nil {
-		return nil, err
+	var logonErr error
+	if c.sessionID, c.serverOptions, logonErr = c._authenticate(auth, attrs._applicationName, attrs._dfv, attrs._locale); logonErr != nil && !isPasswordChangeError(logonErr) {
+		return nil, logonErr
 	}
 
 	if c.sessionID <= 0 {
+		if logonErr != nil {
+			return nil, logonErr
+		}
 		return nil, fmt.Errorf("invalid session id %d", c.sessionID)
 	}
+	defer func() {
+		if err != nil { // logon succeeded: disconnect session
+			c._disconnect() // ignore error
+		}
+	}()
 
 	c.hdbVersion = parseVersion(c.serverOptions[p.CoFullVersionString].(string))
 
+	if err := c.handleLogon(hooks, logonErr); err != nil {
+		return nil, err
+	}
+
 	if attrs._defaultSchema != "" {
 		if _, err := c.ExecContext(ctx, strings.Join([]string{setDefaultSchema, Identifier(attrs._defaultSchema).String()}, " "), nil); err != nil {
 			return nil, err
@@ -621,6 +651,7 @@ func (c *conn) QueryContext(ctx context.Context, query string, nvargs []driver.N
 		c.dbConn.cancel()
 		return nil, ctx.Err()
 	case <-done:
+		applyFetchSizeContext(ctx, rows)
 		if onCloser, ok := rows.(onCloser); ok {
 			onCloser.setOnClose(c.unlock)
 			hasRowsCloser = true
@@ -689,6 +720,10 @@ func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
 // HDBVersion implements the Conn interface.
 func (c *conn) HDBVersion() *Version { return c.hdbVersion }
 
+// ServerTime implements the Conn interface.
+// ServerTime returns the accumulated server execution time of all statements executed by the connection.
+func (c *conn) ServerTime() time.Duration { return c.pr.ServerTime() }
+
 // DatabaseName implements the Conn interface.
 func (c *conn) DatabaseName() string { return c._databaseName() }
 
@@ -719,6 +754,71 @@ func (c *conn) DBConnectInfo(ctx context.Context, databaseName string) (ci *DBCo
 	}
 }
 
+// DescribeStmt prepares the query and returns the descriptions of the statement parameters and
+// result columns. The statement is not executed.
+func (c *conn) DescribeStmt(ctx context.Context, query string) (sd *StmtDescr, err error) {
+	if err := c.tryLock(0); err != nil {
+		return nil, err
+	}
+	defer c.unlock()
+
+	if c.isBad() {
+		return nil, driver.ErrBadConn
+	}
+
+	done := make(chan struct{})
+	go func() {
+		sd, err = c._describeStmt(query)
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		c.dbConn.cancel()
+		return nil, ctx.Err()
+	case <-done:
+		c.lastError = err
+		return sd, err
+	}
+}
+
+// Explain runs EXPLAIN PLAN for the query and returns the root of the plan operator tree.
+// The query is not executed and the plan entries are removed from the explain plan table.
+// As removing the plan entries commits, Explain returns ErrExplainInTransaction if called within a transaction.
+func (c *conn) Explain(ctx context.Context, query string) (root *PlanOperator, err error) {
+	if err := c.tryLock(0); err != nil {
+		return nil, err
+	}
+	defer c.unlock()
+
+	if c.isBad() {
+		return nil, driver.ErrBadConn
+	}
+
+	if c.inTx {
+		return nil, ErrExplainInTransaction
+	}
+
+	if c.trace {
+		defer traceSQL(time.Now(), query, nil)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		root, err = c._explain(query)
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		c.dbConn.cancel()
+		return nil, ctx.Err()
+	case <-done:
+		c.lastError = err
+		return root, err
+	}
+}
+
 func traceSQL(start time.Time, query string, nvargs []driver.NamedValue) {
 	ms := time.Since(start).Milliseconds()
 	switch {
@@ -908,6 +1008,7 @@ func (s *stmt) QueryContext(ctx context.Context, nvargs []driver.NamedValue) (ro
 		c.dbConn.cancel()
 		return nil, ctx.Err()
 	case <-done:
+		applyFetchSizeContext(ctx, rows)
 		if onCloser, ok := rows.(onCloser); ok {
 			onCloser.setOnClose(c.unlock)
 			hasRowsCloser = true
@@ -963,7 +1064,7 @@ func (s *stmt) exec(ctx context.Context, nvargs []driver.NamedValue) (r driver.R
 
 	done := make(chan struct{})
 	go func() {
-		r, err = c._execBulk(s.pr, nvargs, !c.inTx) //TODO
+		r, err = c._execBulk(ctx, s.pr, nvargs, !c.inTx) //TODO
 		close(done)
 	}()
 
@@ -1246,7 +1347,7 @@ func (s *callStmt) QueryContext(ctx context.Context, nvargs []driver.NamedValue)
 
 	done := make(chan struct{})
 	go func() {
-		rows, err = c._queryCall(s.pr, nvargs)
+		rows, err = c._queryCall(ctx, s.pr, nvargs)
 		close(done)
 	}()
 
@@ -1286,7 +1387,7 @@ func (s *callStmt) ExecContext(ctx context.Context, nvargs []driver.NamedValue)
 
 	done := make(chan struct{})
 	go func() {
-		r, err = c._execCall(s.pr, nvargs)
+		r, err = c._execCall(ctx, s.pr, nvargs)
 		close(done)
 	}()
 
@@ -1389,7 +1490,12 @@ func (c *conn) _authenticate(auth *p.Auth, applicationName string, dfv int, loca
 		return co
 	}()
 
-	if err := c.pw.Write(c.sessionID, p.MtConnect, false, finalRequest, p.ClientID(clientID), co); err != nil {
+	// session cookies are bound to the client id of the session they were issued for
+	id := clientID
+	if auth.ClientID() != "" {
+		id = auth.ClientID()
+	}
+	if err := c.pw.Write(c.sessionID, p.MtConnect, false, finalRequest, p.ClientID(id), co); err != nil {
 		return 0, nil, err
 	}
 
@@ -1397,8 +1503,11 @@ func (c *conn) _authenticate(auth *p.Auth, applicationName string, dfv int, loca
 	if err != nil {
 		return 0, nil, err
 	}
+	logonErrors := &p.HdbErrors{}
 	if err := c.pr.IterateParts(func(ph *p.PartHeader) {
 		switch ph.PartKind {
+		case p.PkError:
+			c.pr.Read(logonErrors)
 		case p.PkAuthentication:
 			c.pr.Read(finalReply)
 		case p.PkConnectOptions:
@@ -1408,8 +1517,15 @@ func (c *conn) _authenticate(auth *p.Auth, applicationName string, dfv int, loca
 			c.pr.SetDfv(int(co[p.CoDataFormatVersion2].(int32)))
 		}
 	}); err != nil {
+		// the session is established but restricted to the password change
+		if isPasswordChangeError(err) {
+			return c.pr.SessionID(), co, err
+		}
 		return 0, nil, err
 	}
+	if logonErrors.NumError() != 0 {
+		c.logonWarnings = logonErrors
+	}
 	return c.pr.SessionID(), co, nil
 }
 
@@ -1421,7 +1537,7 @@ func (c *conn) _queryDirect(query string, commit bool) (driver.Rows, error) {
 		return nil, err
 	}
 
-	qr := &queryResult{conn: c}
+	qr := &queryResult{conn: c, fetchSizer: c.fetchSizer()}
 	meta := &p.ResultMetadata{}
 	resSet := &p.Resultset{}
 
@@ -1438,6 +1554,7 @@ func (c *conn) _queryDirect(query string, commit bool) (driver.Rows, error) {
 			qr.fieldValues = resSet.FieldValues
 			qr.decodeErrors = resSet.DecodeErrors
 			qr.attributes = ph.PartAttributes
+			qr.numByte = ph.BufferLength()
 		}
 	}); err != nil {
 		return nil, err
@@ -1517,6 +1634,15 @@ func (c *conn) _fetchFirstLobChunk(nvargs []driver.NamedValue) (bool, error) {
 	return hasNext, nil
 }
 
+// lobChunksWritten reports the lob chunks written to the database.
+func lobChunksWritten(nvargs []driver.NamedValue) {
+	for _, arg := range nvargs {
+		if lobInDescr, ok := arg.Value.(*p.LobInDescr); ok {
+			lobInDescr.ChunkWritten()
+		}
+	}
+}
+
 /*
 Exec executes a sql statement.
 
@@ -1537,7 +1663,7 @@ Bulk insert containing LOBs:
   - Package invariant:
     .for all packages except the last one, the last row contains 'incomplete' LOB data ('piecewise' writing)
 */
-func (c *conn) _execBulk(pr *prepareResult, nvargs []driver.NamedValue, commit bool) (driver.Result, error) {
+func (c *conn) _execBulk(ctx context.Context, pr *prepareResult, nvargs []driver.NamedValue, commit bool) (driver.Result, error) {
 	defer c.addTimeValue(time.Now(), timeExec)
 
 	hasLob := func() bool {
@@ -1551,7 +1677,7 @@ func (c *conn) _execBulk(pr *prepareResult, nvargs []driver.NamedValue, commit b
 
 	// no split needed: no LOB or only one row
 	if !hasLob || len(pr.parameterFields) == len(nvargs) {
-		return c._exec(pr, nvargs, hasLob, commit)
+		return c._exec(ctx, pr, nvargs, hasLob, commit)
 	}
 
 	// args need to be potentially splitted (piecewise LOB handling)
@@ -1562,6 +1688,10 @@ func (c *conn) _execBulk(pr *prepareResult, nvargs []driver.NamedValue, commit b
 
 	for i := 0; i < numRows; i++ { // row-by-row
 
+		if err := ctx.Err(); err != nil {
+			return driver.RowsAffected(totRowsAffected), err
+		}
+
 		from := i * numColumns
 		to := from + numColumns
 
@@ -1575,23 +1705,20 @@ func (c *conn) _execBulk(pr *prepareResult, nvargs []driver.NamedValue, commit b
 			or we did reach the last row
 		*/
 		if hasNext || i == (numRows-1) {
-			r, err := c._exec(pr, nvargs[lastFrom:to], true, commit)
-			//if err != nil {
-			//	return driver.RowsAffected(totRowsAffected), err
-			//}
-			if rowsAffected, err := r.RowsAffected(); err != nil {
-				totRowsAffected += rowsAffected
-			}
+			r, err := c._exec(ctx, pr, nvargs[lastFrom:to], true, commit)
 			if err != nil {
 				return driver.RowsAffected(totRowsAffected), err
 			}
+			if rowsAffected, err := r.RowsAffected(); err == nil {
+				totRowsAffected += rowsAffected
+			}
 			lastFrom = to
 		}
 	}
 	return driver.RowsAffected(totRowsAffected), nil
 }
 
-func (c *conn) _exec(pr *prepareResult, nvargs []driver.NamedValue, hasLob, commit bool) (driver.Result, error) {
+func (c *conn) _exec(ctx context.Context, pr *prepareResult, nvargs []driver.NamedValue, hasLob, commit bool) (driver.Result, error) {
 	inputParameters, err := p.NewInputParameters(pr.parameterFields, nvargs, hasLob)
 	if err != nil {
 		return nil, err
@@ -1619,13 +1746,17 @@ func (c *conn) _exec(pr *prepareResult, nvargs []driver.NamedValue, hasLob, comm
 	}
 	fc := c.pr.FunctionCode()
 
+	if hasLob {
+		lobChunksWritten(nvargs)
+	}
+
 	if len(ids) != 0 {
 		/*
 			writeLobParameters:
 			- chunkReaders
 			- nil (no callResult, exec does not have output parameters)
 		*/
-		if err := c.encodeLobs(nil, ids, pr.parameterFields, nvargs); err != nil {
+		if err := c.encodeLobs(ctx, nil, ids, pr.parameterFields, nvargs); err != nil {
 			return nil, err
 		}
 	}
@@ -1636,7 +1767,7 @@ func (c *conn) _exec(pr *prepareResult, nvargs []driver.NamedValue, hasLob, comm
 	return driver.RowsAffected(rowsAffected), nil
 }
 
-func (c *conn) _queryCall(pr *prepareResult, nvargs []driver.NamedValue) (driver.Rows, error) {
+func (c *conn) _queryCall(ctx context.Context, pr *prepareResult, nvargs []driver.NamedValue) (driver.Rows, error) {
 	defer c.addTimeValue(time.Now(), timeCall)
 
 	/*
@@ -1682,13 +1813,17 @@ func (c *conn) _queryCall(pr *prepareResult, nvargs []driver.NamedValue) (driver
 		return nil, err
 	}
 
+	if hasInLob {
+		lobChunksWritten(nvargs)
+	}
+
 	if len(ids) != 0 {
 		/*
 			writeLobParameters:
 			- chunkReaders
 			- cr (callResult output parameters are set after all lob input parameters are written)
 		*/
-		if err := c.encodeLobs(cr, ids, inPrmFields, nvargs); err != nil {
+		if err := c.encodeLobs(ctx, cr, ids, inPrmFields, nvargs); err != nil {
 			return nil, err
 		}
 	}
@@ -1706,7 +1841,7 @@ func (c *conn) _queryCall(pr *prepareResult, nvargs []driver.NamedValue) (driver
 	return cr, nil
 }
 
-func (c *conn) _execCall(pr *prepareResult, nvargs []driver.NamedValue) (driver.Result, error) {
+func (c *conn) _execCall(ctx context.Context, pr *prepareResult, nvargs []driver.NamedValue) (driver.Result, error) {
 	defer c.addTimeValue(time.Now(), timeCall)
 
 	/*
@@ -1763,13 +1898,17 @@ func (c *conn) _execCall(pr *prepareResult, nvargs []driver.NamedValue) (driver.
 		return nil, err
 	}
 
+	if hasInLob {
+		lobChunksWritten(inArgs)
+	}
+
 	if len(ids) != 0 {
 		/*
 			writeLobParameters:
 			- chunkReaders
 			- cr (callResult output parameters are set after all lob input parameters are written)
 		*/
-		if err := c.encodeLobs(cr, ids, inPrmFields, inArgs); err != nil {
+		if err := c.encodeLobs(ctx, cr, ids, inPrmFields, inArgs); err != nil {
 			return nil, err
 		}
 	}
@@ -1807,7 +1946,7 @@ func (c *conn) _readCall(outputFields []*p.ParameterField) (*callResult, []p.Loc
 				- resultset might not be provided for all tables
 				- so, 'additional' query result is detected by new metadata part
 			*/
-			qr = &queryResult{conn: c}
+			qr = &queryResult{conn: c, fetchSizer: c.fetchSizer()}
 			cr.qrs = append(cr.qrs, qr)
 			c.pr.Read(meta)
 			qr.fields = meta.ResultFields
@@ -1817,6 +1956,7 @@ func (c *conn) _readCall(outputFields []*p.ParameterField) (*callResult, []p.Loc
 			qr.fieldValues = resSet.FieldValues
 			qr.decodeErrors = resSet.DecodeErrors
 			qr.attributes = ph.PartAttributes
+			qr.numByte = ph.BufferLength()
 		case p.PkResultsetID:
 			c.pr.Read((*p.ResultsetID)(&qr.rsID))
 		case p.PkWriteLobReply:
@@ -1856,7 +1996,7 @@ func (c *conn) _query(pr *prepareResult, nvargs []driver.NamedValue, commit bool
 		return nil, err
 	}
 
-	qr := &queryResult{conn: c, fields: pr.resultFields}
+	qr := &queryResult{conn: c, fields: pr.resultFields, fetchSizer: c.fetchSizer()}
 	resSet := &p.Resultset{}
 
 	if err := c.pr.IterateParts(func(ph *p.PartHeader) {
@@ -1869,6 +2009,7 @@ func (c *conn) _query(pr *prepareResult, nvargs []driver.NamedValue, commit bool
 			qr.fieldValues = resSet.FieldValues
 			qr.decodeErrors = resSet.DecodeErrors
 			qr.attributes = ph.PartAttributes
+			qr.numByte = ph.BufferLength()
 		}
 	}); err != nil {
 		return nil, err
@@ -1881,24 +2022,79 @@ func (c *conn) _query(pr *prepareResult, nvargs []driver.NamedValue, commit bool
 
 func (c *conn) _fetchNext(qr *queryResult) error {
 	//TODO: query?
+	chunk := &resultsetChunk{fieldValues: qr.fieldValues} // reuse field values
+	if err := c._fetchChunk(qr.rsID, qr.fields, qr.fetchSizer.fetchSize, chunk); err != nil {
+		return err
+	}
+	qr.fieldValues = chunk.fieldValues
+	qr.decodeErrors = chunk.decodeErrors
+	qr.attributes = chunk.attributes
+	qr.numByte = chunk.numByte
+	return nil
+}
+
+func (c *conn) _fetchChunk(rsID uint64, fields []*p.ResultField, fetchSize int, chunk *resultsetChunk) error {
 	defer c.addTimeValue(time.Now(), timeFetch)
 
-	if err := c.pw.Write(c.sessionID, p.MtFetchNext, false, p.ResultsetID(qr.rsID), p.Fetchsize(c.fetchSize)); err != nil {
+	if err := c.pw.Write(c.sessionID, p.MtFetchNext, false, p.ResultsetID(rsID), p.Fetchsize(fetchSize)); err != nil {
 		return err
 	}
 
-	resSet := &p.Resultset{ResultFields: qr.fields, FieldValues: qr.fieldValues} // reuse field values
+	resSet := &p.Resultset{ResultFields: fields, FieldValues: chunk.fieldValues}
 
 	return c.pr.IterateParts(func(ph *p.PartHeader) {
 		if ph.PartKind == p.PkResultset {
 			c.pr.Read(resSet)
-			qr.fieldValues = resSet.FieldValues
-			qr.decodeErrors = resSet.DecodeErrors
-			qr.attributes = ph.PartAttributes
+			chunk.fieldValues = resSet.FieldValues
+			chunk.decodeErrors = resSet.DecodeErrors
+			chunk.attributes = ph.PartAttributes
+			chunk.numByte = ph.BufferLength()
 		}
 	})
 }
 
+func (c *conn) _describeStmt(query string) (*StmtDescr, error) {
+	qd, err := newQueryDescr(query, c.scanner)
+	if err != nil {
+		return nil, err
+	}
+	pr, err := c._prepare(qd.query)
+	if err != nil {
+		return nil, err
+	}
+	if err := c._dropStatementID(pr.stmtID); err != nil {
+		return nil, err
+	}
+	return newStmtDescr(pr), nil
+}
+
+func (c *conn) _explain(query string) (root *PlanOperator, err error) {
+	stmtName := newExplainStmtName(c.sessionID)
+
+	if _, err := c._execDirect(fmt.Sprintf(explainPlanStmt, stmtName, query), false); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// remove plan entries in any case
+		if _, delErr := c._execDirect(fmt.Sprintf(explainPlanDelete, stmtName), true); err == nil {
+			err = delErr
+		}
+	}()
+
+	rows, err := c._queryDirect(fmt.Sprintf(explainPlanSelect, stmtName), false)
+	if err != nil {
+		return nil, err
+	}
+	root, err = explainPlan(rows)
+	if closeErr := rows.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
 func (c *conn) _dropStatementID(id uint64) error {
 	if err := c.pw.Write(c.sessionID, p.MtDropStatementID, false, p.StatementID(id)); err != nil {
 		return err
@@ -1965,24 +2161,9 @@ func (c *conn) decodeLobs(descr *p.LobOutDescr, wr io.Writer) error {
 
 	if descr.IsCharBased {
 		wrcl := transform.NewWriter(wr, c.cesu8Decoder()) // CESU8 transformer
-		err = c._decodeLobs(descr, wrcl, func(b []byte) (int64, error) {
-			// Caution: hdb counts 4 byte utf-8 encodings (cesu-8 6 bytes) as 2 (3 byte) chars
-			numChars := int64(0)
-			for len(b) > 0 {
-				if !cesu8.FullRune(b) { //
-					return 0, fmt.Errorf("lob chunk consists of incomplete CESU-8 runes")
-				}
-				_, size := cesu8.DecodeRune(b)
-				b = b[size:]
-				numChars++
-				if size == cesu8.CESUMax {
-					numChars++
-				}
-			}
-			return numChars, nil
-		})
+		err = c._decodeLobs(descr, wrcl, countLobChars)
 	} else {
-		err = c._decodeLobs(descr, wr, func(b []byte) (int64, error) { return int64(len(b)), nil })
+		err = c._decodeLobs(descr, wr, countLobBytes)
 	}
 
 	if pw, ok := wr.(*io.PipeWriter); ok { // if the writer is a pipe-end -> close at the end
@@ -1995,6 +2176,98 @@ func (c *conn) decodeLobs(descr *p.LobOutDescr, wr io.Writer) error {
 	return err
 }
 
+func countLobBytes(b []byte) (int64, error) { return int64(len(b)), nil }
+
+func countLobChars(b []byte) (int64, error) {
+	// Caution: hdb counts 4 byte utf-8 encodings (cesu-8 6 bytes) as 2 (3 byte) chars
+	numChars := int64(0)
+	for len(b) > 0 {
+		if !cesu8.FullRune(b) { //
+			return 0, fmt.Errorf("lob chunk consists of incomplete CESU-8 runes")
+		}
+		_, size := cesu8.DecodeRune(b)
+		b = b[size:]
+		numChars++
+		if size == cesu8.CESUMax {
+			numChars++
+		}
+	}
+	return numChars, nil
+}
+
+// readLob reads a chunk of a lob starting at offset ofs (random access).
+// ofs and size are measured in bytes for binary lobs and in characters for character based lobs.
+// The content of character based lobs is returned utf-8 encoded.
+// It returns the chunk content and the number of bytes or characters read.
+func (c *conn) readLob(descr *p.LobOutDescr, ofs int64, size int32) ([]byte, int64, error) {
+	defer c.addTimeValue(time.Now(), timeFetchLob)
+
+	if c.isBad() {
+		return nil, 0, driver.ErrBadConn
+	}
+
+	if ofs < 0 || size < 0 {
+		return nil, 0, fmt.Errorf("lob: invalid offset %d or size %d", ofs, size)
+	}
+	if remaining := descr.NumChar - ofs; int64(size) > remaining {
+		size = int32(remaining)
+	}
+	if size <= 0 {
+		return nil, 0, nil
+	}
+	if size > int32(c.lobChunkSize) {
+		size = int32(c.lobChunkSize)
+	}
+
+	var b []byte
+	countChars := countLobBytes
+	if descr.IsCharBased {
+		countChars = countLobChars
+	}
+
+	// content of first chunk is part of the descriptor
+	switch {
+	case ofs == 0 && descr.IsCharBased && len(descr.B) != 0:
+		b = descr.B
+	case !descr.IsCharBased && ofs < int64(len(descr.B)):
+		end := ofs + int64(size)
+		if end > int64(len(descr.B)) {
+			end = int64(len(descr.B))
+		}
+		b = descr.B[ofs:end]
+	default:
+		lobRequest := &p.ReadLobRequest{ID: descr.ID, Ofs: ofs, ChunkSize: size}
+		lobReply := &p.ReadLobReply{}
+
+		if err := c.pw.Write(c.sessionID, p.MtWriteLob, false, lobRequest); err != nil {
+			return nil, 0, err
+		}
+		if err := c.pr.IterateParts(func(ph *p.PartHeader) {
+			if ph.PartKind == p.PkReadLobReply {
+				c.pr.Read(lobReply)
+			}
+		}); err != nil {
+			return nil, 0, err
+		}
+		if lobReply.ID != lobRequest.ID {
+			return nil, 0, fmt.Errorf("internal error: invalid lob locator %d - expected %d", lobReply.ID, lobRequest.ID)
+		}
+		b = lobReply.B
+	}
+
+	n, err := countChars(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !descr.IsCharBased {
+		return append([]byte(nil), b...), n, nil
+	}
+	if b, _, err = transform.Bytes(c.cesu8Decoder(), b); err != nil { // CESU8 transformer
+		return nil, 0, err
+	}
+	return b, n, nil
+}
+
 func (c *conn) _decodeLobs(descr *p.LobOutDescr, wr io.Writer, countChars func(b []byte) (int64, error)) error {
 	lobChunkSize := int64(c.lobChunkSize)
 
@@ -2056,8 +2329,26 @@ func (c *conn) _decodeLobs(descr *p.LobOutDescr, wr io.Writer, countChars func(b
 	return nil
 }
 
-// encodeLobs encodes (write to db) input lob parameters.
-func (c *conn) encodeLobs(cr *callResult, ids []p.LocatorID, inPrmFields []*p.ParameterField, nvargs []driver.NamedValue) error {
+// maxWriteLobRequestSize is the maximum size of lob data written in one write lob request.
+const maxWriteLobRequestSize = 1 << 20
+
+// lobRequestChunkSize returns the lob chunk size used for writing numDescr lobs in one write lob request.
+func (c *conn) lobRequestChunkSize(numDescr int) int {
+	if numDescr*c.lobChunkSize <= maxWriteLobRequestSize {
+		return c.lobChunkSize
+	}
+	if chunkSize := maxWriteLobRequestSize / numDescr; chunkSize > minLobChunkSize {
+		return chunkSize
+	}
+	return minLobChunkSize
+}
+
+/*
+encodeLobs encodes (write to db) input lob parameters.
+  - The lob content is streamed to the database in chunks, so that only one chunk per lob needs to be buffered.
+  - The context is checked before each chunk is read, so that a cancelled write stops reading the lob sources.
+*/
+func (c *conn) encodeLobs(ctx context.Context, cr *callResult, ids []p.LocatorID, inPrmFields []*p.ParameterField, nvargs []driver.NamedValue) error {
 
 	descrs := make([]*p.WriteLobDescr, 0, len(ids))
 
@@ -2092,9 +2383,13 @@ func (c *conn) encodeLobs(cr *callResult, ids []p.LocatorID, inPrmFields []*p.Pa
 			}
 		}
 
-		// TODO check total size limit
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		chunkSize := c.lobRequestChunkSize(len(descrs))
 		for _, descr := range descrs {
-			if err := descr.FetchNext(c.lobChunkSize); err != nil {
+			if err := descr.FetchNext(chunkSize); err != nil {
 				return err
 			}
 		}
@@ -2123,6 +2418,10 @@ func (c *conn) encodeLobs(cr *callResult, ids []p.LocatorID, inPrmFields []*p.Pa
 			return err
 		}
 
+		for _, descr := range descrs {
+			descr.LobInDescr.ChunkWritten()
+		}
+
 		// remove done descr
 		j := 0
 		for _, descr := range descrs {
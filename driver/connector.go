@@ -9,6 +9,7 @@ import (
 	"crypto/tls"
 	"database/sql/driver"
 	"errors"
+	"fmt"
 	"os"
 	"time"
 
@@ -71,7 +72,9 @@ func NewX509AuthConnectorByFiles(host, clientCertFile, clientKeyFile string) (*C
 	if err != nil {
 		return nil, err
 	}
-	return NewX509AuthConnector(host, clientCert, clientKey), nil
+	c := NewX509AuthConnector(host, clientCert, clientKey)
+	c.authAttrs._clientCertFile, c.authAttrs._clientKeyFile = clientCertFile, clientKeyFile
+	return c, nil
 }
 
 // NewJWTAuthConnector creates a connector for token (JWT) based authentication.
@@ -82,19 +85,78 @@ func NewJWTAuthConnector(host, token string) *Connector {
 	return c
 }
 
+// NewSAMLAuthConnector creates a connector for SAML assertion based authentication.
+// Please see NewSAMLAuth for details about the assertion function.
+func NewSAMLAuthConnector(host string, assertion func() (string, error)) *Connector {
+	c := NewConnector()
+	c.connAttrs._host = host
+	c.AddAuthMethod(func() AuthMethod { return NewSAMLAuth(assertion) })
+	return c
+}
+
 func newDSNConnector(dsn *DSN) (*Connector, error) {
 	c := NewConnector()
 	c.connAttrs._host = dsn.host
 	c.connAttrs._defaultSchema = dsn.defaultSchema
 	c.connAttrs._pingInterval = dsn.pingInterval
-	c.connAttrs._setTimeout(dsn.timeout)
+	if dsn.timeout != 0 {
+		c.connAttrs._setTimeout(dsn.timeout)
+	}
 	if dsn.tls != nil {
 		if err := c.connAttrs._setTLS(dsn.tls.ServerName, dsn.tls.InsecureSkipVerify, dsn.tls.RootCAFiles); err != nil {
 			return nil, err
 		}
 	}
+	if dsn.bufferSize != 0 {
+		c.connAttrs._bufferSize = dsn.bufferSize
+	}
+	if dsn.fetchSize != 0 {
+		c.connAttrs._setFetchSize(dsn.fetchSize)
+	}
+	if dsn.bulkSize != 0 {
+		c.connAttrs._setBulkSize(dsn.bulkSize)
+	}
+	if dsn.lobChunkSize != 0 {
+		c.connAttrs._lobChunkSize = dsn.lobChunkSize
+	}
+	if dsn.tcpKeepAlive != nil {
+		c.connAttrs._tcpKeepAlive = *dsn.tcpKeepAlive
+	}
+	if dsn.locale != "" {
+		c.connAttrs._locale = dsn.locale
+	}
+	if dsn.applicationName != "" {
+		c.connAttrs._applicationName = dsn.applicationName
+	}
+	if dsn.dfv != 0 {
+		c.connAttrs._dfv = dsn.dfv
+	}
+	if dsn.legacy != nil {
+		c.connAttrs._legacy = *dsn.legacy
+	}
+	if dsn.sessionVariables != nil {
+		c.connAttrs._sessionVariables = cloneStringStringMap(dsn.sessionVariables)
+	}
 	c.authAttrs._username = dsn.username
 	c.authAttrs._password = dsn.password
+	if dsn.clientCertFile != "" {
+		loader := NewPEMClientCertLoader(dsn.clientCertFile, dsn.clientKeyFile, "")
+		clientCert, clientKey, err := loader.Load()
+		if err != nil {
+			return nil, &ParseError{s: fmt.Sprintf("failed to load %s and %s", DSNClientCertFile, DSNClientKeyFile), err: err}
+		}
+		c.authAttrs._clientCert, c.authAttrs._clientKey = clientCert, clientKey
+		c.authAttrs._clientCertFile, c.authAttrs._clientKeyFile = dsn.clientCertFile, dsn.clientKeyFile
+		c.authAttrs._refreshClientCert = clientCertRefresher(loader)
+	}
+	if dsn.tokenFile != "" {
+		src := fileTokenSource(dsn.tokenFile)
+		if _, err := src.Token(); err != nil {
+			return nil, &ParseError{s: fmt.Sprintf("failed to load %s", DSNTokenFile), err: err}
+		}
+		c.authAttrs._tokenSource = newTokenCache(src)
+		c.authAttrs._tokenFile = dsn.tokenFile
+	}
 	return c, nil
 }
 
@@ -221,6 +283,106 @@ func (c *Connector) SetRefreshToken(refreshToken func() (token string, ok bool))
 	c.authAttrs.setRefreshToken(refreshToken)
 }
 
+// AddAuthMethod adds a custom authentication method to the connector.
+// newMethod is called for each logon to create a new method instance, so that methods can keep
+// logon specific state. A method replaces a built-in method of the same type.
+func (c *Connector) AddAuthMethod(newMethod func() AuthMethod) { c.authAttrs.addAuthMethod(newMethod) }
+
+// PasswordChange returns the password change callback function of the connector.
+func (c *Connector) PasswordChange() func(reason PasswordChangeReason, hdbErr Error) (newPassword string, ok bool) {
+	return c.authAttrs.passwordChange()
+}
+
+// SetPasswordChange sets the callback function providing a new password in case
+//   - the user is forced to change the password (reason PasswordChangeRequired, e.g. initial password) or
+//   - the password will expire soon (reason PasswordExpiring).
+//
+// hdbErr is the error or warning returned by the database logon. If the callback function returns
+// ok == true, the password is changed as part of the logon and the new password is used by the
+// connector for subsequent logons. If the password change is required and the callback function
+// returns ok == false, the logon fails with hdbErr.
+func (c *Connector) SetPasswordChange(passwordChange func(reason PasswordChangeReason, hdbErr Error) (newPassword string, ok bool)) {
+	c.authAttrs.setPasswordChange(passwordChange)
+}
+
+// LogonWarning returns the logon warning callback function of the connector.
+func (c *Connector) LogonWarning() func(warning Error) { return c.authAttrs.logonWarning() }
+
+// SetLogonWarning sets the callback function called with the warnings returned by the database
+// logon like password expiry warnings.
+func (c *Connector) SetLogonWarning(logonWarning func(warning Error)) {
+	c.authAttrs.setLogonWarning(logonWarning)
+}
+
+// CookieStore returns the session cookie store of the connector.
+func (c *Connector) CookieStore() CookieStore { return c.authAttrs.cookieStore() }
+
+// SetCookieStore sets a store persisting the session cookies received by logons (e.g. JWT or SAML
+// authentication), so that new connections - even of other processes in case of a FileCookieStore -
+// can logon via session cookie without providing the original credentials.
+// Session cookies are keyed by host and logon name. A stored session cookie is only used if the logon
+// identity of the connector is known: connectors with username use the cookie of the username,
+// connectors without username (JWT authentication) the cookie issued for the subject of the JWT token.
+// A session cookie is removed from the store if the session cookie logon fails.
+func (c *Connector) SetCookieStore(cookieStore CookieStore) { c.authAttrs.setCookieStore(cookieStore) }
+
+// AuthMethods returns the allowed authentication method types in priority order.
+// If not restricted (see SetAuthMethods) nil is returned.
+func (c *Connector) AuthMethods() []string {
+	mts := c.authAttrs.allowedMethods()
+	if mts == nil {
+		return nil
+	}
+	return append([]string(nil), mts...)
+}
+
+// SetAuthMethods restricts the authentication methods to the given method types (see AuthMethod* constants
+// and AuthMethod.Typ for custom methods) in priority order. The restriction applies as follows:
+//   - only allowed methods for which the connector provides credentials are offered to the database
+//     in the order given by mts,
+//   - connect fails with ErrAuthMethodNotAllowed if no allowed method is available or the database
+//     selects a method which is not allowed,
+//   - session cookie reconnects are used only if AuthMethodSessionCookie is allowed,
+//   - a JWT token provided as password (empty username) is not used as token.
+//
+// Calling SetAuthMethods without method types removes the restriction.
+func (c *Connector) SetAuthMethods(mts ...string) error {
+	if len(mts) == 0 {
+		c.authAttrs.setAllowedMethods(nil)
+		return nil
+	}
+	allowed := make([]string, 0, len(mts))
+	seen := make(map[string]bool, len(mts))
+	for _, mt := range mts {
+		if mt == "" {
+			return errors.New("invalid empty authentication method type")
+		}
+		if seen[mt] {
+			return fmt.Errorf("duplicate authentication method type %s", mt)
+		}
+		seen[mt] = true
+		allowed = append(allowed, mt)
+	}
+	c.authAttrs.setAllowedMethods(allowed)
+	return nil
+}
+
+// TokenSource returns the token source of the connector.
+func (c *Connector) TokenSource() TokenSource {
+	if ts := c.authAttrs.tokenSource(); ts != nil {
+		return ts.src
+	}
+	return nil
+}
+
+// SetTokenSource sets a token source providing JWT tokens for token based authentication.
+// The token provided by the token source is cached until shortly before it expires (JWT exp claim).
+// Tokens without expiry time are cached until the database rejects the token.
+// Failed token requests are retried with exponential backoff, token refreshes and failed
+// token refreshes are reported by the connector statistics (see Stats).
+// A token source replaces the refresh token function (see SetRefreshToken).
+func (c *Connector) SetTokenSource(tokenSource TokenSource) { c.authAttrs.setTokenSource(tokenSource) }
+
 // ApplicationName returns the locale of the connector.
 func (c *Connector) ApplicationName() string { return c.connAttrs.applicationName() }
 
@@ -255,6 +417,40 @@ For more information please see DSNFetchSize.
 */
 func (c *Connector) SetFetchSize(fetchSize int) { c.connAttrs.setFetchSize(fetchSize) }
 
+// FetchTargetSize returns the fetch target size of the connector.
+func (c *Connector) FetchTargetSize() int { return c.connAttrs.fetchTargetSize() }
+
+/*
+SetFetchTargetSize sets the target size in bytes of the database replies when fetching resultset rows.
+
+If the fetch target size is greater than zero, the number of rows requested by each fetch is adjusted
+to the bytes per row measured by the previous fetch, so that the reply size approximates the target size
+(adaptive fetch size). In this case fetchSize is only used for the first fetch of a resultset.
+A fetch target size of zero (default) disables the adaptive fetch size.
+
+The fetch size of a single query can be set via context (see WithFetchSize).
+*/
+func (c *Connector) SetFetchTargetSize(fetchTargetSize int) {
+	c.connAttrs.setFetchTargetSize(fetchTargetSize)
+}
+
+// Prefetch returns the number of resultset chunks fetched in advance by the connector.
+func (c *Connector) Prefetch() int { return c.connAttrs.prefetch() }
+
+/*
+SetPrefetch sets the number of resultset chunks (of fetchSize rows) fetched in advance (read-ahead).
+
+If prefetch is greater than zero, the next resultset chunks are requested in the background while the
+application is processing the current one. Prefetching is disabled by default (prefetch == 0).
+The maximum value of prefetch is 16.
+
+Remarks:
+  - Prefetching is not applied to resultsets containing large object fields, as reading lob content
+    needs the database connection while the rows are processed.
+  - Prefetched chunks are buffered in memory - please choose fetchSize and prefetch accordingly.
+*/
+func (c *Connector) SetPrefetch(prefetch int) { c.connAttrs.setPrefetch(prefetch) }
+
 // LobChunkSize returns the lobChunkSize of the connector.
 func (c *Connector) LobChunkSize() int { return c.connAttrs.lobChunkSize() }
 
@@ -303,30 +499,38 @@ func isAuthError(checkErr error) bool {
 // Connect implements the database/sql/driver/Connector interface.
 func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
 	// can we connect via cookie?
-	if auth := c.authAttrs.cookieAuth(); auth != nil {
-		conn, err := newConn(ctx, c.metrics, c.connAttrs, auth)
+	host := c.connAttrs.host()
+	if auth := c.authAttrs.cookieAuth(host); auth != nil {
+		conn, err := newConn(ctx, c.metrics, c.connAttrs, auth, c.authAttrs.logonHooks())
 		if err == nil {
 			return conn, nil
 		}
 		if !isAuthError(err) {
 			return nil, err
 		}
+		c.authAttrs.invalidateSessionCookie(host)
+	}
+
+	if err := c.authAttrs.refreshExpiringToken(c.metrics); err != nil {
+		return nil, err
 	}
 
 	auth := c.authAttrs.auth()
 	retries := 1
 	for {
-		conn, err := newConn(ctx, c.metrics, c.connAttrs, auth)
+		conn, err := newConn(ctx, c.metrics, c.connAttrs, auth, c.authAttrs.logonHooks())
 		if err == nil {
 			if method, ok := auth.Method().(p.AuthCookieGetter); ok {
-				c.authAttrs.setSessionCookie(method.Cookie())
+				if logonname, cookie := method.Cookie(); cookie != nil {
+					c.authAttrs.saveSessionCookie(host, logonname, cookie)
+				}
 			}
 			return conn, nil
 		}
 		if !isAuthError(err) {
 			return nil, err
 		}
-		if retries < 1 || !c.authAttrs.refresh(auth) {
+		if retries < 1 || !c.authAttrs.refresh(auth, c.metrics) {
 			return nil, err
 		}
 		retries--
@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCookieTTL is the default time a stored session cookie is considered valid.
+const DefaultCookieTTL = 12 * time.Hour
+
+// A SessionCookie is a session cookie received by a database logon (e.g. JWT authentication)
+// which can be used for subsequent logons without providing the original credentials.
+type SessionCookie struct {
+	Host      string    `json:"host"`
+	Logonname string    `json:"logonname"`
+	Subject   string    `json:"subject,omitempty"` // subject of the JWT token the cookie was issued for
+	ClientID  string    `json:"clientID"`          // the cookie is bound to the client id of the session it was issued for
+	Cookie    []byte    `json:"cookie"`
+	Expires   time.Time `json:"expires"`
+}
+
+func (c *SessionCookie) expired(t time.Time) bool { return !c.Expires.IsZero() && !t.Before(c.Expires) }
+
+// A CookieStore persists session cookies keyed by host and logon name (see Connector.SetCookieStore).
+type CookieStore interface {
+	// Load returns the not expired session cookies of host.
+	Load(host string) ([]*SessionCookie, error)
+	// Save stores the session cookie replacing a cookie with same host and logon name.
+	Save(cookie *SessionCookie) error
+	// Delete removes the session cookie of host and logon name.
+	Delete(host, logonname string) error
+}
+
+type sessionCookies []*SessionCookie
+
+func sameCookieKey(c *SessionCookie, host, logonname string) bool {
+	return c.Host == host && strings.EqualFold(c.Logonname, logonname)
+}
+
+// load returns the not expired cookies of host.
+func (s sessionCookies) load(host string, now time.Time) []*SessionCookie {
+	var cookies []*SessionCookie
+	for _, c := range s {
+		if c.Host == host && !c.expired(now) {
+			cc := *c
+			cookies = append(cookies, &cc)
+		}
+	}
+	return cookies
+}
+
+// delete removes expired cookies and cookies of host and logon name.
+func (s sessionCookies) delete(host, logonname string, now time.Time) sessionCookies {
+	cookies := s[:0]
+	for _, c := range s {
+		if !c.expired(now) && !sameCookieKey(c, host, logonname) {
+			cookies = append(cookies, c)
+		}
+	}
+	return cookies
+}
+
+// save replaces the cookie of host and logon name.
+func (s sessionCookies) save(cookie *SessionCookie, ttl time.Duration, now time.Time) sessionCookies {
+	cc := *cookie
+	if cc.Expires.IsZero() && ttl > 0 {
+		cc.Expires = now.Add(ttl)
+	}
+	return append(s.delete(cookie.Host, cookie.Logonname, now), &cc)
+}
+
+// MemoryCookieStore is a CookieStore keeping the session cookies in memory.
+// A MemoryCookieStore can be shared by connectors of the same process.
+type MemoryCookieStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	cookies sessionCookies
+}
+
+var _ CookieStore = (*MemoryCookieStore)(nil)
+
+// NewMemoryCookieStore returns a new MemoryCookieStore instance.
+// Session cookies without expiry time expire after ttl (default DefaultCookieTTL if ttl <= 0).
+func NewMemoryCookieStore(ttl time.Duration) *MemoryCookieStore {
+	if ttl <= 0 {
+		ttl = DefaultCookieTTL
+	}
+	return &MemoryCookieStore{ttl: ttl}
+}
+
+// Load implements the CookieStore interface.
+func (s *MemoryCookieStore) Load(host string) ([]*SessionCookie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cookies.load(host, time.Now()), nil
+}
+
+// Save implements the CookieStore interface.
+func (s *MemoryCookieStore) Save(cookie *SessionCookie) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cookies = s.cookies.save(cookie, s.ttl, time.Now())
+	return nil
+}
+
+// Delete implements the CookieStore interface.
+func (s *MemoryCookieStore) Delete(host, logonname string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cookies = s.cookies.delete(host, logonname, time.Now())
+	return nil
+}
+
+// FileCookieStore is a CookieStore persisting the session cookies in a JSON file
+// so that session cookies can be used across processes.
+// The file is created with permission 0600 and replaced atomically on updates.
+// Updates of concurrent processes are serialized by a lock file (file path with suffix .lock)
+// which is removed after the update. Lock files older than 30 seconds (e.g. left over by
+// a crashed process) are removed on the next update.
+// As session cookies are credentials the file needs to be protected accordingly.
+type FileCookieStore struct {
+	mu   sync.Mutex
+	path string
+	ttl  time.Duration
+}
+
+var _ CookieStore = (*FileCookieStore)(nil)
+
+// NewFileCookieStore returns a new FileCookieStore instance storing the session cookies in file path.
+// Session cookies without expiry time expire after ttl (default DefaultCookieTTL if ttl <= 0).
+func NewFileCookieStore(path string, ttl time.Duration) *FileCookieStore {
+	if ttl <= 0 {
+		ttl = DefaultCookieTTL
+	}
+	return &FileCookieStore{path: path, ttl: ttl}
+}
+
+// Path returns the file path of the cookie store.
+func (s *FileCookieStore) Path() string { return s.path }
+
+func (s *FileCookieStore) read() (sessionCookies, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cookies sessionCookies
+	if err := json.Unmarshal(b, &cookies); err != nil {
+		return nil, err
+	}
+	return cookies, nil
+}
+
+func (s *FileCookieStore) write(cookies sessionCookies) error {
+	b, err := json.MarshalIndent(cookies, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, b)
+}
+
+// writeFileAtomic writes data to a temporary file with permission 0600 and renames it to path,
+// so that concurrent readers never see partially written files.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // ignore error (file does not exist after successful rename)
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path) // CreateTemp creates files with permission 0600
+}
+
+const (
+	fileLockTimeout = 5 * time.Second  // maximum wait time for a lock file.
+	fileLockStale   = 30 * time.Second // lock files older than fileLockStale are considered stale.
+	fileLockRetry   = 10 * time.Millisecond
+)
+
+// lockFile creates the lock file path.lock exclusively (O_EXCL) and returns a function removing it,
+// so that read-modify-write updates of path are serialized across processes.
+func lockFile(path string) (unlock func(), err error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(fileLockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+		if fi, err := os.Stat(lockPath); err == nil && time.Since(fi.ModTime()) > fileLockStale {
+			os.Remove(lockPath) // stale lock file
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("lock file %s: timeout after %s", lockPath, fileLockTimeout)
+		}
+		time.Sleep(fileLockRetry)
+	}
+}
+
+// update reads the cookies, applies fn and writes the result while holding the file lock.
+func (s *FileCookieStore) update(fn func(cookies sessionCookies) sessionCookies) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	cookies, err := s.read()
+	if err != nil {
+		return err
+	}
+	return s.write(fn(cookies))
+}
+
+// Load implements the CookieStore interface.
+func (s *FileCookieStore) Load(host string) ([]*SessionCookie, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cookies, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return cookies.load(host, time.Now()), nil
+}
+
+// Save implements the CookieStore interface.
+func (s *FileCookieStore) Save(cookie *SessionCookie) error {
+	return s.update(func(cookies sessionCookies) sessionCookies { return cookies.save(cookie, s.ttl, time.Now()) })
+}
+
+// Delete implements the CookieStore interface.
+func (s *FileCookieStore) Delete(host, logonname string) error {
+	return s.update(func(cookies sessionCookies) sessionCookies { return cookies.delete(host, logonname, time.Now()) })
+}
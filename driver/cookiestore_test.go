@@ -0,0 +1,198 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testCookieStore(store CookieStore, t *testing.T) {
+	const host1, host2 = "host1:30015", "host2:30015"
+
+	cookies := []*SessionCookie{
+		{Host: host1, Logonname: "USER1", ClientID: "1@host", Cookie: []byte("cookie1")},
+		{Host: host1, Logonname: "USER2", ClientID: "2@host", Cookie: []byte("cookie2")},
+		{Host: host2, Logonname: "USER1", ClientID: "3@host", Cookie: []byte("cookie3")},
+		{Host: host2, Logonname: "USER3", Cookie: []byte("expired"), Expires: time.Now().Add(-time.Minute)},
+	}
+	for _, c := range cookies {
+		if err := store.Save(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	load := func(host string) []*SessionCookie {
+		t.Helper()
+		cookies, err := store.Load(host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cookies
+	}
+
+	if cookies := load(host1); len(cookies) != 2 {
+		t.Fatalf("number of cookies %d - expected 2", len(cookies))
+	}
+	cookies = load(host2)
+	if len(cookies) != 1 {
+		t.Fatalf("number of cookies %d - expected 1 (expired cookie)", len(cookies))
+	}
+	if c := cookies[0]; c.Logonname != "USER1" || c.ClientID != "3@host" || string(c.Cookie) != "cookie3" || c.Expires.IsZero() {
+		t.Fatalf("unexpected cookie %v", c)
+	}
+
+	// replace cookie
+	if err := store.Save(&SessionCookie{Host: host1, Logonname: "user1", Cookie: []byte("cookie4")}); err != nil {
+		t.Fatal(err)
+	}
+	cookies = load(host1)
+	if len(cookies) != 2 || string(cookies[1].Cookie) != "cookie4" {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+
+	if err := store.Delete(host1, "USER1"); err != nil {
+		t.Fatal(err)
+	}
+	cookies = load(host1)
+	if len(cookies) != 1 || cookies[0].Logonname != "USER2" {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+}
+
+func testSubjectJWT(sub string) string {
+	enc := base64.RawURLEncoding
+	return fmt.Sprintf("%s.%s.%s",
+		enc.EncodeToString([]byte(`{"alg":"none"}`)),
+		enc.EncodeToString([]byte(fmt.Sprintf(`{"sub":%q}`, sub))),
+		enc.EncodeToString([]byte("signature")),
+	)
+}
+
+func testCookieStoreConnector(store CookieStore, t *testing.T) {
+	const host = "localhost:39013"
+
+	c1 := NewJWTAuthConnector(host, testSubjectJWT("user1"))
+	c1.SetCookieStore(store)
+	c1.authAttrs.saveSessionCookie(host, "USER1", []byte("cookie"))
+
+	// new connector (process) with token of same subject uses stored cookie including client id
+	c2 := NewJWTAuthConnector(host, testSubjectJWT("user1"))
+	c2.SetCookieStore(store)
+	auth := c2.authAttrs.cookieAuth(host)
+	if auth == nil {
+		t.Fatal("session cookie authentication expected")
+	}
+	if auth.ClientID() != clientID {
+		t.Fatalf("client id %s - expected %s", auth.ClientID(), clientID)
+	}
+
+	// connector with token of different subject does not use cookie
+	c3 := NewJWTAuthConnector(host, testSubjectJWT("user2"))
+	c3.SetCookieStore(store)
+	if c3.authAttrs.cookieAuth(host) != nil {
+		t.Fatal("unexpected session cookie authentication")
+	}
+
+	// connector with unknown logon identity (token without subject) does not use cookie
+	c4 := NewJWTAuthConnector(host, "token")
+	c4.SetCookieStore(store)
+	if c4.authAttrs.cookieAuth(host) != nil {
+		t.Fatal("unexpected session cookie authentication")
+	}
+
+	// connector with different user does not use cookie
+	c5 := NewBasicAuthConnector(host, "USER2", "password")
+	c5.SetCookieStore(store)
+	if c5.authAttrs.cookieAuth(host) != nil {
+		t.Fatal("unexpected session cookie authentication")
+	}
+
+	// invalidate
+	c2.authAttrs.invalidateSessionCookie(host)
+	if c2.authAttrs.cookieAuth(host) != nil {
+		t.Fatal("session cookie should be invalidated")
+	}
+	if cookies, err := store.Load(host); err != nil || len(cookies) != 0 {
+		t.Fatalf("cookies %v error %v - expected no cookies", cookies, err)
+	}
+}
+
+func testFileCookieStoreLock(store *FileCookieStore, t *testing.T) {
+	const numWorker = 10
+
+	// concurrent updates of different store instances (processes) are not lost
+	var wg sync.WaitGroup
+	errs := make(chan error, numWorker)
+	for i := 0; i < numWorker; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := NewFileCookieStore(store.Path(), 0)
+			errs <- s.Save(&SessionCookie{Host: "host", Logonname: fmt.Sprintf("USER%d", i), Cookie: []byte("cookie")})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cookies, err := store.Load("host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cookies) != numWorker {
+		t.Fatalf("number of cookies %d - expected %d", len(cookies), numWorker)
+	}
+	if _, err := os.Stat(store.Path() + ".lock"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("lock file not removed: %v", err)
+	}
+
+	// stale lock file is removed
+	lockPath := store.Path() + ".lock"
+	if err := os.WriteFile(lockPath, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * fileLockStale)
+	if err := os.Chtimes(lockPath, stale, stale); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("host", "USER0"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCookieStore(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("memory", func(t *testing.T) { testCookieStore(NewMemoryCookieStore(0), t) })
+	t.Run("file", func(t *testing.T) {
+		store := NewFileCookieStore(filepath.Join(dir, "cookies.json"), 0)
+		testCookieStore(store, t)
+		fi, err := os.Stat(store.Path())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm != 0600 {
+			t.Fatalf("file permission %o - expected %o", perm, 0600)
+		}
+	})
+	t.Run("memoryConnector", func(t *testing.T) { testCookieStoreConnector(NewMemoryCookieStore(0), t) })
+	t.Run("fileConnector", func(t *testing.T) {
+		testCookieStoreConnector(NewFileCookieStore(filepath.Join(dir, "connector.json"), 0), t)
+	})
+	t.Run("fileLock", func(t *testing.T) {
+		testFileCookieStoreLock(NewFileCookieStore(filepath.Join(dir, "lock.json"), 0), t)
+	})
+}
@@ -0,0 +1,203 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+	"time"
+)
+
+/*
+A DSNBuilder builds a DSN programmatically.
+
+Example:
+
+	dsn, err := driver.NewDSNBuilder("localhost:39013").
+		Credentials("myuser", "mypassword").
+		TLS("hostname", false, "trust.pem").
+		FetchSize(1000).
+		Build()
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("dsn: %s", dsn.Redacted())
+	db, err := sql.Open(driver.DriverName, dsn.String())
+*/
+type DSNBuilder struct {
+	dsn DSN
+}
+
+// NewDSNBuilder returns a new DSNBuilder instance for host (<host address>:<port number>).
+func NewDSNBuilder(host string) *DSNBuilder { return &DSNBuilder{dsn: DSN{host: host}} }
+
+// Credentials sets the username and password (basic authentication).
+func (b *DSNBuilder) Credentials(username, password string) *DSNBuilder {
+	b.dsn.username, b.dsn.password = username, password
+	return b
+}
+
+// DefaultSchema sets the database default schema (see DSNDefaultSchema).
+func (b *DSNBuilder) DefaultSchema(schema string) *DSNBuilder {
+	b.dsn.defaultSchema = schema
+	return b
+}
+
+// Timeout sets the driver side connection timeout (see DSNTimeout). The DSN keeps full seconds only.
+func (b *DSNBuilder) Timeout(timeout time.Duration) *DSNBuilder { b.dsn.timeout = timeout; return b }
+
+// PingInterval sets the connection ping interval (see DSNPingInterval). The DSN keeps full seconds only.
+func (b *DSNBuilder) PingInterval(d time.Duration) *DSNBuilder { b.dsn.pingInterval = d; return b }
+
+// TLS sets the TLS parameters (see DSNTLSServerName, DSNTLSInsecureSkipVerify and DSNTLSRootCAFile).
+func (b *DSNBuilder) TLS(serverName string, insecureSkipVerify bool, rootCAFiles ...string) *DSNBuilder {
+	b.dsn.tls = &TLSPrms{ServerName: serverName, InsecureSkipVerify: insecureSkipVerify, RootCAFiles: rootCAFiles}
+	return b
+}
+
+// BufferSize sets the connection buffer size (see DSNBufferSize).
+func (b *DSNBuilder) BufferSize(bufferSize int) *DSNBuilder { b.dsn.bufferSize = bufferSize; return b }
+
+// FetchSize sets the fetch size (see DSNFetchSize).
+func (b *DSNBuilder) FetchSize(fetchSize int) *DSNBuilder { b.dsn.fetchSize = fetchSize; return b }
+
+// BulkSize sets the bulk size (see DSNBulkSize).
+func (b *DSNBuilder) BulkSize(bulkSize int) *DSNBuilder { b.dsn.bulkSize = bulkSize; return b }
+
+// LobChunkSize sets the lob chunk size (see DSNLobChunkSize).
+func (b *DSNBuilder) LobChunkSize(lobChunkSize int) *DSNBuilder {
+	b.dsn.lobChunkSize = lobChunkSize
+	return b
+}
+
+// TCPKeepAlive sets the TCP keep-alive interval (see DSNTCPKeepAlive). The DSN keeps full seconds only.
+func (b *DSNBuilder) TCPKeepAlive(tcpKeepAlive time.Duration) *DSNBuilder {
+	b.dsn.tcpKeepAlive = &tcpKeepAlive
+	return b
+}
+
+// Locale sets the client locale (see DSNLocale).
+func (b *DSNBuilder) Locale(locale string) *DSNBuilder { b.dsn.locale = locale; return b }
+
+// ApplicationName sets the application name (see DSNApplicationName).
+func (b *DSNBuilder) ApplicationName(name string) *DSNBuilder {
+	b.dsn.applicationName = name
+	return b
+}
+
+// Dfv sets the data format version (see DSNDfv).
+func (b *DSNBuilder) Dfv(dfv int) *DSNBuilder { b.dsn.dfv = dfv; return b }
+
+// Legacy sets the legacy mode (see DSNLegacy).
+func (b *DSNBuilder) Legacy(legacy bool) *DSNBuilder { b.dsn.legacy = &legacy; return b }
+
+// SessionVariable adds a session variable (see DSNSessionVariablePrefix).
+func (b *DSNBuilder) SessionVariable(name, value string) *DSNBuilder {
+	if b.dsn.sessionVariables == nil {
+		b.dsn.sessionVariables = map[string]string{}
+	}
+	b.dsn.sessionVariables[name] = value
+	return b
+}
+
+// ClientCertFiles sets the X509 client certificate and key files (see DSNClientCertFile and DSNClientKeyFile).
+func (b *DSNBuilder) ClientCertFiles(clientCertFile, clientKeyFile string) *DSNBuilder {
+	b.dsn.clientCertFile, b.dsn.clientKeyFile = clientCertFile, clientKeyFile
+	return b
+}
+
+// TokenFile sets the JWT token file (see DSNTokenFile).
+func (b *DSNBuilder) TokenFile(tokenFile string) *DSNBuilder { b.dsn.tokenFile = tokenFile; return b }
+
+// Build validates the parameters and returns the DSN. Invalid parameters are reported as ParseError.
+func (b *DSNBuilder) Build() (*DSN, error) {
+	if b.dsn.host == "" {
+		return nil, &ParseError{s: "invalid parameter - host is empty"}
+	}
+	if b.dsn.password != "" && b.dsn.username == "" {
+		return nil, &ParseError{s: "invalid parameter - password without username"}
+	}
+	// validate by parsing the DSN string
+	return parseDSN(b.dsn.String())
+}
+
+// DSN returns a DSN representing the connector configuration. Settings with default values are omitted.
+// Settings which cannot be expressed by DSN parameters like callback functions, dialer, custom
+// authentication methods and cookie stores are not included. An error is returned if the
+// TLS configuration was set by SetTLSConfig or the client certificate or the token were not
+// loaded from files, as the DSN would not represent the connector security configuration, or if
+// the timeout is 0, as a DSN without timeout parameter uses the default timeout.
+func (c *Connector) DSN() (*DSN, error) {
+	c.connAttrs.mu.RLock()
+	defer c.connAttrs.mu.RUnlock()
+	c.authAttrs.mu.RLock()
+	defer c.authAttrs.mu.RUnlock()
+
+	ca, aa := c.connAttrs, c.authAttrs
+
+	dsn := &DSN{
+		host:          ca._host,
+		username:      aa._username,
+		password:      aa._password,
+		defaultSchema: ca._defaultSchema,
+		pingInterval:  ca._pingInterval,
+	}
+	switch ca._timeout {
+	case 0:
+		return nil, errors.New("timeout 0 cannot be represented by DSN parameters")
+	case defaultTimeout:
+	default:
+		dsn.timeout = ca._timeout
+	}
+	if ca._tlsConfig != nil {
+		if ca._tlsPrms == nil {
+			return nil, errors.New("TLS configuration set by SetTLSConfig cannot be represented by DSN parameters")
+		}
+		dsn.tls = &TLSPrms{ServerName: ca._tlsPrms.ServerName, InsecureSkipVerify: ca._tlsPrms.InsecureSkipVerify, RootCAFiles: cloneStringSlice(ca._tlsPrms.RootCAFiles)}
+	}
+	if ca._bufferSize != defaultBufferSize {
+		dsn.bufferSize = ca._bufferSize
+	}
+	if ca._fetchSize != defaultFetchSize {
+		dsn.fetchSize = ca._fetchSize
+	}
+	if ca._bulkSize != defaultBulkSize {
+		dsn.bulkSize = ca._bulkSize
+	}
+	if ca._lobChunkSize != defaultLobChunkSize {
+		dsn.lobChunkSize = ca._lobChunkSize
+	}
+	if ca._tcpKeepAlive != defaultTCPKeepAlive {
+		tcpKeepAlive := ca._tcpKeepAlive
+		dsn.tcpKeepAlive = &tcpKeepAlive
+	}
+	dsn.locale = ca._locale
+	if ca._applicationName != defaultApplicationName {
+		dsn.applicationName = ca._applicationName
+	}
+	if ca._dfv != defaultDfv {
+		dsn.dfv = ca._dfv
+	}
+	if ca._legacy != defaultLegacy {
+		legacy := ca._legacy
+		dsn.legacy = &legacy
+	}
+	if len(ca._sessionVariables) != 0 {
+		dsn.sessionVariables = cloneStringStringMap(ca._sessionVariables)
+	}
+
+	if aa._clientCert != nil {
+		if aa._clientCertFile == "" {
+			return nil, errors.New("client certificate not loaded from files cannot be represented by DSN parameters")
+		}
+		dsn.clientCertFile, dsn.clientKeyFile = aa._clientCertFile, aa._clientKeyFile
+	}
+	if aa._token != "" || aa._tokenSource != nil {
+		if aa._tokenFile == "" {
+			return nil, errors.New("token not loaded from file cannot be represented by DSN parameters")
+		}
+		dsn.tokenFile = aa._tokenFile
+	}
+	return dsn, nil
+}
@@ -69,3 +69,68 @@ func ExampleConn_DBConnectInfo() {
 
 	// output: ok
 }
+
+// ExampleConn-DescribeStmt shows how to retrieve the parameter and result column descriptions of a statement
+// without executing it with the help of sql.Conn.Raw().
+func ExampleConn_DescribeStmt() {
+	db := sql.OpenDB(driver.NewTestConnector())
+	defer db.Close()
+
+	// Grab connection.
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.Raw(func(driverConn interface{}) error {
+		// Access driver.Conn methods.
+		sd, err := driverConn.(driver.Conn).DescribeStmt(context.Background(), "select dummy from dummy where 1 = ?")
+		if err != nil {
+			return err
+		}
+		for _, prm := range sd.Parameters {
+			fmt.Printf("parameter in %t out %t type %s data type %s\n", prm.In, prm.Out, prm.TypeName, prm.DataType)
+		}
+		for _, col := range sd.Columns {
+			fmt.Printf("column schema %s table %s column %s type %s data type %s\n", col.SchemaName, col.TableName, col.ColumnName, col.TypeName, col.DataType)
+		}
+		return nil
+	}); err != nil {
+		log.Fatal(err)
+	}
+
+	// output:
+	// parameter in true out false type INTEGER data type DtInteger
+	// column schema SYS table DUMMY column DUMMY type VARCHAR data type DtString
+}
+
+// ExampleConn-Explain shows how to retrieve the explain plan of a query with the help of sql.Conn.Raw().
+func ExampleConn_Explain() {
+	db := sql.OpenDB(driver.NewTestConnector())
+	defer db.Close()
+
+	// Grab connection.
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.Raw(func(driverConn interface{}) error {
+		// Access driver.Conn methods.
+		root, err := driverConn.(driver.Conn).Explain(context.Background(), "select * from dummy")
+		if err != nil {
+			return err
+		}
+		log.Printf("explain plan:\n%s", root)
+		return nil
+	}); err != nil {
+		log.Fatal(err)
+	}
+
+	// Make sure that the example is executed during test runs.
+	fmt.Print("ok")
+
+	// output: ok
+}
@@ -0,0 +1,62 @@
+//go:build !unit
+// +build !unit
+
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	sqldriver "database/sql/driver"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// ExampleRowsColumnDescr shows how to retrieve the column descriptions of a query result with the help of sql.Conn.Raw().
+func ExampleRowsColumnDescr() {
+	db := sql.OpenDB(driver.NewTestConnector())
+	defer db.Close()
+
+	// Grab connection.
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.Raw(func(driverConn interface{}) error {
+		// Query via driver connection.
+		rows, err := driverConn.(sqldriver.QueryerContext).QueryContext(context.Background(), "select dummy, 1 as one from dummy", nil)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		// Computed columns do not have a source schema, table and column.
+		for _, descr := range rows.(driver.RowsColumnDescr).ColumnDescrs() {
+			fmt.Printf("schema %q table %q column %q display name %q type %s\n", descr.SchemaName, descr.TableName, descr.ColumnName, descr.DisplayName, descr.TypeName)
+		}
+
+		dest := make([]sqldriver.Value, len(rows.Columns()))
+		for {
+			if err := rows.Next(dest); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		}
+	}); err != nil {
+		log.Fatal(err)
+	}
+
+	// output:
+	// schema "SYS" table "DUMMY" column "DUMMY" display name "DUMMY" type VARCHAR
+	// schema "" table "" column "" display name "ONE" type INTEGER
+}
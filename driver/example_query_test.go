@@ -8,6 +8,7 @@
 package driver
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -36,3 +37,43 @@ func Example_query() {
 	fmt.Print(i)
 	// output: 0
 }
+
+// ExampleExpandInConn demonstrates binding slices to in-list predicates.
+// The second slice exceeds the maximum number of values and is bound via a local temporary table.
+// For TestConnector see main_test.go.
+func ExampleExpandInConn() {
+	ctx := context.Background()
+
+	db := sql.OpenDB(NewTestConnector())
+	defer db.Close()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	table := RandomIdentifier("testInList_")
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("create table %s (i integer, s nvarchar(10))", table)); err != nil {
+		log.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("insert into %s values (?, ?)", table), i, fmt.Sprint(i)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	query, args, drop, err := ExpandInConn(ctx, conn, 3, fmt.Sprintf("select count(*) from %s where i in (?) and s in (?)", table),
+		[]int{1, 2, 3}, []string{"2", "3", "4", "5"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer drop(ctx)
+
+	var cnt int
+	if err := conn.QueryRowContext(ctx, query, args...).Scan(&cnt); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(cnt)
+	// output: 2
+}
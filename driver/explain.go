@@ -0,0 +1,160 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	explainPlanStmt   = "explain plan set statement_name = '%s' for %s"
+	explainPlanSelect = "select operator_id, parent_operator_id, operator_name, to_nvarchar(operator_details), schema_name, table_name, to_double(subtree_cost), to_double(output_size) from explain_plan_table where statement_name = '%s' order by operator_id"
+	explainPlanDelete = "delete from explain_plan_table where statement_name = '%s'"
+)
+
+// explainID is used to generate unique explain plan statement names.
+var explainID uint64
+
+func newExplainStmtName(sessionID int64) string {
+	return fmt.Sprintf("GOHDB_EXPLAIN_%d_%d", sessionID, atomic.AddUint64(&explainID, 1))
+}
+
+// PlanOperator is an operator of an explain plan operator tree.
+type PlanOperator struct {
+	ID          int64
+	Name        string          // Operator name (e.g. COLUMN SEARCH, JOIN).
+	Details     string          // Operator details (e.g. filter and join conditions).
+	SchemaName  string          // Schema name of the accessed table (empty if no table is accessed).
+	TableName   string          // Name of the accessed table (empty if no table is accessed).
+	Cost        float64         // Estimated cost of the operator subtree.
+	Cardinality float64         // Estimated number of output rows.
+	Children    []*PlanOperator // Child operators.
+}
+
+func (o *PlanOperator) String() string {
+	b := &strings.Builder{}
+	o.format(b, 0)
+	return b.String()
+}
+
+func (o *PlanOperator) format(b *strings.Builder, level int) {
+	b.WriteString(strings.Repeat("  ", level))
+	b.WriteString(o.Name)
+	if o.TableName != "" {
+		fmt.Fprintf(b, " %s.%s", o.SchemaName, o.TableName)
+	}
+	if o.Details != "" {
+		fmt.Fprintf(b, " (%s)", o.Details)
+	}
+	fmt.Fprintf(b, " cost %g cardinality %g\n", o.Cost, o.Cardinality)
+	for _, child := range o.Children {
+		child.format(b, level+1)
+	}
+}
+
+// Walk calls fn for the operator and all its descendants in depth-first order.
+// Walk stops if fn returns false.
+func (o *PlanOperator) Walk(fn func(o *PlanOperator) bool) bool {
+	if !fn(o) {
+		return false
+	}
+	for _, child := range o.Children {
+		if !child.Walk(fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// explainPlan builds the operator tree from the explain plan table rows.
+func explainPlan(rows driver.Rows) (*PlanOperator, error) {
+	var root *PlanOperator
+	ops := map[int64]*PlanOperator{}
+	var parentIDs []int64
+	var order []*PlanOperator
+
+	dest := make([]driver.Value, len(rows.Columns()))
+	for {
+		if err := rows.Next(dest); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		o := &PlanOperator{
+			ID:          asInt64(dest[0]),
+			Name:        asString(dest[2]),
+			Details:     asString(dest[3]),
+			SchemaName:  asString(dest[4]),
+			TableName:   asString(dest[5]),
+			Cost:        asFloat64(dest[6]),
+			Cardinality: asFloat64(dest[7]),
+		}
+		ops[o.ID] = o
+		order = append(order, o)
+		if dest[1] == nil {
+			parentIDs = append(parentIDs, -1)
+		} else {
+			parentIDs = append(parentIDs, asInt64(dest[1]))
+		}
+	}
+
+	for i, o := range order {
+		parent, ok := ops[parentIDs[i]]
+		if !ok {
+			if root != nil {
+				return nil, fmt.Errorf("explain plan: multiple root operators %d and %d", root.ID, o.ID)
+			}
+			root = o
+			continue
+		}
+		parent.Children = append(parent.Children, o)
+	}
+	if root == nil {
+		return nil, fmt.Errorf("explain plan: no operators found")
+	}
+	return root, nil
+}
+
+func asString(v driver.Value) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return ""
+	}
+}
+
+func asInt64(v driver.Value) int64 {
+	switch v := v.(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+func asFloat64(v driver.Value) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+type testExplainRows struct {
+	values [][]driver.Value
+	pos    int
+}
+
+func (r *testExplainRows) Columns() []string {
+	return []string{"OPERATOR_ID", "PARENT_OPERATOR_ID", "OPERATOR_NAME", "OPERATOR_DETAILS", "SCHEMA_NAME", "TABLE_NAME", "SUBTREE_COST", "OUTPUT_SIZE"}
+}
+
+func (r *testExplainRows) Close() error { return nil }
+
+func (r *testExplainRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.pos])
+	r.pos++
+	return nil
+}
+
+func TestExplainPlan(t *testing.T) {
+	rows := &testExplainRows{values: [][]driver.Value{
+		{int32(1), nil, "COLUMN SEARCH", "T1.A, T2.B", nil, nil, 12.5, 100.0},
+		{int32(2), int32(1), "JOIN", "T1.ID = T2.ID", nil, nil, 10.0, 100.0},
+		{int32(3), int32(2), "COLUMN TABLE", nil, "S", "T1", 2.0, 1000.0},
+		{int32(4), int32(2), "COLUMN TABLE", nil, "S", "T2", 3.0, 10.0},
+	}}
+
+	root, err := explainPlan(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.ID != 1 || root.Name != "COLUMN SEARCH" || root.Cost != 12.5 || len(root.Children) != 1 {
+		t.Fatalf("invalid root operator %#v", root)
+	}
+	join := root.Children[0]
+	if join.Details != "T1.ID = T2.ID" || len(join.Children) != 2 {
+		t.Fatalf("invalid join operator %#v", join)
+	}
+	if join.Children[0].TableName != "T1" || join.Children[1].TableName != "T2" || join.Children[1].Cardinality != 10 {
+		t.Fatalf("invalid table operators %#v %#v", join.Children[0], join.Children[1])
+	}
+
+	var tables []string
+	root.Walk(func(o *PlanOperator) bool {
+		if o.TableName != "" {
+			tables = append(tables, o.SchemaName+"."+o.TableName)
+		}
+		return true
+	})
+	if len(tables) != 2 || tables[0] != "S.T1" || tables[1] != "S.T2" {
+		t.Fatalf("invalid tables %v", tables)
+	}
+
+	if _, err := explainPlan(&testExplainRows{}); err == nil {
+		t.Fatal("expected no operators error")
+	}
+}
+
+func TestExplainInTransaction(t *testing.T) {
+	c := &conn{dbConn: &dbConn{}, inTx: true}
+	if _, err := c.Explain(context.Background(), "select * from dummy"); !errors.Is(err, ErrExplainInTransaction) {
+		t.Fatalf("got error %v - expected %v", err, ErrExplainInTransaction)
+	}
+}
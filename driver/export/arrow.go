@@ -0,0 +1,330 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+)
+
+/*
+Apache Arrow IPC streaming format
+- see https://arrow.apache.org/docs/format/Columnar.html#ipc-streaming-format
+- see https://github.com/apache/arrow/blob/master/format/Schema.fbs and Message.fbs
+*/
+
+// arrow metadata version V5.
+const arrowMetadataV5 = 4
+
+// arrow message header types.
+const (
+	arrowHeaderSchema      = 1
+	arrowHeaderRecordBatch = 3
+)
+
+// arrow type ids.
+const (
+	arrowTypeInt           = 2
+	arrowTypeFloatingPoint = 3
+	arrowTypeBinary        = 4
+	arrowTypeUtf8          = 5
+	arrowTypeBool          = 6
+	arrowTypeDate          = 8
+	arrowTypeTime          = 9
+	arrowTypeTimestamp     = 10
+)
+
+// arrow enumerations.
+const (
+	arrowPrecisionSingle  = 1
+	arrowPrecisionDouble  = 2
+	arrowDateUnitDay      = 0
+	arrowTimeUnitSecond   = 0
+	arrowTimeUnitMicro    = 2
+	arrowTimestampTZ      = "UTC"
+	arrowContinuation     = 0xFFFFFFFF
+	arrowAlignment        = 8
+	arrowFieldNodeSize    = 16
+	arrowBufferSize       = 16
+	arrowStructAlignement = 8
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// arrowType returns the arrow type id and type table of a column.
+func (c *column) arrowType() (fbUint8, fbTable) {
+	switch c.kind {
+	case kdBool:
+		return arrowTypeBool, fbTable{}
+	case kdInt:
+		return arrowTypeInt, fbTable{fbInt32(c.bitSize), fbBool(!c.unsigned)}
+	case kdFloat:
+		if c.bitSize == 32 {
+			return arrowTypeFloatingPoint, fbTable{fbInt16(arrowPrecisionSingle)}
+		}
+		return arrowTypeFloatingPoint, fbTable{fbInt16(arrowPrecisionDouble)}
+	case kdDate:
+		return arrowTypeDate, fbTable{fbInt16(arrowDateUnitDay)}
+	case kdTime:
+		return arrowTypeTime, fbTable{fbInt16(arrowTimeUnitSecond), fbInt32(32)}
+	case kdTimestamp:
+		return arrowTypeTimestamp, fbTable{fbInt16(arrowTimeUnitMicro), fbString(arrowTimestampTZ)}
+	case kdBytes, kdBinaryLob:
+		return arrowTypeBinary, fbTable{}
+	default: // strings, decimals, character based lobs
+		return arrowTypeUtf8, fbTable{}
+	}
+}
+
+// arrowColumn collects the values of a column for a record batch.
+type arrowColumn struct {
+	col       *column
+	validity  []byte
+	nullCount int
+	values    []byte // fixed size values, bit packed booleans or variable size data
+	offsets   []byte // variable size values only
+	buf       bytes.Buffer
+}
+
+func (c *arrowColumn) isVarSize() bool {
+	switch c.col.kind {
+	case kdBool, kdInt, kdFloat, kdDate, kdTime, kdTimestamp:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *arrowColumn) reset() {
+	c.validity = c.validity[:0]
+	c.nullCount = 0
+	c.values = c.values[:0]
+	c.offsets = c.offsets[:0]
+	if c.isVarSize() {
+		c.offsets = binary.LittleEndian.AppendUint32(c.offsets, 0)
+	}
+}
+
+func setBit(b []byte, i int, v bool) []byte {
+	if i%8 == 0 {
+		b = append(b, 0)
+	}
+	if v {
+		b[i/8] |= 1 << (i % 8)
+	}
+	return b
+}
+
+func (c *arrowColumn) append(i int, f *field) error {
+	valid := f.v != nil
+	c.validity = setBit(c.validity, i, valid)
+	if !valid {
+		c.nullCount++
+	}
+
+	switch c.col.kind {
+	case kdBool:
+		v, _ := f.v.(bool)
+		c.values = setBit(c.values, i, v)
+	case kdInt:
+		v, _ := f.v.(int64)
+		switch c.col.bitSize {
+		case 8:
+			c.values = append(c.values, byte(v))
+		case 16:
+			c.values = binary.LittleEndian.AppendUint16(c.values, uint16(v))
+		case 32:
+			c.values = binary.LittleEndian.AppendUint32(c.values, uint32(v))
+		default:
+			c.values = binary.LittleEndian.AppendUint64(c.values, uint64(v))
+		}
+	case kdFloat:
+		v, _ := f.v.(float64)
+		if c.col.bitSize == 32 {
+			c.values = binary.LittleEndian.AppendUint32(c.values, math.Float32bits(float32(v)))
+		} else {
+			c.values = binary.LittleEndian.AppendUint64(c.values, math.Float64bits(v))
+		}
+	case kdDate:
+		var days int32
+		if v, ok := f.v.(time.Time); ok {
+			secs := v.Unix() // time.Sub saturates for dates more than 292 years away from the epoch
+			days = int32(secs / secondsPerDay)
+			if secs%secondsPerDay < 0 {
+				days-- // floor division for dates before the epoch
+			}
+		}
+		c.values = binary.LittleEndian.AppendUint32(c.values, uint32(days))
+	case kdTime:
+		var secs int32
+		if v, ok := f.v.(time.Time); ok {
+			secs = int32(v.Hour()*3600 + v.Minute()*60 + v.Second())
+		}
+		c.values = binary.LittleEndian.AppendUint32(c.values, uint32(secs))
+	case kdTimestamp:
+		var micros int64
+		if v, ok := f.v.(time.Time); ok {
+			micros = v.Unix()*1e6 + int64(v.Nanosecond()/1e3) // time.UnixMicro overflows for year 1
+		}
+		c.values = binary.LittleEndian.AppendUint64(c.values, uint64(micros))
+	default: // variable size
+		if valid {
+			if err := c.appendVarSize(f); err != nil {
+				return err
+			}
+		}
+		if len(c.values) > math.MaxInt32 {
+			return fmt.Errorf("column %s: record batch data exceeds maximum size %d - decrease batch size", c.col.name, math.MaxInt32)
+		}
+		c.offsets = binary.LittleEndian.AppendUint32(c.offsets, uint32(len(c.values)))
+	}
+	return nil
+}
+
+func (c *arrowColumn) appendVarSize(f *field) error {
+	switch v := f.v.(type) {
+	case []byte:
+		c.values = append(c.values, v...)
+	case string:
+		c.values = append(c.values, v...)
+	case *big.Rat:
+		c.values = append(c.values, formatDecimal(v, c.col.scale)...)
+	case time.Time, bool, int64, float64:
+		s, err := c.col.text(v)
+		if err != nil {
+			return err
+		}
+		c.values = append(c.values, s...)
+	default: // lob
+		c.buf.Reset()
+		if err := f.writeLob(&c.buf); err != nil {
+			return err
+		}
+		c.values = append(c.values, c.buf.Bytes()...)
+	}
+	return nil
+}
+
+// buffers returns the arrow buffers of the column.
+func (c *arrowColumn) buffers() [][]byte {
+	if c.isVarSize() {
+		return [][]byte{c.validity, c.offsets, c.values}
+	}
+	return [][]byte{c.validity, c.values}
+}
+
+// arrowEncoder writes the Arrow IPC stream format: a schema message followed by record batch messages and an end-of-stream marker.
+type arrowEncoder struct {
+	wr        *bufio.Writer
+	cols      []*arrowColumn
+	batchSize int
+	numRow    int
+}
+
+func newArrowEncoder(wr *bufio.Writer, cols []*column, batchSize int) *arrowEncoder {
+	e := &arrowEncoder{wr: wr, cols: make([]*arrowColumn, len(cols)), batchSize: batchSize}
+	for i, c := range cols {
+		e.cols[i] = &arrowColumn{col: c}
+		e.cols[i].reset()
+	}
+	return e
+}
+
+func (e *arrowEncoder) writeUint32(v uint32) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], v)
+	e.wr.Write(b[:])
+}
+
+// writeMessage writes an encapsulated message (continuation, metadata size, metadata, body).
+func (e *arrowEncoder) writeMessage(headerType fbUint8, header fbTable, body [][]byte, bodyLength int64) error {
+	enc := &fbEncoder{}
+	meta := enc.finish(fbTable{fbInt16(arrowMetadataV5), headerType, header, fbInt64(bodyLength)})
+
+	e.writeUint32(arrowContinuation)
+	e.writeUint32(uint32(len(meta)))
+	e.wr.Write(meta)
+	var padding [arrowAlignment]byte
+	for _, b := range body {
+		e.wr.Write(b)
+		e.wr.Write(padding[:padBytes(len(b))])
+	}
+	return nil
+}
+
+func padBytes(size int) int {
+	if r := size % arrowAlignment; r != 0 {
+		return arrowAlignment - r
+	}
+	return 0
+}
+
+func (e *arrowEncoder) begin() error {
+	fields := make(fbTableVector, len(e.cols))
+	for i, c := range e.cols {
+		typeType, typ := c.col.arrowType()
+		fields[i] = fbTable{fbString(c.col.name), fbBool(c.col.nullable), typeType, typ, nil, fbTableVector{}}
+	}
+	return e.writeMessage(arrowHeaderSchema, fbTable{fbInt16(0), fields}, nil, 0) // little endian
+}
+
+func (e *arrowEncoder) row(fields []field) error {
+	for i, c := range e.cols {
+		if err := c.append(e.numRow, &fields[i]); err != nil {
+			return err
+		}
+	}
+	e.numRow++
+	if e.numRow >= e.batchSize {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *arrowEncoder) end() error {
+	if e.numRow > 0 {
+		if err := e.flush(); err != nil {
+			return err
+		}
+	}
+	e.writeUint32(arrowContinuation)
+	e.writeUint32(0) // end-of-stream
+	return nil
+}
+
+// flush writes a record batch message.
+func (e *arrowEncoder) flush() error {
+	nodes := &fbStructVector{size: arrowFieldNodeSize, align: arrowStructAlignement}
+	buffers := &fbStructVector{size: arrowBufferSize, align: arrowStructAlignement}
+	var body [][]byte
+	var ofs int64
+
+	for _, c := range e.cols {
+		nodes.b = binary.LittleEndian.AppendUint64(nodes.b, uint64(e.numRow))
+		nodes.b = binary.LittleEndian.AppendUint64(nodes.b, uint64(c.nullCount))
+		for _, b := range c.buffers() {
+			buffers.b = binary.LittleEndian.AppendUint64(buffers.b, uint64(ofs))
+			buffers.b = binary.LittleEndian.AppendUint64(buffers.b, uint64(len(b)))
+			body = append(body, b)
+			ofs += int64(len(b) + padBytes(len(b)))
+		}
+	}
+
+	header := fbTable{fbInt64(e.numRow), nodes, buffers}
+	if err := e.writeMessage(arrowHeaderRecordBatch, header, body, ofs); err != nil {
+		return err
+	}
+
+	for _, c := range e.cols {
+		c.reset()
+	}
+	e.numRow = 0
+	return nil
+}
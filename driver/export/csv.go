@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"bufio"
+	"encoding/base64"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+csvEncoder writes records in the format of encoding/csv.Writer with the following additions
+- NULL values are written as empty fields, whereas empty strings and empty binary values are written as empty quoted fields ("")
+- large objects are streamed to the output
+*/
+type csvEncoder struct {
+	wr     *bufio.Writer
+	cols   []*column
+	comma  rune
+	header bool
+}
+
+func newCSVEncoder(wr *bufio.Writer, cols []*column, comma rune, header bool) *csvEncoder {
+	return &csvEncoder{wr: wr, cols: cols, comma: comma, header: header}
+}
+
+func (e *csvEncoder) begin() error {
+	if !e.header {
+		return nil
+	}
+	for i, c := range e.cols {
+		if i > 0 {
+			e.wr.WriteRune(e.comma)
+		}
+		e.writeField(c.name)
+	}
+	return e.wr.WriteByte('\n')
+}
+
+func (e *csvEncoder) end() error { return nil }
+
+func (e *csvEncoder) row(fields []field) error {
+	for i, c := range e.cols {
+		if i > 0 {
+			e.wr.WriteRune(e.comma)
+		}
+		f := &fields[i]
+		if f.v == nil {
+			continue
+		}
+		switch c.kind {
+		case kdCharLob:
+			e.wr.WriteByte('"')
+			if err := f.writeLob(&csvQuoteWriter{wr: e.wr}); err != nil {
+				return err
+			}
+			e.wr.WriteByte('"')
+		case kdBinaryLob:
+			cw := &csvCountWriter{wr: e.wr}
+			enc := base64.NewEncoder(base64.StdEncoding, cw)
+			if err := f.writeLob(enc); err != nil {
+				return err
+			}
+			if err := enc.Close(); err != nil {
+				return err
+			}
+			if cw.n == 0 {
+				e.wr.WriteString(`""`)
+			}
+		case kdBytes:
+			b, _ := f.v.([]byte)
+			if len(b) == 0 {
+				e.wr.WriteString(`""`)
+				continue
+			}
+			e.wr.WriteString(base64.StdEncoding.EncodeToString(b))
+		default:
+			s, err := c.text(f.v)
+			if err != nil {
+				return err
+			}
+			if s == "" {
+				e.wr.WriteString(`""`)
+				continue
+			}
+			e.writeField(s)
+		}
+	}
+	return e.wr.WriteByte('\n')
+}
+
+func (e *csvEncoder) writeField(s string) {
+	if !e.fieldNeedsQuotes(s) {
+		e.wr.WriteString(s)
+		return
+	}
+	e.wr.WriteByte('"')
+	e.wr.WriteString(strings.ReplaceAll(s, `"`, `""`))
+	e.wr.WriteByte('"')
+}
+
+// fieldNeedsQuotes mirrors the encoding/csv quoting rules.
+func (e *csvEncoder) fieldNeedsQuotes(s string) bool {
+	if s == "" {
+		return false
+	}
+	if s == `\.` || strings.ContainsRune(s, e.comma) || strings.ContainsAny(s, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+// csvQuoteWriter doubles quotes of the content written to a quoted csv field.
+type csvQuoteWriter struct {
+	wr *bufio.Writer
+}
+
+func (w *csvQuoteWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b == '"' {
+			w.wr.WriteByte('"')
+		}
+		if err := w.wr.WriteByte(b); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// csvCountWriter counts the bytes written to a csv field.
+type csvCountWriter struct {
+	wr *bufio.Writer
+	n  int
+}
+
+func (w *csvCountWriter) Write(p []byte) (int, error) {
+	n, err := w.wr.Write(p)
+	w.n += n
+	return n, err
+}
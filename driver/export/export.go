@@ -0,0 +1,352 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package export implements the export of query results to CSV, JSON Lines and Apache Arrow IPC stream format.
+
+The column types of the result set are derived from the go-hdb result field metadata:
+  - decimal values are exported as exact decimal strings,
+  - timestamps are exported in RFC 3339 format (Arrow: timestamp with microsecond unit and UTC time zone),
+  - dates and times are exported as RFC 3339 full-date and partial-time (Arrow: date32 and time32),
+  - binary values are exported base64 encoded (Arrow: binary).
+
+Large objects are streamed to the output in case of CSV and JSON Lines format. As the Arrow IPC format needs to know
+the size of all values of a record batch in advance, large objects are buffered per record batch in case of
+Arrow IPC format.
+*/
+package export
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// Format is the type of the supported export formats.
+type Format int
+
+// Export format constants.
+const (
+	FormatCSV       Format = iota // Comma separated values.
+	FormatJSONLines               // JSON Lines (one JSON object per row).
+	FormatArrow                   // Apache Arrow IPC stream format.
+)
+
+var formatText = map[Format]string{
+	FormatCSV:       "csv",
+	FormatJSONLines: "jsonl",
+	FormatArrow:     "arrow",
+}
+
+func (f Format) String() string {
+	if s, ok := formatText[f]; ok {
+		return s
+	}
+	return fmt.Sprintf("format(%d)", f)
+}
+
+// ParseFormat returns the format with name s (csv, jsonl or arrow).
+func ParseFormat(s string) (Format, error) {
+	for f, text := range formatText {
+		if strings.EqualFold(s, text) {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid export format %s", s)
+}
+
+// default values.
+const (
+	defaultComma     = ','
+	defaultBatchSize = 1024
+)
+
+// Options are the export options.
+type Options struct {
+	Format    Format
+	Header    bool // CSV only: write column names as first record.
+	Comma     rune // CSV only: field delimiter (default ',').
+	BatchSize int  // Arrow only: number of rows per record batch (default 1024).
+}
+
+func (o *Options) comma() rune {
+	if o.Comma == 0 {
+		return defaultComma
+	}
+	return o.Comma
+}
+
+func (o *Options) batchSize() int {
+	if o.BatchSize <= 0 {
+		return defaultBatchSize
+	}
+	return o.BatchSize
+}
+
+// A Queryer is implemented by sql.DB, sql.Conn and sql.Tx.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// Query executes the query and writes the result to w. It returns the number of exported rows.
+func Query(ctx context.Context, q Queryer, w io.Writer, opts *Options, query string, args ...interface{}) (int64, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	return Rows(w, rows, opts)
+}
+
+// Rows writes the rows to w. It returns the number of exported rows.
+// Rows does not close the rows.
+func Rows(w io.Writer, rows *sql.Rows, opts *Options) (int64, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	cts, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	cols := make([]*column, len(cts))
+	for i, ct := range cts {
+		cols[i] = newColumn(ct)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	var enc encoder
+	switch opts.Format {
+	case FormatCSV:
+		enc = newCSVEncoder(bw, cols, opts.comma(), opts.Header)
+	case FormatJSONLines:
+		enc = newJSONLinesEncoder(bw, cols)
+	case FormatArrow:
+		enc = newArrowEncoder(bw, cols, opts.batchSize())
+	default:
+		return 0, fmt.Errorf("invalid export format %s", opts.Format)
+	}
+
+	fields := make([]field, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range fields {
+		dest[i] = &fields[i]
+	}
+
+	if err := enc.begin(); err != nil {
+		return 0, err
+	}
+	var numRow int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return numRow, err
+		}
+		if err := enc.row(fields); err != nil {
+			return numRow, err
+		}
+		numRow++
+	}
+	if err := rows.Err(); err != nil {
+		return numRow, err
+	}
+	if err := enc.end(); err != nil {
+		return numRow, err
+	}
+	return numRow, bw.Flush()
+}
+
+// encoder is the interface implemented by the format specific encoders.
+type encoder interface {
+	begin() error
+	row(fields []field) error
+	end() error
+}
+
+// field is the scan destination of a result column. It keeps the driver value 'as is' so that
+// large objects can be read after the row is scanned.
+type field struct {
+	v interface{}
+}
+
+// Scan implements the database/sql/Scanner interface.
+func (f *field) Scan(src interface{}) error { f.v = src; return nil }
+
+// writeLob writes the content of a large object to wr.
+func (f *field) writeLob(wr io.Writer) error { return driver.NewLob(nil, wr).Scan(f.v) }
+
+// kind classifies the result columns.
+type kind int
+
+const (
+	kdString kind = iota
+	kdBytes
+	kdBool
+	kdInt
+	kdFloat
+	kdDecimal
+	kdDate
+	kdTime
+	kdTimestamp
+	kdCharLob
+	kdBinaryLob
+)
+
+var (
+	lobType     = reflect.TypeOf((*driver.Lob)(nil)).Elem()
+	decimalType = reflect.TypeOf((*driver.Decimal)(nil)).Elem()
+)
+
+// maxDecimalScale is the maximum scale of fixed decimal columns (floating point decimals report a greater scale).
+const maxDecimalScale = 38
+
+type column struct {
+	name     string
+	typeName string
+	kind     kind
+	bitSize  int  // kdInt, kdFloat
+	unsigned bool // kdInt
+	scale    int  // kdDecimal: -1 for floating point decimals
+	nullable bool
+}
+
+func newColumn(ct *sql.ColumnType) *column {
+	c := &column{name: ct.Name(), typeName: ct.DatabaseTypeName(), scale: -1}
+	c.nullable, _ = ct.Nullable()
+
+	switch ct.ScanType() {
+	case lobType:
+		switch c.typeName {
+		case "CLOB", "NCLOB", "TEXT", "NLOCATOR":
+			c.kind = kdCharLob
+		default:
+			c.kind = kdBinaryLob
+		}
+		return c
+	case decimalType:
+		c.kind = kdDecimal
+		if _, scale, ok := ct.DecimalSize(); ok && scale >= 0 && scale <= maxDecimalScale {
+			c.scale = int(scale)
+		}
+		return c
+	}
+
+	switch c.typeName {
+	case "BOOLEAN":
+		c.kind = kdBool
+	case "TINYINT":
+		c.kind, c.bitSize, c.unsigned = kdInt, 8, true
+	case "SMALLINT":
+		c.kind, c.bitSize = kdInt, 16
+	case "INTEGER":
+		c.kind, c.bitSize = kdInt, 32
+	case "BIGINT":
+		c.kind, c.bitSize = kdInt, 64
+	case "REAL":
+		c.kind, c.bitSize = kdFloat, 32
+	case "DOUBLE":
+		c.kind, c.bitSize = kdFloat, 64
+	case "DATE", "DAYDATE":
+		c.kind = kdDate
+	case "TIME", "SECONDTIME":
+		c.kind = kdTime
+	case "TIMESTAMP", "LONGDATE", "SECONDDATE":
+		c.kind = kdTimestamp
+	case "BINARY", "VARBINARY":
+		c.kind = kdBytes
+	default:
+		c.kind = kdString
+	}
+	return c
+}
+
+// Formats used for date, time and timestamp values.
+const (
+	dateFormat      = "2006-01-02"
+	timeFormat      = "15:04:05"
+	timestampFormat = time.RFC3339Nano
+)
+
+// text returns the text representation of non large object and non binary values.
+func (c *column) text(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		bitSize := c.bitSize
+		if bitSize == 0 {
+			bitSize = 64
+		}
+		return strconv.FormatFloat(v, 'g', -1, bitSize), nil
+	case *big.Rat:
+		return formatDecimal(v, c.scale), nil
+	case time.Time:
+		switch c.kind {
+		case kdDate:
+			return v.Format(dateFormat), nil
+		case kdTime:
+			return v.Format(timeFormat), nil
+		default:
+			return v.Format(timestampFormat), nil
+		}
+	default:
+		return "", fmt.Errorf("column %s: unsupported value type %T", c.name, v)
+	}
+}
+
+// formatDecimal returns the exact decimal representation of r.
+// For scale >= 0 at least scale fractional digits are returned.
+func formatDecimal(r *big.Rat, scale int) string {
+	prec := scale
+	if n := decimalDigits(r.Denom()); n > prec {
+		prec = n
+	}
+	if prec < 0 {
+		prec = 0
+	}
+	return r.FloatString(prec)
+}
+
+var (
+	bigTwo  = big.NewInt(2)
+	bigFive = big.NewInt(5)
+)
+
+// decimalDigits returns the number of fractional digits needed to represent a fraction with denominator d exactly.
+// As decimal database values are of form m*10^e the denominator is always of form 2^x*5^y.
+func decimalDigits(d *big.Int) int {
+	n := new(big.Int).Set(d)
+	m := new(big.Int)
+	count := func(f *big.Int) int {
+		i := 0
+		for {
+			q, r := new(big.Int).QuoRem(n, f, m)
+			if r.Sign() != 0 {
+				return i
+			}
+			n = q
+			i++
+		}
+	}
+	twos, fives := count(bigTwo), count(bigFive)
+	if twos > fives {
+		return twos
+	}
+	return fives
+}
@@ -0,0 +1,236 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestFormatDecimal(t *testing.T) {
+	testData := []struct {
+		r     *big.Rat
+		scale int
+		s     string
+	}{
+		{big.NewRat(0, 1), -1, "0"},
+		{big.NewRat(1, 1), 2, "1.00"},
+		{big.NewRat(3, 2), -1, "1.5"},
+		{big.NewRat(3, 2), 3, "1.500"},
+		{big.NewRat(-1, 8), -1, "-0.125"},
+		{big.NewRat(1, 1000000), 0, "0.000001"},
+		{big.NewRat(12345, 100), 2, "123.45"},
+	}
+
+	for i, d := range testData {
+		if s := formatDecimal(d.r, d.scale); s != d.s {
+			t.Fatalf("%d value %s scale %d - got %s - expected %s", i, d.r, d.scale, s, d.s)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, f := range []Format{FormatCSV, FormatJSONLines, FormatArrow} {
+		pf, err := ParseFormat(f.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pf != f {
+			t.Fatalf("format %s - got %s", f, pf)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Fatal("expected invalid format error")
+	}
+}
+
+var testTime = time.Date(2022, 3, 4, 5, 6, 7, 8000, time.UTC)
+
+var testColumns = []*column{
+	{name: "S", kind: kdString, scale: -1, nullable: true},
+	{name: "I", kind: kdInt, bitSize: 32, scale: -1, nullable: true},
+	{name: "F", kind: kdFloat, bitSize: 64, scale: -1},
+	{name: "B", kind: kdBytes, scale: -1},
+	{name: "D", kind: kdDecimal, scale: 2},
+	{name: "TS", kind: kdTimestamp, scale: -1},
+}
+
+func testRows() [][]field {
+	return [][]field{
+		{{"a,\"b\"\n"}, {int64(-1)}, {1.5}, {[]byte{0, 1, 2}}, {big.NewRat(1, 4)}, {testTime}},
+		{{""}, {nil}, {2.0}, {[]byte{}}, {big.NewRat(-3, 1)}, {testTime}},
+		{{nil}, {int64(42)}, {0.0}, {[]byte("xyz")}, {big.NewRat(1, 1)}, {testTime}},
+	}
+}
+
+func testEncode(t *testing.T, newEncoder func(wr *bufio.Writer) encoder) []byte {
+	var b bytes.Buffer
+	wr := bufio.NewWriter(&b)
+	enc := newEncoder(wr)
+	if err := enc.begin(); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range testRows() {
+		if err := enc.row(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := enc.end(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wr.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	const expected = `S;I;F;B;D;TS
+"a,""b""
+";-1;1.5;AAEC;0.25;2022-03-04T05:06:07.000008Z
+"";;2;"";-3.00;2022-03-04T05:06:07.000008Z
+;42;0;eHl6;1.00;2022-03-04T05:06:07.000008Z
+`
+	b := testEncode(t, func(wr *bufio.Writer) encoder { return newCSVEncoder(wr, testColumns, ';', true) })
+	if string(b) != expected {
+		t.Fatalf("got\n%s\nexpected\n%s", b, expected)
+	}
+}
+
+// testLob provides lob content the way lob descriptors of a result set do.
+type testLob []byte
+
+func (l testLob) SetWriter(wr io.Writer) error {
+	_, err := wr.Write(l)
+	return err
+}
+
+func TestCSVLob(t *testing.T) {
+	const expected = "C,BL\n\"a\"\"b\",AAEC\n\"\",\"\"\n,\n"
+
+	cols := []*column{
+		{name: "C", kind: kdCharLob, scale: -1, nullable: true},
+		{name: "BL", kind: kdBinaryLob, scale: -1, nullable: true},
+	}
+	var b bytes.Buffer
+	wr := bufio.NewWriter(&b)
+	enc := newCSVEncoder(wr, cols, ',', true)
+	if err := enc.begin(); err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range [][]field{
+		{{testLob(`a"b`)}, {testLob{0, 1, 2}}},
+		{{testLob{}}, {testLob{}}},
+		{{nil}, {nil}},
+	} {
+		if err := enc.row(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wr.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != expected {
+		t.Fatalf("got\n%s\nexpected\n%s", b.String(), expected)
+	}
+}
+
+func TestJSONLines(t *testing.T) {
+	const expected = `{"S":"a,\"b\"\n","I":-1,"F":1.5,"B":"AAEC","D":"0.25","TS":"2022-03-04T05:06:07.000008Z"}
+{"S":"","I":null,"F":2,"B":"","D":"-3.00","TS":"2022-03-04T05:06:07.000008Z"}
+{"S":null,"I":42,"F":0,"B":"eHl6","D":"1.00","TS":"2022-03-04T05:06:07.000008Z"}
+`
+	b := testEncode(t, func(wr *bufio.Writer) encoder { return newJSONLinesEncoder(wr, testColumns) })
+	if string(b) != expected {
+		t.Fatalf("got\n%s\nexpected\n%s", b, expected)
+	}
+}
+
+func TestArrow(t *testing.T) {
+	b := testEncode(t, func(wr *bufio.Writer) encoder { return newArrowEncoder(wr, testColumns, 2) })
+
+	// expected messages: schema, two record batches and end-of-stream marker.
+	var headerTypes []byte
+	for len(b) != 0 {
+		if len(b) < 8 || binary.LittleEndian.Uint32(b) != arrowContinuation {
+			t.Fatal("invalid message continuation")
+		}
+		metaSize := int(binary.LittleEndian.Uint32(b[4:]))
+		b = b[8:]
+		if metaSize == 0 {
+			break
+		}
+		if metaSize%arrowAlignment != 0 {
+			t.Fatalf("invalid metadata size %d", metaSize)
+		}
+		meta := b[:metaSize]
+		// root table -> vtable -> fields version (0), header type (1) and body length (3)
+		root := int(binary.LittleEndian.Uint32(meta))
+		vt := root - int(int32(binary.LittleEndian.Uint32(meta[root:])))
+		fieldPos := func(i int) int { return root + int(binary.LittleEndian.Uint16(meta[vt+4+2*i:])) }
+		if version := binary.LittleEndian.Uint16(meta[fieldPos(0):]); version != arrowMetadataV5 {
+			t.Fatalf("invalid metadata version %d", version)
+		}
+		headerTypes = append(headerTypes, meta[fieldPos(1)])
+		bodyLength := int(binary.LittleEndian.Uint64(meta[fieldPos(3):]))
+		if bodyLength%arrowAlignment != 0 {
+			t.Fatalf("invalid body length %d", bodyLength)
+		}
+		b = b[metaSize+bodyLength:]
+	}
+	if len(b) != 0 {
+		t.Fatalf("%d bytes after end-of-stream marker", len(b))
+	}
+	if !bytes.Equal(headerTypes, []byte{arrowHeaderSchema, arrowHeaderRecordBatch, arrowHeaderRecordBatch}) {
+		t.Fatalf("invalid message header types %v", headerTypes)
+	}
+}
+
+func TestArrowColumnValues(t *testing.T) {
+	testData := []struct {
+		col     *column
+		v       interface{}
+		decoded int64
+	}{
+		{&column{kind: kdInt, bitSize: 16}, int64(-2), -2},
+		{&column{kind: kdDate}, time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC), 1},
+		{&column{kind: kdDate}, time.Date(1969, 12, 31, 12, 0, 0, 0, time.UTC), -1},
+		{&column{kind: kdDate}, time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), -719162},
+		{&column{kind: kdDate}, time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), 2932896},
+		{&column{kind: kdTime}, time.Date(1, 1, 1, 23, 59, 58, 0, time.UTC), 86398},
+		{&column{kind: kdTimestamp}, time.Date(1, 1, 1, 0, 0, 0, 1000, time.UTC), -62135596800000000 + 1},
+		{&column{kind: kdTimestamp}, time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.UTC), 253402300799999999},
+	}
+
+	for i, d := range testData {
+		c := &arrowColumn{col: d.col}
+		c.reset()
+		if err := c.append(0, &field{d.v}); err != nil {
+			t.Fatal(err)
+		}
+		var decoded int64
+		switch len(c.values) {
+		case 2:
+			decoded = int64(int16(binary.LittleEndian.Uint16(c.values)))
+		case 4:
+			decoded = int64(int32(binary.LittleEndian.Uint32(c.values)))
+		case 8:
+			decoded = int64(binary.LittleEndian.Uint64(c.values))
+		default:
+			t.Fatalf("%d: invalid value size %d", i, len(c.values))
+		}
+		if decoded != d.decoded {
+			t.Fatalf("%d: value %v - got %d - expected %d", i, d.v, decoded, d.decoded)
+		}
+		if c.validity[0] != 1 || c.nullCount != 0 {
+			t.Fatalf("%d: invalid validity %v null count %d", i, c.validity, c.nullCount)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"encoding/binary"
+)
+
+/*
+Minimal flatbuffers encoder used to write the Arrow IPC message metadata.
+
+The encoder builds the buffer front to back:
+- vtables are written directly in front of their tables (signed offset)
+- referenced objects (strings, vectors, tables) are written after the referencing table (unsigned forward offset)
+All scalars are aligned relative to the buffer start.
+*/
+
+// fbTable is a flatbuffers table. The slice index is the field id, nil values are absent fields.
+type fbTable []interface{}
+
+// flatbuffers field value types.
+type (
+	fbBool         bool
+	fbUint8        uint8
+	fbInt16        int16
+	fbInt32        int32
+	fbInt64        int64
+	fbString       string
+	fbTableVector  []fbTable
+	fbStructVector struct {
+		size, align int // struct size and alignment
+		b           []byte
+	}
+)
+
+// scalarSize returns the inline size of a field value (offsets: 4 bytes).
+func scalarSize(v interface{}) int {
+	switch v.(type) {
+	case fbBool, fbUint8:
+		return 1
+	case fbInt16:
+		return 2
+	case fbInt64:
+		return 8
+	default: // fbInt32 and offsets
+		return 4
+	}
+}
+
+type fbEncoder struct {
+	b []byte
+}
+
+// finish encodes the root table and returns the buffer padded to a multiple of 8 bytes.
+func (e *fbEncoder) finish(root fbTable) []byte {
+	e.b = make([]byte, 4, 256) // root offset
+	pos := e.table(root)
+	binary.LittleEndian.PutUint32(e.b, uint32(pos))
+	e.pad(8)
+	return e.b
+}
+
+func (e *fbEncoder) pad(align int) {
+	for len(e.b)%align != 0 {
+		e.b = append(e.b, 0)
+	}
+}
+
+func (e *fbEncoder) reserve(n int) int {
+	pos := len(e.b)
+	e.b = append(e.b, make([]byte, n)...)
+	return pos
+}
+
+func (e *fbEncoder) putUint32(pos int, v uint32) { binary.LittleEndian.PutUint32(e.b[pos:], v) }
+
+func (e *fbEncoder) table(t fbTable) int {
+	// inline layout (biggest fields first to minimize padding)
+	ofs := make([]int, len(t))
+	size, maxAlign := 4, 4 // vtable offset
+	for _, fieldSize := range []int{8, 4, 2, 1} {
+		for i, v := range t {
+			if v == nil || scalarSize(v) != fieldSize {
+				continue
+			}
+			size = (size + fieldSize - 1) / fieldSize * fieldSize
+			ofs[i] = size
+			size += fieldSize
+			if fieldSize > maxAlign {
+				maxAlign = fieldSize
+			}
+		}
+	}
+
+	// vtable
+	e.pad(2)
+	vtPos := e.reserve(4 + 2*len(t))
+	binary.LittleEndian.PutUint16(e.b[vtPos:], uint16(4+2*len(t)))
+	binary.LittleEndian.PutUint16(e.b[vtPos+2:], uint16(size))
+	for i, o := range ofs {
+		binary.LittleEndian.PutUint16(e.b[vtPos+4+2*i:], uint16(o))
+	}
+
+	// table
+	e.pad(maxAlign)
+	pos := e.reserve(size)
+	e.putUint32(pos, uint32(pos-vtPos)) // signed offset to vtable (vtable in front of table)
+
+	for i, v := range t {
+		fieldPos := pos + ofs[i]
+		switch v := v.(type) {
+		case nil:
+		case fbBool:
+			if v {
+				e.b[fieldPos] = 1
+			}
+		case fbUint8:
+			e.b[fieldPos] = byte(v)
+		case fbInt16:
+			binary.LittleEndian.PutUint16(e.b[fieldPos:], uint16(v))
+		case fbInt32:
+			binary.LittleEndian.PutUint32(e.b[fieldPos:], uint32(v))
+		case fbInt64:
+			binary.LittleEndian.PutUint64(e.b[fieldPos:], uint64(v))
+		default:
+			e.putUint32(fieldPos, uint32(e.object(v)-fieldPos))
+		}
+	}
+	return pos
+}
+
+// object encodes a referenced object and returns its position.
+func (e *fbEncoder) object(v interface{}) int {
+	switch v := v.(type) {
+	case fbTable:
+		return e.table(v)
+	case fbString:
+		e.pad(4)
+		pos := e.reserve(4)
+		e.putUint32(pos, uint32(len(v)))
+		e.b = append(e.b, v...)
+		e.b = append(e.b, 0) // zero terminated
+		return pos
+	case fbTableVector:
+		e.pad(4)
+		pos := e.reserve(4 + 4*len(v))
+		e.putUint32(pos, uint32(len(v)))
+		for i, t := range v {
+			elemPos := pos + 4 + 4*i
+			e.putUint32(elemPos, uint32(e.table(t)-elemPos))
+		}
+		return pos
+	case *fbStructVector:
+		// vector elements need to be aligned - vector length is stored in front of the elements
+		e.pad(4)
+		for (len(e.b)+4)%v.align != 0 {
+			e.b = append(e.b, 0)
+		}
+		pos := e.reserve(4)
+		e.putUint32(pos, uint32(len(v.b)/v.size))
+		e.b = append(e.b, v.b...)
+		return pos
+	default:
+		panic("invalid flatbuffers value") // should never happen
+	}
+}
@@ -0,0 +1,154 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package export
+
+import (
+	"bufio"
+	"encoding/base64"
+	"math"
+)
+
+// jsonLinesEncoder writes one JSON object per row. The object keys are the column names in result set order.
+type jsonLinesEncoder struct {
+	wr   *bufio.Writer
+	cols []*column
+	keys []string
+}
+
+func newJSONLinesEncoder(wr *bufio.Writer, cols []*column) *jsonLinesEncoder {
+	e := &jsonLinesEncoder{wr: wr, cols: cols, keys: make([]string, len(cols))}
+	for i, c := range cols {
+		e.keys[i] = jsonQuote(c.name)
+	}
+	return e
+}
+
+func (e *jsonLinesEncoder) begin() error { return nil }
+
+func (e *jsonLinesEncoder) end() error { return nil }
+
+func (e *jsonLinesEncoder) row(fields []field) error {
+	e.wr.WriteByte('{')
+	for i, c := range e.cols {
+		if i > 0 {
+			e.wr.WriteByte(',')
+		}
+		e.wr.WriteString(e.keys[i])
+		e.wr.WriteByte(':')
+		if err := e.writeValue(c, &fields[i]); err != nil {
+			return err
+		}
+	}
+	e.wr.WriteByte('}')
+	return e.wr.WriteByte('\n')
+}
+
+func (e *jsonLinesEncoder) writeValue(c *column, f *field) error {
+	if f.v == nil {
+		e.wr.WriteString("null")
+		return nil
+	}
+	switch c.kind {
+	case kdCharLob:
+		e.wr.WriteByte('"')
+		if err := f.writeLob(&jsonStringWriter{wr: e.wr}); err != nil {
+			return err
+		}
+		return e.wr.WriteByte('"')
+	case kdBinaryLob:
+		e.wr.WriteByte('"')
+		enc := base64.NewEncoder(base64.StdEncoding, e.wr)
+		if err := f.writeLob(enc); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		return e.wr.WriteByte('"')
+	case kdBytes:
+		b, _ := f.v.([]byte)
+		e.wr.WriteByte('"')
+		e.wr.WriteString(base64.StdEncoding.EncodeToString(b))
+		return e.wr.WriteByte('"')
+	case kdFloat:
+		if v, ok := f.v.(float64); ok && (math.IsNaN(v) || math.IsInf(v, 0)) {
+			e.wr.WriteString("null") // not representable in json
+			return nil
+		}
+	}
+	s, err := c.text(f.v)
+	if err != nil {
+		return err
+	}
+	switch c.kind {
+	case kdBool, kdInt, kdFloat:
+		e.wr.WriteString(s)
+	default:
+		e.wr.WriteByte('"')
+		(&jsonStringWriter{wr: e.wr}).WriteString(s)
+		e.wr.WriteByte('"')
+	}
+	return nil
+}
+
+const hex = "0123456789abcdef"
+
+// jsonStringWriter escapes the content written to a json string.
+// As multi-byte UTF-8 sequences only consist of bytes >= 0x80 the content can be escaped byte by byte.
+type jsonStringWriter struct {
+	wr *bufio.Writer
+}
+
+func (w *jsonStringWriter) writeByte(b byte) {
+	switch b {
+	case '"', '\\':
+		w.wr.WriteByte('\\')
+		w.wr.WriteByte(b)
+	case '\n':
+		w.wr.WriteString(`\n`)
+	case '\r':
+		w.wr.WriteString(`\r`)
+	case '\t':
+		w.wr.WriteString(`\t`)
+	default:
+		if b < 0x20 {
+			w.wr.WriteString(`\u00`)
+			w.wr.WriteByte(hex[b>>4])
+			w.wr.WriteByte(hex[b&0xF])
+			return
+		}
+		w.wr.WriteByte(b)
+	}
+}
+
+func (w *jsonStringWriter) Write(p []byte) (int, error) {
+	for _, b := range p {
+		w.writeByte(b)
+	}
+	return len(p), nil
+}
+
+func (w *jsonStringWriter) WriteString(s string) {
+	for i := 0; i < len(s); i++ {
+		w.writeByte(s[i])
+	}
+}
+
+// jsonQuote returns s as json string.
+func jsonQuote(s string) string {
+	b := make([]byte, 0, len(s)+2)
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b = append(b, '\\', c)
+		case c < 0x20:
+			b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+		default:
+			b = append(b, c)
+		}
+	}
+	return string(append(b, '"'))
+}
@@ -0,0 +1,79 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// maxAdaptiveFetchSize is the maximum number of rows fetched in adaptive fetch size mode.
+const maxAdaptiveFetchSize = 1 << 15
+
+type fetchSizeCtxKeyType struct{}
+
+var fetchSizeCtxKey fetchSizeCtxKeyType
+
+/*
+WithFetchSize returns a context setting the fetch size (number of rows fetched from the database in one
+round trip) for queries executed with this context. The fetch size set by the context takes precedence over
+the fetch size and the fetch target size of the connector.
+
+Example:
+
+	rows, err := db.QueryContext(driver.WithFetchSize(ctx, 10000), "select * from bigTable")
+*/
+func WithFetchSize(ctx context.Context, fetchSize int) context.Context {
+	if fetchSize < minFetchSize {
+		fetchSize = minFetchSize
+	}
+	return context.WithValue(ctx, fetchSizeCtxKey, fetchSize)
+}
+
+// fetchSizeFromContext returns the fetch size set by WithFetchSize.
+func fetchSizeFromContext(ctx context.Context) (int, bool) {
+	fetchSize, ok := ctx.Value(fetchSizeCtxKey).(int)
+	return fetchSize, ok
+}
+
+// fetchSizer determines the number of rows requested by the next fetch.
+type fetchSizer struct {
+	fetchSize  int // fixed fetch size or last adapted fetch size
+	targetSize int // target reply size in bytes (adaptive mode) - 0: adaptive mode off
+}
+
+func (c *conn) fetchSizer() fetchSizer {
+	return fetchSizer{fetchSize: c.fetchSize, targetSize: c.fetchTargetSize}
+}
+
+// adapt adjusts the fetch size to the measured bytes per row of the last fetch (adaptive mode only).
+func (fs *fetchSizer) adapt(numByte, numRow int) {
+	if fs.targetSize == 0 || numByte <= 0 || numRow <= 0 {
+		return
+	}
+	bytesPerRow := numByte / numRow
+	if bytesPerRow == 0 {
+		bytesPerRow = 1
+	}
+	fetchSize := fs.targetSize / bytesPerRow
+	switch {
+	case fetchSize < minFetchSize:
+		fetchSize = minFetchSize
+	case fetchSize > maxAdaptiveFetchSize:
+		fetchSize = maxAdaptiveFetchSize
+	}
+	fs.fetchSize = fetchSize
+}
+
+// applyFetchSizeContext sets the fetch size of query results in case it is set by the context.
+func applyFetchSizeContext(ctx context.Context, rows driver.Rows) {
+	qr, ok := rows.(*queryResult)
+	if !ok {
+		return
+	}
+	if fetchSize, ok := fetchSizeFromContext(ctx); ok {
+		qr.fetchSizer = fetchSizer{fetchSize: fetchSize}
+	}
+}
@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFetchSizerAdapt(t *testing.T) {
+	testData := []struct {
+		fs              fetchSizer
+		numByte, numRow int
+		fetchSize       int
+	}{
+		{fetchSizer{fetchSize: 128}, 1000, 10, 128},                                      // adaptive mode off
+		{fetchSizer{fetchSize: 128, targetSize: 1 << 20}, 0, 0, 128},                     // nothing measured
+		{fetchSizer{fetchSize: 128, targetSize: 1 << 20}, 1000, 10, (1 << 20) / 100},     // narrow rows
+		{fetchSizer{fetchSize: 128, targetSize: 1 << 20}, 1 << 24, 4, minFetchSize},      // wide rows
+		{fetchSizer{fetchSize: 128, targetSize: 1 << 20}, 10, 100, maxAdaptiveFetchSize}, // very narrow rows
+	}
+
+	for i, d := range testData {
+		d.fs.adapt(d.numByte, d.numRow)
+		if d.fs.fetchSize != d.fetchSize {
+			t.Fatalf("%d: got fetch size %d - expected %d", i, d.fs.fetchSize, d.fetchSize)
+		}
+	}
+}
+
+func TestWithFetchSize(t *testing.T) {
+	ctx := context.Background()
+	if _, ok := fetchSizeFromContext(ctx); ok {
+		t.Fatal("unexpected fetch size in context")
+	}
+	if fetchSize, ok := fetchSizeFromContext(WithFetchSize(ctx, 1000)); !ok || fetchSize != 1000 {
+		t.Fatalf("got fetch size %d - expected %d", fetchSize, 1000)
+	}
+	if fetchSize, _ := fetchSizeFromContext(WithFetchSize(ctx, -1)); fetchSize != minFetchSize {
+		t.Fatalf("got fetch size %d - expected %d", fetchSize, minFetchSize)
+	}
+}
@@ -0,0 +1,202 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/SAP/go-hdb/driver/internal/protocol/scanner"
+)
+
+// DefaultMaxInValues is the default maximum number of values of an in-list argument which
+// ExpandInConn binds as single parameters.
+const DefaultMaxInValues = 1000
+
+var errEmptyInList = errors.New("empty in-list argument")
+
+var (
+	typeOfTime    = reflect.TypeOf((*time.Time)(nil)).Elem()
+	typeOfDecimal = reflect.TypeOf((*Decimal)(nil)).Elem()
+	typeOfRat     = reflect.TypeOf((*big.Rat)(nil)).Elem()
+)
+
+// inListValue returns the reflect value of an in-list argument (slice or array except byte slices and driver.Valuer).
+func inListValue(arg interface{}) (reflect.Value, bool) {
+	if _, ok := arg.(driver.Valuer); ok {
+		return reflect.Value{}, false
+	}
+	rv := reflect.ValueOf(arg)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rv, rv.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return reflect.Value{}, false
+	}
+}
+
+// expandIn rewrites query and args. In-list arguments exceeding maxValues (maxValues > 0) are handled by large,
+// which returns the query text replacing the placeholder.
+func expandIn(query string, args []interface{}, maxValues int, large func(rv reflect.Value) (string, error)) (string, []interface{}, error) {
+	pos, err := scanner.Placeholders(query)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pos) != len(args) {
+		return "", nil, fmt.Errorf("invalid number of arguments %d - expected %d", len(args), len(pos))
+	}
+
+	var b strings.Builder
+	expArgs := make([]interface{}, 0, len(args))
+	last := 0
+	for i, arg := range args {
+		rv, ok := inListValue(arg)
+		if !ok {
+			expArgs = append(expArgs, arg)
+			continue
+		}
+		n := rv.Len()
+		if n == 0 {
+			return "", nil, fmt.Errorf("argument %d: %w", i+1, errEmptyInList)
+		}
+
+		b.WriteString(query[last:pos[i]])
+		last = pos[i] + 1
+
+		if maxValues > 0 && n > maxValues {
+			s, err := large(rv)
+			if err != nil {
+				return "", nil, err
+			}
+			b.WriteString(s)
+			continue
+		}
+		for j := 0; j < n; j++ {
+			if j != 0 {
+				b.WriteString(", ")
+			}
+			b.WriteByte('?')
+			expArgs = append(expArgs, rv.Index(j).Interface())
+		}
+	}
+	if last == 0 { // nothing to expand
+		return query, args, nil
+	}
+	b.WriteString(query[last:])
+	return b.String(), expArgs, nil
+}
+
+// ExpandIn expands slice and array arguments into a list of placeholders, one per element,
+// so that a slice can be bound to an in-list predicate:
+//
+//	query, args, err := driver.ExpandIn("select * from t where id in (?) and kind = ?", []int{1, 2, 3}, "a")
+//	// query: select * from t where id in (?, ?, ?) and kind = ?
+//	// args:  1, 2, 3, "a"
+//
+// Byte slices and arguments implementing the driver.Valuer interface are not expanded.
+// Only question mark placeholders are supported (no :n placeholders). Question marks in comments,
+// string literals and quoted identifiers are not considered as placeholders.
+func ExpandIn(query string, args ...interface{}) (string, []interface{}, error) {
+	return expandIn(query, args, 0, nil)
+}
+
+// inListColumnType returns the database type of the temporary in-list table column.
+func inListColumnType(rv reflect.Value) (string, error) {
+	t := rv.Type().Elem()
+	if t.Kind() == reflect.Interface { // derive type from first element
+		v := rv.Index(0).Elem()
+		if !v.IsValid() {
+			return "", fmt.Errorf("cannot derive in-list type from nil element")
+		}
+		t = v.Type()
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case typeOfTime:
+		return "timestamp", nil
+	case typeOfDecimal, typeOfRat:
+		return "decimal", nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "bigint", nil
+	case reflect.Uint, reflect.Uint64: // value range exceeds bigint
+		return "", fmt.Errorf("unsupported in-list element type %s - value range exceeds bigint", t)
+	case reflect.Float32, reflect.Float64:
+		return "double", nil
+	case reflect.String:
+		return "nvarchar(5000)", nil
+	default:
+		return "", fmt.Errorf("unsupported in-list element type %s", t)
+	}
+}
+
+// ExpandInConn is like ExpandIn but in-list arguments with more than maxValues elements
+// (default DefaultMaxInValues if maxValues <= 0) are inserted into a local temporary table
+// and the placeholder is replaced by a subquery selecting the values of the table:
+//
+//	select * from t where id in (select V from #IN_...)
+//
+// As local temporary tables are only visible within the database session, the rewritten
+// query needs to be executed on conn. The returned drop function drops the temporary tables
+// and should be called after the query execution.
+func ExpandInConn(ctx context.Context, conn *sql.Conn, maxValues int, query string, args ...interface{}) (string, []interface{}, func(ctx context.Context) error, error) {
+	if maxValues <= 0 {
+		maxValues = DefaultMaxInValues
+	}
+
+	var tables []Identifier
+	drop := func(ctx context.Context) error {
+		var firstErr error
+		for _, table := range tables {
+			if _, err := conn.ExecContext(ctx, fmt.Sprintf("drop table %s", table)); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		tables = nil
+		return firstErr
+	}
+
+	large := func(rv reflect.Value) (string, error) {
+		columnType, err := inListColumnType(rv)
+		if err != nil {
+			return "", err
+		}
+		table := RandomIdentifier("#IN_")
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("create local temporary table %s (V %s)", table, columnType)); err != nil {
+			return "", err
+		}
+		tables = append(tables, table)
+
+		stmt, err := conn.PrepareContext(ctx, fmt.Sprintf("insert into %s values (?)", table))
+		if err != nil {
+			return "", err
+		}
+		defer stmt.Close()
+		if _, err := stmt.ExecContext(ctx, rv.Interface()); err != nil { // 'many' insert
+			return "", err
+		}
+		return fmt.Sprintf("select V from %s", table), nil
+	}
+
+	query, args, err := expandIn(query, args, maxValues, large)
+	if err != nil {
+		drop(ctx) // ignore error
+		return "", nil, nil, err
+	}
+	return query, args, drop, nil
+}
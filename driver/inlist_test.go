@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestExpandIn(t *testing.T) {
+	testData := []struct {
+		query    string
+		args     []interface{}
+		expQuery string
+		expArgs  []interface{}
+	}{
+		{"select * from t where a = ?", []interface{}{1}, "select * from t where a = ?", []interface{}{1}},
+		{
+			"select * from t where a in (?) and b = ?", []interface{}{[]int{1, 2, 3}, []byte("x")},
+			"select * from t where a in (?, ?, ?) and b = ?", []interface{}{1, 2, 3, []byte("x")},
+		},
+		{
+			"select '?' from t where a in (?) -- ?", []interface{}{[2]string{"a", "b"}},
+			"select '?' from t where a in (?, ?) -- ?", []interface{}{"a", "b"},
+		},
+		{
+			"select * from t where a in (?) and b in (?)", []interface{}{[]interface{}{1}, []NullBytes{{}, {}}},
+			"select * from t where a in (?) and b in (?, ?)", []interface{}{1, NullBytes{}, NullBytes{}},
+		},
+	}
+
+	for _, d := range testData {
+		query, args, err := ExpandIn(d.query, d.args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if query != d.expQuery {
+			t.Fatalf("query %s - expected %s", query, d.expQuery)
+		}
+		if !reflect.DeepEqual(args, d.expArgs) {
+			t.Fatalf("args %v - expected %v", args, d.expArgs)
+		}
+	}
+
+	if _, _, err := ExpandIn("select * from t where a in (?)", []int{}); !errors.Is(err, errEmptyInList) {
+		t.Fatalf("error %v - expected %v", err, errEmptyInList)
+	}
+	if _, _, err := ExpandIn("select * from t where a in (?)", 1, 2); err == nil {
+		t.Fatal("expected invalid number of arguments error")
+	}
+}
+
+func TestInListColumnType(t *testing.T) {
+	testData := []struct {
+		v          interface{}
+		columnType string
+	}{
+		{[]int{1}, "bigint"},
+		{[]uint32{1}, "bigint"},
+		{[]string{"a"}, "nvarchar(5000)"},
+		{[]interface{}{1.5}, "double"},
+		{[]*Decimal{nil}, "decimal"},
+	}
+	for _, d := range testData {
+		columnType, err := inListColumnType(reflect.ValueOf(d.v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if columnType != d.columnType {
+			t.Fatalf("%T: column type %s - expected %s", d.v, columnType, d.columnType)
+		}
+	}
+	for _, v := range []interface{}{[]struct{}{{}}, []uint64{1}, []interface{}{uint(1)}} {
+		if _, err := inListColumnType(reflect.ValueOf(v)); err == nil {
+			t.Fatalf("%T: expected unsupported type error", v)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package pkcs implements the decryption of PKCS#8 encrypted private keys and PKCS#12 bundles.
+package pkcs
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ErrIncorrectPassphrase is returned if the data cannot be decrypted or verified with the passphrase.
+var ErrIncorrectPassphrase = errors.New("incorrect passphrase")
+
+// ErrUnsupported is returned for unsupported algorithms.
+var ErrUnsupported = errors.New("unsupported algorithm")
+
+var (
+	oidPBES2                         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2                        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+
+	oidSHA1           = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256         = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384         = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512         = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+func unmarshal(in []byte, out interface{}) error {
+	rest, err := asn1.Unmarshal(in, out)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 0 {
+		return errors.New("trailing data found")
+	}
+	return nil
+}
+
+// digest returns the hash function and the block size of a digest algorithm.
+func digest(oid asn1.ObjectIdentifier) (func() hash.Hash, int, error) {
+	switch {
+	case oid.Equal(oidSHA1), oid.Equal(oidHMACWithSHA1), len(oid) == 0: // default prf: hmacWithSHA1
+		return sha1.New, 64, nil
+	case oid.Equal(oidSHA256), oid.Equal(oidHMACWithSHA256):
+		return sha256.New, 64, nil
+	case oid.Equal(oidSHA384), oid.Equal(oidHMACWithSHA384):
+		return sha512.New384, 128, nil
+	case oid.Equal(oidSHA512), oid.Equal(oidHMACWithSHA512):
+		return sha512.New, 128, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: digest %s", ErrUnsupported, oid)
+	}
+}
+
+// blockCipher returns the block cipher constructor and the key size of an encryption scheme.
+func blockCipher(oid asn1.ObjectIdentifier) (func(key []byte) (cipher.Block, error), int, error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return aes.NewCipher, 16, nil
+	case oid.Equal(oidAES192CBC):
+		return aes.NewCipher, 24, nil
+	case oid.Equal(oidAES256CBC):
+		return aes.NewCipher, 32, nil
+	case oid.Equal(oidDESEDE3CBC):
+		return des.NewTripleDESCipher, 24, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: encryption scheme %s", ErrUnsupported, oid)
+	}
+}
+
+// bmpString returns the null terminated BMPString (UTF-16 big endian) of s used as PKCS#12 password.
+func bmpString(s string) []byte {
+	u := utf16.Encode([]rune(s))
+	b := make([]byte, 0, 2*len(u)+2)
+	for _, r := range u {
+		b = append(b, byte(r>>8), byte(r))
+	}
+	return append(b, 0, 0)
+}
+
+// pkcs12KDF implements the PKCS#12 key derivation function (RFC 7292 appendix B.2).
+func pkcs12KDF(h func() hash.Hash, v int, salt, password []byte, iterations int, id byte, size int) []byte {
+	fill := func(b []byte) []byte {
+		if len(b) == 0 {
+			return nil
+		}
+		r := make([]byte, v*((len(b)+v-1)/v))
+		for i := range r {
+			r[i] = b[i%len(b)]
+		}
+		return r
+	}
+
+	d := bytes.Repeat([]byte{id}, v)
+	ib := append(fill(salt), fill(password)...)
+
+	one := big.NewInt(1)
+	var out []byte
+	for len(out) < size {
+		hh := h()
+		hh.Write(d)
+		hh.Write(ib)
+		a := hh.Sum(nil)
+		for i := 1; i < iterations; i++ {
+			hh.Reset()
+			hh.Write(a)
+			a = hh.Sum(a[:0])
+		}
+		out = append(out, a...)
+
+		// I_j = (I_j + B + 1) mod 2^(v*8)
+		b := new(big.Int).SetBytes(fill(a)[:v])
+		b.Add(b, one)
+		for j := 0; j < len(ib); j += v {
+			ij := new(big.Int).SetBytes(ib[j : j+v])
+			ij.Add(ij, b)
+			ijb := ij.Bytes()
+			if len(ijb) > v {
+				ijb = ijb[len(ijb)-v:]
+			}
+			block := ib[j : j+v]
+			for k := range block {
+				block[k] = 0
+			}
+			copy(block[v-len(ijb):], ijb)
+		}
+	}
+	return out[:size]
+}
+
+// decrypt decrypts data encrypted with a PBES2 or the PKCS#12 3DES password based encryption scheme.
+// password is the passphrase for PBES2 and the BMPString password for PKCS#12 schemes.
+func decrypt(alg pkix.AlgorithmIdentifier, data []byte, passphrase string) ([]byte, error) {
+	var block cipher.Block
+	var iv []byte
+
+	switch {
+	case alg.Algorithm.Equal(oidPBES2):
+		var params pbes2Params
+		if err := unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+			return nil, err
+		}
+		if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+			return nil, fmt.Errorf("%w: key derivation function %s", ErrUnsupported, params.KeyDerivationFunc.Algorithm)
+		}
+		var kdfParams pbkdf2Params
+		if err := unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdfParams); err != nil {
+			return nil, err
+		}
+		h, _, err := digest(kdfParams.PRF.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		newCipher, keySize, err := blockCipher(params.EncryptionScheme.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		if err := unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+			return nil, err
+		}
+		key := pbkdf2.Key([]byte(passphrase), kdfParams.Salt, kdfParams.IterationCount, keySize, h)
+		if block, err = newCipher(key); err != nil {
+			return nil, err
+		}
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+		var params pbeParams
+		if err := unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+			return nil, err
+		}
+		password := bmpString(passphrase)
+		key := pkcs12KDF(sha1.New, 64, params.Salt, password, params.Iterations, 1, 24)
+		iv = pkcs12KDF(sha1.New, 64, params.Salt, password, params.Iterations, 2, 8)
+		var err error
+		if block, err = des.NewTripleDESCipher(key); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupported, alg.Algorithm)
+	}
+
+	bs := block.BlockSize()
+	if len(iv) != bs {
+		return nil, fmt.Errorf("invalid initialization vector size %d - expected %d", len(iv), bs)
+	}
+	if len(data) == 0 || len(data)%bs != 0 {
+		return nil, errors.New("invalid encrypted data size")
+	}
+	out := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+
+	// remove PKCS#7 padding
+	n := int(out[len(out)-1])
+	if n == 0 || n > bs {
+		return nil, ErrIncorrectPassphrase
+	}
+	for _, b := range out[len(out)-n:] {
+		if int(b) != n {
+			return nil, ErrIncorrectPassphrase
+		}
+	}
+	return out[:len(out)-n], nil
+}
@@ -0,0 +1,195 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pkcs
+
+import (
+	"crypto/hmac"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+var (
+	oidDataContentType          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContentType = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+	oidKeyBag               = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS8ShroudedKeyBag  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidCertTypeX509         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidPBEWithSHAAnd40BitRC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+)
+
+type pfxPdu struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+// IsLegacyRC2 returns true if err is caused by the 40 bit RC2 encryption of legacy PKCS#12 bundles.
+func IsLegacyRC2(err error) bool { return errors.Is(err, errLegacyRC2) }
+
+var errLegacyRC2 = fmt.Errorf("%w: pbeWithSHAAnd40BitRC2-CBC", ErrUnsupported)
+
+func verifyMac(md *macData, message []byte, passphrase string) error {
+	h, v, err := digest(md.Mac.Algorithm.Algorithm)
+	if err != nil {
+		return err
+	}
+	verify := func(password []byte) bool {
+		key := pkcs12KDF(h, v, md.MacSalt, password, md.Iterations, 3, h().Size())
+		mac := hmac.New(h, key)
+		mac.Write(message)
+		return hmac.Equal(md.Mac.Digest, mac.Sum(nil))
+	}
+	if verify(bmpString(passphrase)) {
+		return nil
+	}
+	if passphrase == "" && verify(nil) { // some implementations use an empty password instead of the empty BMPString
+		return nil
+	}
+	return ErrIncorrectPassphrase
+}
+
+// DecodePKCS12 decodes a DER encoded PKCS#12 bundle and returns the private key in unencrypted PKCS#8
+// DER encoding and the DER encoded certificates in bundle order.
+func DecodePKCS12(der []byte, passphrase string) ([]byte, [][]byte, error) {
+	var pfx pfxPdu
+	if err := unmarshal(der, &pfx); err != nil {
+		return nil, nil, fmt.Errorf("invalid PKCS#12 data: %w", err)
+	}
+	if pfx.Version != 3 {
+		return nil, nil, fmt.Errorf("%w: PKCS#12 version %d", ErrUnsupported, pfx.Version)
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidDataContentType) {
+		return nil, nil, fmt.Errorf("%w: public key protected PKCS#12 data", ErrUnsupported)
+	}
+	var authSafeData []byte
+	if err := unmarshal(pfx.AuthSafe.Content.Bytes, &authSafeData); err != nil {
+		return nil, nil, err
+	}
+	if len(pfx.MacData.Mac.Algorithm.Algorithm) != 0 {
+		if err := verifyMac(&pfx.MacData, authSafeData, passphrase); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var authSafe []contentInfo
+	if err := unmarshal(authSafeData, &authSafe); err != nil {
+		return nil, nil, err
+	}
+
+	var key []byte
+	var certs [][]byte
+	for _, ci := range authSafe {
+		var data []byte
+		switch {
+		case ci.ContentType.Equal(oidDataContentType):
+			if err := unmarshal(ci.Content.Bytes, &data); err != nil {
+				return nil, nil, err
+			}
+		case ci.ContentType.Equal(oidEncryptedDataContentType):
+			var ed encryptedData
+			if err := unmarshal(ci.Content.Bytes, &ed); err != nil {
+				return nil, nil, err
+			}
+			alg := ed.EncryptedContentInfo.ContentEncryptionAlgorithm
+			if alg.Algorithm.Equal(oidPBEWithSHAAnd40BitRC) {
+				return nil, nil, errLegacyRC2
+			}
+			var err error
+			if data, err = decrypt(alg, ed.EncryptedContentInfo.EncryptedContent, passphrase); err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, fmt.Errorf("%w: content type %s", ErrUnsupported, ci.ContentType)
+		}
+
+		var bags []safeBag
+		if err := unmarshal(data, &bags); err != nil {
+			return nil, nil, err
+		}
+		for _, bag := range bags {
+			switch {
+			case bag.ID.Equal(oidCertBag):
+				var cb certBag
+				if err := unmarshal(bag.Value.Bytes, &cb); err != nil {
+					return nil, nil, err
+				}
+				if !cb.ID.Equal(oidCertTypeX509) {
+					continue // ignore non X509 certificates
+				}
+				certs = append(certs, cb.Data)
+			case bag.ID.Equal(oidKeyBag), bag.ID.Equal(oidPKCS8ShroudedKeyBag):
+				if key != nil {
+					return nil, nil, errors.New("PKCS#12 data contains more than one private key")
+				}
+				if bag.ID.Equal(oidKeyBag) {
+					key = bag.Value.Bytes
+				} else {
+					var err error
+					if key, err = DecryptPKCS8(bag.Value.Bytes, passphrase); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+		}
+	}
+	if key == nil {
+		return nil, nil, errors.New("PKCS#12 data does not contain a private key")
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(key); err != nil {
+		return nil, nil, err
+	}
+	if len(certs) == 0 {
+		return nil, nil, errors.New("PKCS#12 data does not contain a certificate")
+	}
+	return key, certs, nil
+}
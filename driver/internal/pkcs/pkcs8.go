@@ -0,0 +1,32 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pkcs
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+)
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// DecryptPKCS8 decrypts a DER encoded PKCS#8 encrypted private key and returns the private key
+// in unencrypted PKCS#8 DER encoding.
+func DecryptPKCS8(der []byte, passphrase string) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if err := unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+	key, err := decrypt(info.Algorithm, info.EncryptedData, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(key); err != nil {
+		return nil, ErrIncorrectPassphrase // padding might be valid by chance
+	}
+	return key, nil
+}
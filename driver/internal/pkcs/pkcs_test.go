@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pkcs
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func testPEMBytes(t *testing.T, s string) []byte {
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		t.Fatal("invalid PEM data")
+	}
+	return block.Bytes
+}
+
+func testPublicKey(t *testing.T, key []byte) *ecdsa.PublicKey {
+	k, err := x509.ParsePKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &k.(*ecdsa.PrivateKey).PublicKey
+}
+
+func testLeafPublicKey(t *testing.T) *ecdsa.PublicKey {
+	cert, err := x509.ParseCertificate(testPEMBytes(t, testLeafCert))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert.PublicKey.(*ecdsa.PublicKey)
+}
+
+func testDecryptPKCS8(t *testing.T) {
+	for name, data := range map[string]string{"aes": testAESKey, "des": testDESKey} {
+		t.Run(name, func(t *testing.T) {
+			key, err := DecryptPKCS8(testPEMBytes(t, data), "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !testPublicKey(t, key).Equal(testLeafPublicKey(t)) {
+				t.Fatal("private key does not match certificate")
+			}
+			if _, err := DecryptPKCS8(testPEMBytes(t, data), "wrong"); !errors.Is(err, ErrIncorrectPassphrase) {
+				t.Fatalf("expected error %v - got %v", ErrIncorrectPassphrase, err)
+			}
+		})
+	}
+}
+
+func testDecodePKCS12(t *testing.T) {
+	for name, data := range map[string]string{"aes": testAESPKCS12, "des": testDESPKCS12} {
+		t.Run(name, func(t *testing.T) {
+			der, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, certs, err := DecodePKCS12(der, "secret")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !testPublicKey(t, key).Equal(testLeafPublicKey(t)) {
+				t.Fatal("private key does not match certificate")
+			}
+			if len(certs) != 2 {
+				t.Fatalf("number of certificates %d - expected 2", len(certs))
+			}
+			leaf, ca := testPEMBytes(t, testLeafCert), testPEMBytes(t, testCACert)
+			for _, cert := range certs {
+				if !bytes.Equal(cert, leaf) && !bytes.Equal(cert, ca) {
+					t.Fatal("unexpected certificate")
+				}
+			}
+			if _, _, err := DecodePKCS12(der, "wrong"); !errors.Is(err, ErrIncorrectPassphrase) {
+				t.Fatalf("expected error %v - got %v", ErrIncorrectPassphrase, err)
+			}
+		})
+	}
+}
+
+func TestPKCS(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"decryptPKCS8", testDecryptPKCS8},
+		{"decodePKCS12", testDecodePKCS12},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
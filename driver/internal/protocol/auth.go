@@ -5,7 +5,9 @@
 package protocol
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/SAP/go-hdb/driver/internal/protocol/auth"
 	"github.com/SAP/go-hdb/driver/internal/protocol/encoding"
@@ -31,6 +33,14 @@ type AuthCookieGetter interface {
 	Cookie() (logonname string, cookie []byte)
 }
 
+// AuthRefresher is implemented by authentication methods supporting credential refresh.
+type AuthRefresher interface {
+	Refresh() bool
+}
+
+// ErrAuthMethodNotAllowed is returned in case the authentication method is not allowed.
+var ErrAuthMethodNotAllowed = errors.New("authentication method not allowed")
+
 type authMethods map[string]auth.Method // key equals authentication method type.
 
 // Auth holds the client authentication methods dependant on the driver.Connector attributes.
@@ -38,6 +48,8 @@ type Auth struct {
 	logonname string
 	methods   authMethods
 	method    auth.Method // selected method
+	allowed   []string    // allowed method types in priority order (nil: all methods allowed)
+	clientID  string      // client id of session cookie authentication
 }
 
 // NewAuth creates a new Auth instance.
@@ -48,9 +60,14 @@ func (a *Auth) String() string { return fmt.Sprintf("logonname %s", a.logonname)
 // AddSessionCookie adds session cookie authentication method.
 func (a *Auth) AddSessionCookie(cookie []byte, clientID string) {
 	a.methods[auth.MtSessionCookie] = auth.NewSessionCookie(cookie, clientID)
+	a.clientID = clientID
 	auth.Tracef("add session cookie: cookie %v clientID %s", cookie, clientID)
 }
 
+// ClientID returns the client id the session cookie was issued for or an empty string
+// in case no session cookie authentication method was added.
+func (a *Auth) ClientID() string { return a.clientID }
+
 // AddBasic adds basic authentication methods.
 func (a *Auth) AddBasic(username, password string) {
 	a.methods[auth.MtSCRAMPBKDF2SHA256] = auth.NewSCRAMPBKDF2SHA256(username, password)
@@ -63,6 +80,42 @@ func (a *Auth) AddJWT(token string) { a.methods[auth.MtJWT] = auth.NewJWT(token)
 // AddX509 adds X509 authentication method.
 func (a *Auth) AddX509(cert, key []byte) { a.methods[auth.MtX509] = auth.NewX509(cert, key) }
 
+// AddCustom adds a custom authentication method.
+func (a *Auth) AddCustom(m auth.CustomMethod) {
+	a.methods[m.Typ()] = auth.NewCustom(m)
+	auth.Tracef("add custom method: %s", m.Typ())
+}
+
+// Restrict restricts the authentication methods to the method types mts.
+// The methods are sent to the database in the order of mts instead of the default method order.
+func (a *Auth) Restrict(mts []string) { a.allowed = mts }
+
+func (a *Auth) isAllowed(mt string) bool {
+	if a.allowed == nil {
+		return true
+	}
+	for _, allowed := range a.allowed {
+		if allowed == mt {
+			return true
+		}
+	}
+	return false
+}
+
+// orderedMethods returns the allowed methods in priority order.
+func (a *Auth) orderedMethods() []auth.Method {
+	if a.allowed == nil {
+		return a.methods.order()
+	}
+	methods := make([]auth.Method, 0, len(a.allowed))
+	for _, mt := range a.allowed {
+		if m, ok := a.methods[mt]; ok {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
 // Method returns the selected authentication method.
 func (a *Auth) Method() auth.Method { return a.method }
 
@@ -71,6 +124,9 @@ func (a *Auth) setMethod(mt string) error {
 
 	auth.Tracef("selected method: %s", mt)
 
+	if !a.isAllowed(mt) {
+		return fmt.Errorf("%w: %s selected by server (allowed methods: %s)", ErrAuthMethodNotAllowed, mt, strings.Join(a.allowed, ", "))
+	}
 	if a.method, ok = a.methods[mt]; !ok {
 		return fmt.Errorf("invalid method type: %s", mt)
 	}
@@ -80,10 +136,16 @@ func (a *Auth) setMethod(mt string) error {
 // InitRequest returns the init request part.
 func (a *Auth) InitRequest() (*AuthInitRequest, error) {
 	auth.Trace("authentication: initial request")
+	methods := a.orderedMethods()
+	if len(methods) == 0 && a.allowed != nil {
+		return nil, fmt.Errorf("%w: no credentials for allowed methods %s", ErrAuthMethodNotAllowed, strings.Join(a.allowed, ", "))
+	}
 	prms := &auth.Prms{}
 	prms.AddCESU8String(a.logonname)
-	for _, m := range a.methods.order() {
-		m.PrepareInitReq(prms)
+	for _, m := range methods {
+		if err := m.PrepareInitReq(prms); err != nil {
+			return nil, err
+		}
 	}
 	return &AuthInitRequest{prms: prms}, nil
 }
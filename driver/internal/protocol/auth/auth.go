@@ -31,6 +31,7 @@ const (
 	MoSessionCookie byte = iota
 	MoX509
 	MoJWT
+	MoSAML
 	MoSCRAMPBKDF2SHA256
 	MoSCRAMSHA256
 )
@@ -40,7 +41,7 @@ type Method interface {
 	fmt.Stringer
 	Typ() string
 	Order() byte
-	PrepareInitReq(prms *Prms)
+	PrepareInitReq(prms *Prms) error
 	InitRepDecode(d *Decoder) error
 	PrepareFinalReq(prms *Prms) error
 	FinalRepDecode(d *Decoder) error
@@ -52,6 +53,7 @@ var (
 	_ Method = (*JWT)(nil)
 	_ Method = (*X509)(nil)
 	_ Method = (*SessionCookie)(nil)
+	_ Method = (*Custom)(nil)
 )
 
 // subPrmsSize is the type used to encode and decode the size of sub parameters.
@@ -0,0 +1,102 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package auth
+
+import (
+	"fmt"
+)
+
+// CustomMethod is the interface of authentication methods implemented outside of the driver.
+type CustomMethod interface {
+	Typ() string
+	Order() byte
+	InitRequest() ([]byte, error)
+	InitReply(data []byte) error
+	FinalRequest() ([]interface{}, error)
+	FinalReply(data []byte) error
+}
+
+// Custom adapts a custom authentication method to the Method interface.
+type Custom struct {
+	m CustomMethod
+}
+
+// NewCustom creates a new Custom instance.
+func NewCustom(m CustomMethod) *Custom { return &Custom{m: m} }
+
+func (a *Custom) String() string { return fmt.Sprintf("method type %s", a.Typ()) }
+
+// Typ implements the Method interface.
+func (a *Custom) Typ() string { return a.m.Typ() }
+
+// Order implements the Method interface.
+func (a *Custom) Order() byte { return a.m.Order() }
+
+// Refresh calls the Refresh method of the custom method if implemented.
+func (a *Custom) Refresh() bool {
+	if r, ok := a.m.(interface{ Refresh() bool }); ok {
+		return r.Refresh()
+	}
+	return false
+}
+
+// Cookie calls the Cookie method of the custom method if implemented.
+func (a *Custom) Cookie() (string, []byte) {
+	if c, ok := a.m.(interface{ Cookie() (string, []byte) }); ok {
+		return c.Cookie()
+	}
+	return "", nil
+}
+
+// PrepareInitReq implements the Method interface.
+func (a *Custom) PrepareInitReq(prms *Prms) error {
+	data, err := a.m.InitRequest()
+	if err != nil {
+		return err
+	}
+	prms.addString(a.Typ())
+	prms.addBytes(data)
+	return nil
+}
+
+// InitRepDecode implements the Method interface.
+func (a *Custom) InitRepDecode(d *Decoder) error {
+	data := d.bytes()
+	Tracef("%s auth - init reply: %v", a.Typ(), data)
+	return a.m.InitReply(data)
+}
+
+// PrepareFinalReq implements the Method interface.
+func (a *Custom) PrepareFinalReq(prms *Prms) error {
+	params, err := a.m.FinalRequest()
+	if err != nil {
+		return err
+	}
+	for _, param := range params {
+		switch param := param.(type) {
+		case string:
+			prms.AddCESU8String(param)
+		case []byte:
+			prms.addBytes(param)
+		default:
+			return fmt.Errorf("%s auth - invalid final request parameter type %T", a.Typ(), param)
+		}
+	}
+	return nil
+}
+
+// FinalRepDecode implements the Method interface.
+func (a *Custom) FinalRepDecode(d *Decoder) error {
+	if err := d.NumPrm(2); err != nil {
+		return err
+	}
+	mt := d.String()
+	if err := checkAuthMethodType(mt, a.Typ()); err != nil {
+		return err
+	}
+	data := d.bytes()
+	Tracef("%s auth - final reply: %v", a.Typ(), data)
+	return a.m.FinalReply(data)
+}
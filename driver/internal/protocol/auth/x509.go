@@ -44,9 +44,10 @@ func (a *X509) Typ() string { return MtX509 }
 func (a *X509) Order() byte { return MoX509 }
 
 // PrepareInitReq implements the Method interface.
-func (a *X509) PrepareInitReq(prms *Prms) {
+func (a *X509) PrepareInitReq(prms *Prms) error {
 	prms.addString(a.Typ())
 	prms.addEmpty()
+	return nil
 }
 
 // InitRepDecode implements the Method interface.
@@ -20,6 +20,11 @@ func (m authMethods) order() []auth.Method {
 	for _, method := range m {
 		methods = append(methods, method)
 	}
-	sort.Slice(methods, func(i, j int) bool { return methods[i].Order() < methods[j].Order() })
+	sort.Slice(methods, func(i, j int) bool {
+		if methods[i].Order() == methods[j].Order() {
+			return methods[i].Typ() < methods[j].Typ()
+		}
+		return methods[i].Order() < methods[j].Order()
+	})
 	return methods
 }
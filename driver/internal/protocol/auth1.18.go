@@ -17,6 +17,11 @@ import (
 
 func (m authMethods) order() []auth.Method {
 	methods := maps.Values(m)
-	slices.SortFunc(methods, func(a, b auth.Method) bool { return a.Order() < b.Order() })
+	slices.SortFunc(methods, func(a, b auth.Method) bool {
+		if a.Order() == b.Order() {
+			return a.Typ() < b.Typ()
+		}
+		return a.Order() < b.Order()
+	})
 	return methods
 }
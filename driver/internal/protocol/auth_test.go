@@ -6,6 +6,8 @@ package protocol
 
 import (
 	"bytes"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/SAP/go-hdb/driver/internal/protocol/auth"
@@ -104,12 +106,135 @@ func testJWTAuth(t *testing.T) {
 	}
 }
 
+// testCustomMethod mimics JWT authentication via the custom method interface.
+type testCustomMethod struct {
+	logonname string
+	cookie    []byte
+}
+
+func (m *testCustomMethod) Typ() string                  { return "JWT" }
+func (m *testCustomMethod) Order() byte                  { return auth.MoJWT }
+func (m *testCustomMethod) InitRequest() ([]byte, error) { return []byte("dummy token"), nil }
+func (m *testCustomMethod) InitReply(data []byte) error  { m.logonname = string(data); return nil }
+func (m *testCustomMethod) FinalRequest() ([]interface{}, error) {
+	return []interface{}{m.logonname, []byte(m.Typ()), []byte{}}, nil
+}
+func (m *testCustomMethod) FinalReply(data []byte) error { m.cookie = data; return nil }
+func (m *testCustomMethod) Cookie() (string, []byte)     { return m.logonname, m.cookie }
+
+func testCustomAuth(t *testing.T) {
+	m := &testCustomMethod{}
+	a := NewAuth("")
+	a.AddCustom(m)
+
+	initRequest, err := a.InitRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := authEncodeStep(initRequest, t), []byte("\x03\x00\x00\x03JWT\x0Bdummy token"); !bytes.Equal(expected, actual) {
+		t.Fatalf("init request: expected %q, got %q", string(expected), string(actual))
+	}
+
+	initReply, err := a.InitReply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authDecodeStep(initReply, []byte("\x02\x00\x03JWT\x07USER123"), t)
+	if m.logonname != "USER123" {
+		t.Fatalf("init reply: expected USER123, got %s", m.logonname)
+	}
+
+	finalRequest, err := a.FinalRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := authEncodeStep(finalRequest, t), []byte("\x03\x00\x07USER123\x03JWT\x00"); !bytes.Equal(expected, actual) {
+		t.Fatalf("final request: expected %q, got %q", string(expected), string(actual))
+	}
+
+	finalReply, err := a.FinalReply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authDecodeStep(finalReply, []byte("\x02\x00\x03JWT\x205be8f43e064e0589ce07ba9de6fce107"), t)
+	if _, cookie := a.Method().(AuthCookieGetter).Cookie(); string(cookie) != "5be8f43e064e0589ce07ba9de6fce107" {
+		t.Fatalf("final reply: unexpected cookie %q", string(cookie))
+	}
+}
+
+func testRestrictAuth(t *testing.T) {
+	a := NewAuth("")
+	a.AddJWT("dummy token")
+	a.AddBasic("user", "password")
+	a.Restrict([]string{auth.MtSCRAMPBKDF2SHA256, auth.MtJWT})
+
+	methods := a.orderedMethods()
+	if len(methods) != 2 || methods[0].Typ() != auth.MtSCRAMPBKDF2SHA256 || methods[1].Typ() != auth.MtJWT {
+		t.Fatalf("unexpected methods %v", methods)
+	}
+	if _, err := a.InitRequest(); err != nil {
+		t.Fatal(err)
+	}
+
+	initReply, err := a.InitReply()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := encoding.NewDecoder(bytes.NewBuffer([]byte("\x02\x00\x0BSCRAMSHA256\x00")), cesu8.DefaultDecoder)
+	if err := initReply.decode(dec, nil); !errors.Is(err, ErrAuthMethodNotAllowed) {
+		t.Fatalf("expected error %v - got %v", ErrAuthMethodNotAllowed, err)
+	}
+
+	a = NewAuth("")
+	a.AddBasic("user", "password")
+	a.Restrict([]string{auth.MtX509})
+	if _, err := a.InitRequest(); !errors.Is(err, ErrAuthMethodNotAllowed) {
+		t.Fatalf("expected error %v - got %v", ErrAuthMethodNotAllowed, err)
+	}
+}
+
+// testOrderMethod is a custom method with configurable type and order.
+type testOrderMethod struct {
+	typ   string
+	order byte
+}
+
+func (m *testOrderMethod) Typ() string                          { return m.typ }
+func (m *testOrderMethod) Order() byte                          { return m.order }
+func (m *testOrderMethod) InitRequest() ([]byte, error)         { return nil, nil }
+func (m *testOrderMethod) InitReply(data []byte) error          { return nil }
+func (m *testOrderMethod) FinalRequest() ([]interface{}, error) { return nil, nil }
+func (m *testOrderMethod) FinalReply(data []byte) error         { return nil }
+
+func testMethodOrder(t *testing.T) {
+	a := NewAuth("")
+	a.AddBasic("user", "password")
+	a.AddJWT("token")
+	a.AddCustom(&testOrderMethod{typ: "SAML", order: auth.MoSAML})
+	a.AddCustom(&testOrderMethod{typ: "ZZZ", order: auth.MoJWT}) // same order as JWT
+
+	expected := []string{auth.MtJWT, "ZZZ", "SAML", auth.MtSCRAMPBKDF2SHA256, auth.MtSCRAMSHA256}
+	for i := 0; i < 10; i++ { // map iteration order is random
+		methods := a.orderedMethods()
+		mts := make([]string, len(methods))
+		for j, m := range methods {
+			mts[j] = m.Typ()
+		}
+		if !reflect.DeepEqual(mts, expected) {
+			t.Fatalf("method order %v - expected %v", mts, expected)
+		}
+	}
+}
+
 func TestAuth(t *testing.T) {
 	tests := []struct {
 		name string
 		fct  func(t *testing.T)
 	}{
 		{"testJWTAuth", testJWTAuth},
+		{"testCustomAuth", testCustomAuth},
+		{"testRestrictAuth", testRestrictAuth},
+		{"testMethodOrder", testMethodOrder},
 	}
 
 	for _, test := range tests {
@@ -44,6 +44,8 @@ const (
 // HANA Database errors.
 const (
 	HdbErrAuthenticationFailed = 10
+	HdbErrForcePasswordChange  = 414 // user is forced to change password
+	HdbWarnPasswordExpiring    = 431 // user's password will expire within few days
 )
 
 type sqlState [sqlStateSize]byte
@@ -84,6 +86,16 @@ type HdbErrors struct {
 	idx int
 }
 
+// NewHdbErrors returns a HdbErrors instance containing a single error (level error) or warning
+// (e.g. to simulate database errors in tests).
+func NewHdbErrors(code int, warning bool, text string) *HdbErrors {
+	level := errorLevelError
+	if warning {
+		level = errorLevelWarning
+	}
+	return &HdbErrors{errors: []*hdbError{{errorCode: int32(code), errorTextLength: int32(len(text)), errorLevel: level, errorText: []byte(text)}}}
+}
+
 func (e *HdbErrors) String() string { return e.errors[e.idx].String() }
 func (e *HdbErrors) Error() string  { return e.errors[e.idx].Error() }
 
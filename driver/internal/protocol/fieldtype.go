@@ -267,6 +267,11 @@ type ReadProvider interface {
 	Reader() io.Reader
 }
 
+// ProgressProvider is the interface wrapping the Progress method which provides a lob write progress callback.
+type ProgressProvider interface {
+	Progress() func(n int64)
+}
+
 // Lob
 func convertLob(t transform.Transformer, ft fieldType, v interface{}) (driver.Value, error) {
 	if v == nil {
@@ -274,6 +279,11 @@ func convertLob(t transform.Transformer, ft fieldType, v interface{}) (driver.Va
 	}
 
 	var rd io.Reader
+	var progress func(n int64)
+
+	if v, ok := v.(ProgressProvider); ok {
+		progress = v.Progress()
+	}
 
 	switch v := v.(type) {
 	case io.Reader:
@@ -290,7 +300,9 @@ func convertLob(t transform.Transformer, ft fieldType, v interface{}) (driver.Va
 		rd = transform.NewReader(rd, t)
 	}
 
-	return newLobInDescr(rd), nil
+	descr := newLobInDescr(rd)
+	descr.progress = progress
+	return descr, nil
 }
 
 // prm size
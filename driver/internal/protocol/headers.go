@@ -261,6 +261,9 @@ func (h *PartHeader) String() string {
 	)
 }
 
+// BufferLength returns the length of the part buffer in bytes.
+func (h *PartHeader) BufferLength() int { return int(h.bufferLength) }
+
 func (h *PartHeader) setNumArg(numArg int) error {
 	switch {
 	default:
@@ -77,8 +77,14 @@ type LobsDecoderSetter interface {
 	SetLobsDecoder(fn func(descr *LobOutDescr, wr io.Writer) error)
 }
 
+// LobReaderSetter is the interface wrapping the SetLobReader method (lob handling).
+type LobReaderSetter interface {
+	SetLobReader(fn func(descr *LobOutDescr, ofs int64, size int32) ([]byte, int64, error))
+}
+
 var _ WriterSetter = (*LobOutDescr)(nil)
 var _ LobsDecoderSetter = (*LobOutDescr)(nil)
+var _ LobReaderSetter = (*LobOutDescr)(nil)
 
 // LobInDescr represents a lob input descriptor.
 type LobInDescr struct {
@@ -88,6 +94,9 @@ type LobInDescr struct {
 	_size int
 	pos   int
 	b     []byte
+
+	progress   func(n int64) // progress callback
+	numWritten int64         // number of bytes written to the database
 }
 
 func newLobInDescr(rd io.Reader) *LobInDescr {
@@ -127,6 +136,18 @@ func (d *LobInDescr) FetchNext(chunkSize int) (bool, error) {
 	return true, nil
 }
 
+// ChunkWritten needs to be called after the current chunk is written to the database
+// to report the progress of the lob write.
+func (d *LobInDescr) ChunkWritten() {
+	if len(d.b) == 0 {
+		return
+	}
+	d.numWritten += int64(len(d.b))
+	if d.progress != nil {
+		d.progress(d.numWritten)
+	}
+}
+
 func (d *LobInDescr) setPos(pos int) { d.pos = pos }
 
 func (d *LobInDescr) size() int { return d._size }
@@ -139,6 +160,7 @@ func (d *LobInDescr) writeFirst(enc *encoding.Encoder) {
 type LobOutDescr struct {
 	//TODO description
 	fnLD        func(descr *LobOutDescr, wr io.Writer) error
+	fnLR        func(descr *LobOutDescr, ofs int64, size int32) ([]byte, int64, error)
 	IsCharBased bool
 	/*
 		HDB does not return lob type code but undefined only
@@ -163,6 +185,23 @@ func (d *LobOutDescr) SetLobsDecoder(fn func(descr *LobOutDescr, wr io.Writer) e
 // SetWriter implements the WriterSetter interface.
 func (d *LobOutDescr) SetWriter(wr io.Writer) error { return d.fnLD(d, wr) }
 
+// SetLobReader sets the function to read lob chunks at arbitrary offsets.
+func (d *LobOutDescr) SetLobReader(fn func(descr *LobOutDescr, ofs int64, size int32) ([]byte, int64, error)) {
+	d.fnLR = fn
+}
+
+// ReadChunk reads a chunk of size bytes (binary lobs) or characters (character based lobs) starting at offset ofs.
+// It returns the chunk content and the number of bytes or characters read.
+func (d *LobOutDescr) ReadChunk(ofs int64, size int32) ([]byte, int64, error) {
+	if d.fnLR == nil {
+		return nil, 0, fmt.Errorf("lob: random access reader not available")
+	}
+	return d.fnLR(d, ofs, size)
+}
+
+// NumByte returns the lob size in bytes.
+func (d *LobOutDescr) NumByte() int64 { return d.numByte }
+
 /*
 write lobs:
 - write lob field to database in chunks
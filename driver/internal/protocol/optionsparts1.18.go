@@ -57,3 +57,12 @@ func (ops Options[K]) encode(enc *encoding.Encoder) error {
 	}
 	return nil
 }
+
+// serverExecutionTime returns the server execution time of a statement context part.
+func serverExecutionTime(part partReader) (int64, bool) {
+	if ops, ok := part.(*Options[statementContextType]); ok {
+		v, ok := (*ops)[scServerExecutionTime].(int64)
+		return v, ok
+	}
+	return 0, false
+}
@@ -113,6 +113,9 @@ func (f *ParameterField) TypeName() string { return f.TC.typeName() }
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeScanType
 func (f *ParameterField) ScanType() reflect.Type { return f.TC.dataType().ScanType() }
 
+// DataType returns the data type of the field.
+func (f *ParameterField) DataType() DataType { return f.TC.dataType() }
+
 // TypeLength returns the type length of the field.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeLength
 func (f *ParameterField) TypeLength() (int64, bool) {
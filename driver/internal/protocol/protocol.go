@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"time"
 
 	"github.com/SAP/go-hdb/driver/internal/protocol/encoding"
 	"github.com/SAP/go-hdb/driver/sqltrace"
@@ -50,6 +51,8 @@ type Reader struct {
 	lastErrors       *HdbErrors
 	lastRowsAffected *RowsAffected
 
+	serverTime time.Duration // accumulated server execution time
+
 	// partReader read errors could be
 	// - read buffer errors -> buffer Error() and ResetError()
 	// - plus other errors (which cannot be ignored, e.g. Lob reader)
@@ -72,6 +75,9 @@ func NewReader(upStream bool, rd io.Reader, decoder func() transform.Transformer
 	}
 }
 
+// ServerTime returns the accumulated server execution time of all statements read by the reader.
+func (r *Reader) ServerTime() time.Duration { return r.serverTime }
+
 // SetDfv sets the data format version fpr the protocol reader.
 func (r *Reader) SetDfv(dfv int) { r.dec.SetDfv(dfv) }
 
@@ -165,6 +171,10 @@ func (r *Reader) Read(part partReader) error {
 		r.lastErrors = part
 	case *RowsAffected:
 		r.lastRowsAffected = part
+	default:
+		if v, ok := serverExecutionTime(part); ok {
+			r.serverTime += time.Duration(v) * time.Microsecond
+		}
 	}
 	return err
 }
@@ -190,7 +200,7 @@ func (r *Reader) skip() error {
 	pk := r.ph.PartKind
 
 	// if trace is on or mandatory parts need to be read we cannot skip
-	if !(r.traceOn || pk == PkError || pk == PkRowsAffected) {
+	if !(r.traceOn || pk == PkError || pk == PkRowsAffected || pk == PkStatementContext) {
 		return r.skipPart()
 	}
 
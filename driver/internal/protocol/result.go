@@ -115,6 +115,21 @@ func (f *ResultField) Nullable() bool { return f.columnOptions == coOptional }
 // Name returns the result field name.
 func (f *ResultField) Name() string { return f.columnDisplayName }
 
+// SchemaName returns the schema name of the field source table.
+func (f *ResultField) SchemaName() string { return f.schemaName }
+
+// TableName returns the name of the field source table.
+func (f *ResultField) TableName() string { return f.tableName }
+
+// ColumnName returns the name of the field source column.
+func (f *ResultField) ColumnName() string { return f.columnName }
+
+// DataType returns the data type of the field.
+func (f *ResultField) DataType() DataType { return f.tc.dataType() }
+
+// IsLob returns true if the field is a large object field.
+func (f *ResultField) IsLob() bool { return f.tc.IsLob() }
+
 func (f *ResultField) decode(dec *encoding.Decoder) {
 	f.columnOptions = columnOptions(dec.Int8())
 	f.tc = TypeCode(dec.Int8())
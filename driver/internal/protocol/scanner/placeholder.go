@@ -0,0 +1,30 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"fmt"
+)
+
+// Placeholders returns the positions of the positional parameter placeholders (?) of a query.
+// Question marks in comments, string literals and quoted identifiers are ignored.
+func Placeholders(query string) ([]int, error) {
+	var pos []int
+
+	sc := &Scanner{}
+	sc.Reset(query)
+
+	for {
+		token, start, _ := sc.Next()
+		switch token {
+		case EOS:
+			return pos, nil
+		case Error:
+			return nil, fmt.Errorf("position %d: %w", start, ErrToken)
+		case Variable:
+			pos = append(pos, start)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestPlaceholders(t *testing.T) {
+	testData := []struct {
+		query string
+		pos   []int
+	}{
+		{"select 1 from dummy", nil},
+		{"select * from t where a in (?) and b = ?", []int{28, 39}},
+		{"select '?''?' from \"t?\" where a = ? -- ?\n", []int{34}},
+		{"select /* ? */ ? from dummy", []int{15}},
+	}
+	for _, d := range testData {
+		pos, err := Placeholders(d.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(pos, d.pos) {
+			t.Fatalf("%s: positions %v - expected %v", d.query, pos, d.pos)
+		}
+	}
+
+	for _, query := range []string{"select 'a from dummy", "select \"a from dummy", "select /* ? from dummy"} {
+		if _, err := Placeholders(query); !errors.Is(err, ErrToken) {
+			t.Fatalf("%s: error %v - expected %v", query, err, ErrToken)
+		}
+	}
+}
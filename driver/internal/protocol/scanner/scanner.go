@@ -5,6 +5,10 @@
 /*
 Package scanner implements a HANA SQL query scanner.
 
+The scanner skips whitespaces as well as line and block comments. Characters not being part
+of any token (like a single colon) are returned as Undefined, malformed tokens (like unterminated string
+literals, quoted identifiers or block comments and numbers with incomplete exponent) as Error.
+
 For a detailed HANA SQL query syntax please see
 https://help.sap.com/doc/6254b3bb439c4f409a979dc407b49c9b/2.0.00/en-US/SAP_HANA_SQL_Script_Reference_en.pdf
 */
@@ -117,17 +121,33 @@ func (sc *Scanner) unreadRune() {
 	sc.prev = -1
 }
 
-func (sc *Scanner) scanWhitespace() {
-	for {
-		ch, ok := sc.readRune()
-		if !ok {
-			return
-		}
-		if !unicode.IsSpace(ch) {
-			sc.unreadRune()
-			return
+// scanWhitespace skips whitespaces and comments (line and block comments).
+// It returns false in case of an unterminated block comment.
+func (sc *Scanner) scanWhitespace() bool {
+	for sc.i < len(sc.s) {
+		switch {
+		case strings.HasPrefix(sc.s[sc.i:], "--"): // line comment
+			end := strings.IndexByte(sc.s[sc.i:], '\n')
+			if end == -1 {
+				sc.i = len(sc.s)
+				return true
+			}
+			sc.i += end + 1
+		case strings.HasPrefix(sc.s[sc.i:], "/*"): // block comment
+			end := strings.Index(sc.s[sc.i+2:], "*/")
+			if end == -1 {
+				return false
+			}
+			sc.i += end + 4
+		default:
+			ch, _ := sc.readRune()
+			if !unicode.IsSpace(ch) {
+				sc.unreadRune()
+				return true
+			}
 		}
 	}
+	return true
 }
 
 func (sc *Scanner) scanOperator(ch rune) {
@@ -189,7 +209,11 @@ func (sc *Scanner) scanQuotedIdentifier(quote rune) Token {
 func (sc *Scanner) scanVariable() Token {
 	ch, ok := sc.readRune()
 	if !ok {
-		return Error
+		return Undefined
+	}
+	if !isAlpha(ch) { // single colon (e.g. assignment operator :=)
+		sc.unreadRune()
+		return Undefined
 	}
 	if isDigit(ch) {
 		sc.scanNumeric()
@@ -207,24 +231,30 @@ func (sc *Scanner) scanNumber() Token {
 	}
 	if isDecimalSeparator(ch) {
 		sc.scanNumeric()
+		if ch, ok = sc.readRune(); !ok {
+			return Number
+		}
 	}
-	ch, ok = sc.readRune()
-	if !ok {
+	if !isExp(ch) {
+		sc.unreadRune()
 		return Number
 	}
-	if isExp(ch) {
-		if isNumber(ch) {
-			sc.scanNumeric()
-			return Number
-		}
+	if ch, ok = sc.readRune(); !ok || !isNumber(ch) {
 		return Error
 	}
+	sc.scanNumeric()
 	return Number
 }
 
-// Next reads and returns the next token.
+// Next reads and returns the next token. Whitespaces and comments are skipped.
+// Malformed tokens like unterminated string literals or comments are returned as Error,
+// characters not being part of any other token as Undefined.
 func (sc *Scanner) Next() (token Token, start, end int) {
-	sc.scanWhitespace()
+	if !sc.scanWhitespace() {
+		start = sc.i
+		sc.i = len(sc.s)
+		return Error, start, sc.i
+	}
 
 	start = sc.i
 
@@ -235,7 +265,7 @@ func (sc *Scanner) Next() (token Token, start, end int) {
 
 	switch {
 	default:
-		return Error, start, sc.i
+		return Undefined, start, sc.i
 
 	case isDelimiter(ch):
 		return Delimiter, start, sc.i
@@ -111,6 +111,34 @@ var testData = []struct {
 			{Error, `" >= :start;`},
 		},
 	},
+	{
+		`select * /* comment; */ from t -- comment
+where a = 1.5e-3;b := :1;`,
+		[]tokenValue{
+			{Identifier, "select"},
+			{Undefined, "*"},
+			{Identifier, "from"},
+			{Identifier, "t"},
+			{Identifier, "where"},
+			{Identifier, "a"},
+			{Operator, "="},
+			{Number, "1.5e-3"},
+			{Delimiter, ";"},
+			{Identifier, "b"},
+			{Undefined, ":"},
+			{Operator, "="},
+			{PosVariable, ":1"},
+			{Delimiter, ";"},
+		},
+	},
+	{
+		`select 1 /* unterminated`,
+		[]tokenValue{
+			{Identifier, "select"},
+			{Number, "1"},
+			{Error, "/* unterminated"},
+		},
+	},
 	{
 		// call table result query
 		`rsid 1234567890`,
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Statement is a statement of a SQL script.
+type Statement struct {
+	Query      string // Statement text without terminating semicolon.
+	Line       int    // Line number of the statement start in the script (1-based).
+	Terminated bool   // Statement is terminated by a semicolon.
+}
+
+// blockEndKeywords are keywords following END which close blocks not opened by BEGIN (e.g. END IF).
+var blockEndKeywords = map[string]bool{"IF": true, "FOR": true, "WHILE": true, "LOOP": true}
+
+// Split splits a SQL script into statements separated by semicolons.
+//
+// Semicolons in comments (line and block comments), string literals and quoted identifiers do not terminate a statement.
+// Semicolons in SQLScript blocks (BEGIN ... END) and CASE expressions do not terminate a statement either,
+// so that e.g. CREATE PROCEDURE ... AS BEGIN ... END statements are returned as one statement.
+// Statements only consisting of whitespaces and comments are skipped.
+func Split(script string) ([]Statement, error) {
+	var stmts []Statement
+
+	sc := &Scanner{}
+	sc.Reset(script)
+
+	line, pos := 1, 0 // line number at position pos
+	lineAt := func(i int) int {
+		line += strings.Count(script[pos:i], "\n")
+		pos = i
+		return line
+	}
+
+	depth := 0        // BEGIN / CASE nesting
+	significant := -1 // position of the first significant (non comment) character of the current statement
+	significantLine := 0
+	lastEnd := false // last identifier was END closing a block
+
+	addStmt := func(end int, terminated bool) {
+		if significant != -1 {
+			query := strings.TrimRightFunc(script[significant:end], unicode.IsSpace)
+			stmts = append(stmts, Statement{Query: query, Line: significantLine, Terminated: terminated})
+		}
+		significant = -1
+		depth = 0
+		lastEnd = false
+	}
+
+	for {
+		token, start, end := sc.Next()
+		switch token {
+		case EOS:
+			addStmt(len(script), false)
+			return stmts, nil
+		case Error:
+			return nil, fmt.Errorf("line %d: %w", lineAt(start), ErrToken)
+		case Delimiter:
+			if script[start] == ';' {
+				if depth == 0 {
+					addStmt(start, true)
+				}
+				lastEnd = false
+				continue
+			}
+		}
+
+		if significant == -1 {
+			significant, significantLine = start, lineAt(start)
+		}
+		if token != Identifier {
+			lastEnd = false
+			continue
+		}
+
+		switch word := strings.ToUpper(script[start:end]); {
+		case lastEnd && word == "CASE":
+			lastEnd = false // END CASE closes the CASE statement opened by CASE
+		case word == "BEGIN" || word == "CASE":
+			depth++
+			lastEnd = false
+		case word == "END":
+			lastEnd = depth > 0
+			if lastEnd {
+				depth--
+			}
+		case lastEnd && blockEndKeywords[word]:
+			depth++ // END IF, END FOR, ... do not close a BEGIN block
+			lastEnd = false
+		default:
+			lastEnd = false
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scanner
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	testData := []struct {
+		script string
+		stmts  []Statement
+	}{
+		{``, nil},
+		{" \n-- comment only;\n/* ; */ ;", nil},
+		{
+			"create table t (a integer);\ninsert into t values (1);\n",
+			[]Statement{{"create table t (a integer)", 1, true}, {"insert into t values (1)", 2, true}},
+		},
+		{
+			"select 'a;''b' from \"x;y\"; select 1 from dummy -- last; statement",
+			[]Statement{{"select 'a;''b' from \"x;y\"", 1, true}, {"select 1 from dummy -- last; statement", 1, false}},
+		},
+		{
+			"do begin\n  select 1 from dummy;", // incomplete block
+			[]Statement{{"do begin\n  select 1 from dummy;", 1, false}},
+		},
+		{
+			"-- header\n/* multi\nline; */\nselect case when 1 = 1 then 'a' end from dummy;",
+			[]Statement{{"select case when 1 = 1 then 'a' end from dummy", 4, true}},
+		},
+		{
+			`create procedure p(in a integer, out b integer) as
+begin
+  if :a > 0 then
+    b = :a;
+  else
+    begin
+      b = 0;
+    end;
+  end if;
+  for i in 1..3 do
+    b = :b + 1;
+  end for;
+end;
+call p(1, ?);`,
+			[]Statement{
+				{`create procedure p(in a integer, out b integer) as
+begin
+  if :a > 0 then
+    b = :a;
+  else
+    begin
+      b = 0;
+    end;
+  end if;
+  for i in 1..3 do
+    b = :b + 1;
+  end for;
+end`, 1, true},
+				{"call p(1, ?)", 14, true},
+			},
+		},
+		{
+			"do begin\n  case :x when 1 then y = 1; else y = 2; end case;\nend;\nselect 1 from dummy;",
+			[]Statement{
+				{"do begin\n  case :x when 1 then y = 1; else y = 2; end case;\nend", 1, true},
+				{"select 1 from dummy", 4, true},
+			},
+		},
+		{
+			"do begin\n  if :x = 1 then\n    case :x when 1 then y = 1; end case;\n  end if;\n  y = case when :x = 1 then 1 end;\nend;\nselect 1 from dummy;",
+			[]Statement{
+				{"do begin\n  if :x = 1 then\n    case :x when 1 then y = 1; end case;\n  end if;\n  y = case when :x = 1 then 1 end;\nend", 1, true},
+				{"select 1 from dummy", 7, true},
+			},
+		},
+	}
+
+	for i, d := range testData {
+		stmts, err := Split(d.script)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if !reflect.DeepEqual(stmts, d.stmts) {
+			t.Fatalf("%d: got %v - expected %v", i, stmts, d.stmts)
+		}
+	}
+}
+
+func TestSplitError(t *testing.T) {
+	for _, script := range []string{"select 'a from dummy;", `select "a from dummy;`, "select 1 from dummy /* comment"} {
+		if _, err := Split(script); !errors.Is(err, ErrToken) {
+			t.Fatalf("script %s - got error %v - expected %v", script, err, ErrToken)
+		}
+	}
+}
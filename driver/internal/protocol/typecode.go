@@ -176,6 +176,51 @@ func (tc TypeCode) dataType() DataType {
 	}
 }
 
+// typeNameDataType maps database type names (e.g. SYS.TABLE_COLUMNS.DATA_TYPE_NAME) to data types.
+var typeNameDataType = map[string]DataType{
+	"BOOLEAN":      DtBoolean,
+	"TINYINT":      DtTinyint,
+	"SMALLINT":     DtSmallint,
+	"INTEGER":      DtInteger,
+	"BIGINT":       DtBigint,
+	"REAL":         DtReal,
+	"DOUBLE":       DtDouble,
+	"FLOAT":        DtDouble,
+	"DECIMAL":      DtDecimal,
+	"SMALLDECIMAL": DtDecimal,
+	"DATE":         DtTime,
+	"TIME":         DtTime,
+	"TIMESTAMP":    DtTime,
+	"SECONDDATE":   DtTime,
+	"LONGDATE":     DtTime,
+	"DAYDATE":      DtTime,
+	"SECONDTIME":   DtTime,
+	"CHAR":         DtString,
+	"VARCHAR":      DtString,
+	"NCHAR":        DtString,
+	"NVARCHAR":     DtString,
+	"ALPHANUM":     DtString,
+	"SHORTTEXT":    DtString,
+	"ST_GEOMETRY":  DtString,
+	"ST_POINT":     DtString,
+	"BINARY":       DtBytes,
+	"VARBINARY":    DtBytes,
+	"BLOB":         DtLob,
+	"CLOB":         DtLob,
+	"NCLOB":        DtLob,
+	"TEXT":         DtLob,
+	"BINTEXT":      DtLob,
+}
+
+// DataTypeOfTypeName returns the data type of a database type name.
+// DtUnknown is returned for unknown type names.
+func DataTypeOfTypeName(name string) DataType {
+	if dt, ok := typeNameDataType[strings.ToUpper(name)]; ok {
+		return dt
+	}
+	return DtUnknown
+}
+
 // typeName returns the database type name.
 // see https://golang.org/pkg/database/sql/driver/#RowsColumnTypeDatabaseTypeName
 func (tc TypeCode) typeName() string {
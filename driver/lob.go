@@ -6,8 +6,10 @@ package driver
 
 import (
 	"database/sql/driver"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 
 	p "github.com/SAP/go-hdb/driver/internal/protocol"
 )
@@ -18,8 +20,9 @@ import (
 // A Lob can be created by contructor method NewLob with io.Reader and io.Writer as parameters or
 // created by new, setting io.Reader and io.Writer by SetReader and SetWriter methods.
 type Lob struct {
-	rd io.Reader
-	wr io.Writer
+	rd       io.Reader
+	wr       io.Writer
+	progress func(n int64)
 }
 
 // NewLob creates a new Lob instance with the io.Reader and io.Writer given as parameters.
@@ -51,6 +54,20 @@ func (l *Lob) SetWriter(wr io.Writer) *Lob {
 	return l
 }
 
+// Progress returns the progress callback function of the Lob.
+func (l Lob) Progress() func(n int64) {
+	return l.progress
+}
+
+// SetProgress sets a callback function reporting the progress of writing the lob content to the database
+// and return *Lob, to enable simple call chaining.
+// The callback function is called after each chunk written to the database with the total number of bytes
+// written so far (CESU-8 encoded in case of character based lobs).
+func (l *Lob) SetProgress(fn func(n int64)) *Lob {
+	l.progress = fn
+	return l
+}
+
 // Scan implements the database/sql/Scanner interface.
 func (l *Lob) Scan(src interface{}) error {
 	if l.wr == nil {
@@ -70,7 +87,10 @@ func (l *Lob) Scan(src interface{}) error {
 
 // Value implements the database/sql/Valuer interface.
 func (l Lob) Value() (driver.Value, error) {
-	return l.rd, nil
+	if l.rd == nil || l.progress == nil {
+		return l.rd, nil
+	}
+	return l, nil // provides reader and progress callback
 }
 
 // NullLob represents an Lob that may be null.
@@ -99,5 +119,182 @@ func (l NullLob) Value() (driver.Value, error) {
 	if !l.Valid {
 		return nil, nil
 	}
-	return l.Lob.rd, nil
+	return l.Lob.Value()
+}
+
+var errLobReaderClosed = errors.New("lob reader is closed")
+
+// A LobReader provides access to the content of a database large object field without reading
+// the content in advance.
+// A LobReader can be used as scan destination of a lob field and implements the io.ReadCloser interface.
+// For binary lobs (BLOB) the io.ReaderAt and io.Seeker interfaces are supported in addition, so that
+// byte ranges of a lob can be read in arbitrary order.
+// As the content is read from the database on demand, a LobReader can only be used as long as the rows
+// (result set) the lob was scanned from are not closed.
+// A NULL lob field is represented by a LobReader with IsNull() true and no content.
+type LobReader struct {
+	descr  *p.LobOutDescr
+	ofs    int64  // read position (bytes or characters)
+	buf    []byte // character based lobs: utf-8 content not yet returned by Read
+	closed bool
+}
+
+var (
+	_ io.ReadCloser = (*LobReader)(nil)
+	_ io.ReaderAt   = (*LobReader)(nil)
+	_ io.Seeker     = (*LobReader)(nil)
+)
+
+// Scan implements the database/sql/Scanner interface.
+func (r *LobReader) Scan(src interface{}) error {
+	*r = LobReader{}
+	if src == nil {
+		return nil
+	}
+	descr, ok := src.(*p.LobOutDescr)
+	if !ok {
+		return fmt.Errorf("lob: invalid scan type %T", src)
+	}
+	r.descr = descr
+	return nil
+}
+
+// IsNull returns true if the lob field is NULL.
+func (r *LobReader) IsNull() bool { return r.descr == nil }
+
+// IsCharBased returns true for character based lobs (CLOB, NCLOB, TEXT).
+func (r *LobReader) IsCharBased() bool { return r.descr != nil && r.descr.IsCharBased }
+
+// ByteLen returns the size of the lob in bytes as stored in the database
+// (CESU-8 encoded in case of character based lobs).
+func (r *LobReader) ByteLen() int64 {
+	if r.descr == nil {
+		return 0
+	}
+	return r.descr.NumByte()
+}
+
+// CharLen returns the size of the lob in characters (binary lobs: bytes).
+// Please note that the database counts characters outside the Unicode BMP as two characters.
+func (r *LobReader) CharLen() int64 {
+	if r.descr == nil {
+		return 0
+	}
+	return r.descr.NumChar
+}
+
+func (r *LobReader) checkRead() error {
+	if r.closed {
+		return errLobReaderClosed
+	}
+	return nil
+}
+
+// Read implements the io.Reader interface.
+// The content of character based lobs is returned utf-8 encoded.
+func (r *LobReader) Read(b []byte) (int, error) {
+	if err := r.checkRead(); err != nil {
+		return 0, err
+	}
+	if r.descr == nil {
+		return 0, io.EOF
+	}
+	if !r.descr.IsCharBased {
+		n, err := r.ReadAt(b, r.ofs)
+		r.ofs += int64(n)
+		if n > 0 && err == io.EOF {
+			err = nil
+		}
+		return n, err
+	}
+
+	if len(r.buf) == 0 {
+		if r.ofs >= r.descr.NumChar {
+			return 0, io.EOF
+		}
+		chunk, numChar, err := r.descr.ReadChunk(r.ofs, math.MaxInt32)
+		if err != nil {
+			return 0, err
+		}
+		if numChar == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+		r.ofs += numChar
+		r.buf = chunk
+	}
+	n := copy(b, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// ReadAt implements the io.ReaderAt interface (binary lobs only).
+func (r *LobReader) ReadAt(b []byte, off int64) (int, error) {
+	if err := r.checkRead(); err != nil {
+		return 0, err
+	}
+	if r.descr == nil {
+		return 0, io.EOF
+	}
+	if r.descr.IsCharBased {
+		return 0, fmt.Errorf("lob: random access is not supported for character based lobs")
+	}
+	if off < 0 {
+		return 0, fmt.Errorf("lob: invalid offset %d", off)
+	}
+
+	n := 0
+	for n < len(b) {
+		if off+int64(n) >= r.descr.NumChar {
+			return n, io.EOF
+		}
+		size := len(b) - n
+		if size > math.MaxInt32 {
+			size = math.MaxInt32
+		}
+		chunk, numByte, err := r.descr.ReadChunk(off+int64(n), int32(size))
+		if err != nil {
+			return n, err
+		}
+		if numByte == 0 {
+			return n, io.ErrUnexpectedEOF
+		}
+		n += copy(b[n:], chunk)
+	}
+	return n, nil
+}
+
+// Seek implements the io.Seeker interface.
+// Character based lobs can only be rewound (Seek(0, io.SeekStart)).
+func (r *LobReader) Seek(offset int64, whence int) (int64, error) {
+	if err := r.checkRead(); err != nil {
+		return 0, err
+	}
+
+	var ofs int64
+	switch whence {
+	case io.SeekStart:
+		ofs = offset
+	case io.SeekCurrent:
+		ofs = r.ofs + offset
+	case io.SeekEnd:
+		ofs = r.CharLen() + offset
+	default:
+		return 0, fmt.Errorf("lob: invalid whence %d", whence)
+	}
+	if ofs < 0 {
+		return 0, fmt.Errorf("lob: negative position %d", ofs)
+	}
+	if r.IsCharBased() && ofs != 0 {
+		return 0, fmt.Errorf("lob: random access is not supported for character based lobs")
+	}
+	r.ofs = ofs
+	r.buf = nil
+	return ofs, nil
+}
+
+// Close implements the io.Closer interface.
+func (r *LobReader) Close() error {
+	r.closed = true
+	r.buf = nil
+	return nil
 }
@@ -198,6 +198,183 @@ func testLobDelayedScan(db *sql.DB, t *testing.T) {
 
 }
 
+func testLobReader(db *sql.DB, t *testing.T) {
+	const lobSize = 100000
+
+	table := driver.RandomIdentifier("lobReader")
+
+	wrBuf := &bytes.Buffer{}
+	if _, err := wrBuf.ReadFrom(io.LimitReader(randReader{}, lobSize)); err != nil {
+		t.Fatal(err)
+	}
+	content := wrBuf.Bytes()
+
+	// use trancactions:
+	// SQL Error 596 - LOB streaming is not permitted in auto-commit mode
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("create table %s (i integer, b blob, c nclob)", table)); err != nil {
+		t.Fatalf("create table failed: %s", err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("insert into %s values (1, ?, ?)", table), driver.NewLob(bytes.NewReader(content), nil), driver.NewLob(bytes.NewReader([]byte("Hello, 世界")), nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("insert into %s values (2, null, null)", table)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.Query(fmt.Sprintf("select b, c from %s order by i", table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	b, c := &driver.LobReader{}, &driver.LobReader{}
+
+	// lob content
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	if err := rows.Scan(b, c); err != nil {
+		t.Fatal(err)
+	}
+	if b.IsNull() || b.ByteLen() != lobSize || b.CharLen() != lobSize {
+		t.Fatalf("invalid binary lob size: byte length %d character length %d - expected %d", b.ByteLen(), b.CharLen(), lobSize)
+	}
+
+	// read ranges in reverse order
+	for _, r := range []struct{ ofs, size int }{{lobSize - 10, 10}, {50000, 20000}, {3, 1}, {0, 1000}} {
+		p := make([]byte, r.size)
+		if _, err := b.ReadAt(p, int64(r.ofs)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(p, content[r.ofs:r.ofs+r.size]) {
+			t.Fatalf("range offset %d size %d: read content differs", r.ofs, r.size)
+		}
+	}
+	if _, err := b.ReadAt(make([]byte, 10), lobSize-5); err != io.EOF {
+		t.Fatalf("got error %v - expected %s", err, io.EOF)
+	}
+
+	// seek and read sequentially
+	if _, err := b.Seek(-1000, io.SeekEnd); err != nil {
+		t.Fatal(err)
+	}
+	p, err := io.ReadAll(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p, content[lobSize-1000:]) {
+		t.Fatal("sequential read content differs")
+	}
+
+	if c.CharLen() != 9 {
+		t.Fatalf("got character length %d - expected %d", c.CharLen(), 9)
+	}
+	p, err = io.ReadAll(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p) != "Hello, 世界" {
+		t.Fatalf("got %s - expected %s", p, "Hello, 世界")
+	}
+	if _, err := c.ReadAt(p, 1); err == nil {
+		t.Fatal("random access error for character based lob expected")
+	}
+
+	// null lob
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	if err := rows.Scan(b, c); err != nil {
+		t.Fatal(err)
+	}
+	if !b.IsNull() || !c.IsNull() {
+		t.Fatal("null lob expected")
+	}
+
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testLobProgress(db *sql.DB, t *testing.T) {
+	const lobSize = 100000
+
+	table := driver.RandomIdentifier("lobProgress")
+
+	// use trancactions:
+	// SQL Error 596 - LOB streaming is not permitted in auto-commit mode
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(fmt.Sprintf("create table %s (b1 blob, b2 blob)", table)); err != nil {
+		t.Fatalf("create table failed: %s", err)
+	}
+
+	var n1, n2 int64
+	lob1 := driver.NewLob(io.LimitReader(randReader{}, lobSize), nil).SetProgress(func(n int64) { n1 = n })
+	lob2 := driver.NewLob(io.LimitReader(randReader{}, 2*lobSize), nil).SetProgress(func(n int64) { n2 = n })
+
+	if _, err := tx.Exec(fmt.Sprintf("insert into %s values (?, ?)", table), lob1, lob2); err != nil {
+		t.Fatal(err)
+	}
+	if n1 != lobSize || n2 != 2*lobSize {
+		t.Fatalf("got progress %d %d - expected %d %d", n1, n2, lobSize, 2*lobSize)
+	}
+}
+
+// cancelReader cancels the context after the first read.
+type cancelReader struct {
+	cancel  context.CancelFunc
+	numRead int
+}
+
+func (r *cancelReader) Read(b []byte) (int, error) {
+	r.numRead++
+	if r.numRead > 1 {
+		r.cancel()
+	}
+	return rand.Read(b)
+}
+
+func testLobCancel(db *sql.DB, t *testing.T) {
+	table := driver.RandomIdentifier("lobCancel")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// use dedicated connection as the connection is invalidated by the cancellation.
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.Exec(fmt.Sprintf("create table %s (b blob)", table)); err != nil {
+		t.Fatalf("create table failed: %s", err)
+	}
+
+	rd := &cancelReader{cancel: cancel}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("insert into %s values (?)", table), driver.NewLob(rd, nil)); err != context.Canceled {
+		t.Fatalf("got error %v - expected %s", err, context.Canceled)
+	}
+}
+
 func TestLob(t *testing.T) {
 	tests := []struct {
 		name string
@@ -206,6 +383,9 @@ func TestLob(t *testing.T) {
 		{"insert", testLobInsert},
 		{"pipe", testLobPipe},
 		{"delayedScan", testLobDelayedScan},
+		{"reader", testLobReader},
+		{"progress", testLobProgress},
+		{"cancel", testLobCancel},
 	}
 
 	db := sql.OpenDB(driver.NewTestConnector())
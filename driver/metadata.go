@@ -0,0 +1,143 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"fmt"
+
+	p "github.com/SAP/go-hdb/driver/internal/protocol"
+)
+
+// DataType is the type definition for the data types supported by go-hdb.
+// The data type of a field determines the scan type of its values (see sql.ColumnType.ScanType).
+type DataType byte
+
+// Data type constants.
+const (
+	DtUnknown  = DataType(p.DtUnknown)  // unknown data type
+	DtBoolean  = DataType(p.DtBoolean)  // scan type bool
+	DtTinyint  = DataType(p.DtTinyint)  // scan type uint8
+	DtSmallint = DataType(p.DtSmallint) // scan type int16
+	DtInteger  = DataType(p.DtInteger)  // scan type int32
+	DtBigint   = DataType(p.DtBigint)   // scan type int64
+	DtReal     = DataType(p.DtReal)     // scan type float32
+	DtDouble   = DataType(p.DtDouble)   // scan type float64
+	DtDecimal  = DataType(p.DtDecimal)  // scan type Decimal
+	DtTime     = DataType(p.DtTime)     // scan type time.Time
+	DtString   = DataType(p.DtString)   // scan type string
+	DtBytes    = DataType(p.DtBytes)    // scan type []byte
+	DtLob      = DataType(p.DtLob)      // scan type Lob
+	DtRows     = DataType(p.DtRows)     // scan type sql.Rows
+)
+
+func (dt DataType) String() string { return p.DataType(dt).String() }
+
+// DataTypeOfTypeName returns the go-hdb data type of a database type name (e.g. NVARCHAR, DECIMAL)
+// like returned by the database catalog views. DtUnknown is returned for unknown type names.
+func DataTypeOfTypeName(typeName string) DataType {
+	return DataType(p.DataTypeOfTypeName(typeName))
+}
+
+// ColumnDescr describes a result column.
+type ColumnDescr struct {
+	SchemaName  string   // Schema name of the source table (empty for computed columns).
+	TableName   string   // Name of the source table (empty for computed columns).
+	ColumnName  string   // Name of the source table column (empty for computed columns).
+	DisplayName string   // Column name as returned by sql.Rows.Columns.
+	TypeName    string   // Database type name (e.g. NVARCHAR, DECIMAL).
+	DataType    DataType // go-hdb data type.
+	Length      int64    // Maximum length of variable length types (0 otherwise).
+	Precision   int64    // Precision of decimal types (0 otherwise).
+	Scale       int64    // Scale of decimal types (0 otherwise).
+	Nullable    bool     // True if the column may be NULL.
+}
+
+func (d *ColumnDescr) String() string {
+	return fmt.Sprintf("schema %s table %s column %s display name %s type %s length %d precision %d scale %d nullable %t",
+		d.SchemaName, d.TableName, d.ColumnName, d.DisplayName, d.TypeName, d.Length, d.Precision, d.Scale, d.Nullable)
+}
+
+func newColumnDescr(f *p.ResultField) *ColumnDescr {
+	d := &ColumnDescr{
+		SchemaName:  f.SchemaName(),
+		TableName:   f.TableName(),
+		ColumnName:  f.ColumnName(),
+		DisplayName: f.Name(),
+		TypeName:    f.TypeName(),
+		DataType:    DataType(f.DataType()),
+		Nullable:    f.Nullable(),
+	}
+	d.Length, _ = f.TypeLength()
+	d.Precision, d.Scale, _ = f.TypePrecisionScale()
+	return d
+}
+
+// ParameterDescr describes a statement parameter.
+type ParameterDescr struct {
+	Name      string   // Parameter name (procedure calls) or empty.
+	In        bool     // True for input and input-output parameters.
+	Out       bool     // True for output and input-output parameters.
+	TypeName  string   // Database type name (e.g. NVARCHAR, DECIMAL).
+	DataType  DataType // go-hdb data type.
+	Length    int64    // Maximum length of variable length types (0 otherwise).
+	Precision int64    // Precision of decimal types (0 otherwise).
+	Scale     int64    // Scale of decimal types (0 otherwise).
+	Nullable  bool     // True if the parameter may be NULL.
+}
+
+func (d *ParameterDescr) String() string {
+	return fmt.Sprintf("name %s in %t out %t type %s length %d precision %d scale %d nullable %t",
+		d.Name, d.In, d.Out, d.TypeName, d.Length, d.Precision, d.Scale, d.Nullable)
+}
+
+func newParameterDescr(f *p.ParameterField) *ParameterDescr {
+	d := &ParameterDescr{
+		Name:     f.Name(),
+		In:       f.In(),
+		Out:      f.Out(),
+		TypeName: f.TypeName(),
+		DataType: DataType(f.DataType()),
+		Nullable: f.Nullable(),
+	}
+	d.Length, _ = f.TypeLength()
+	d.Precision, d.Scale, _ = f.TypePrecisionScale()
+	return d
+}
+
+// StmtDescr describes a prepared statement.
+type StmtDescr struct {
+	Parameters []*ParameterDescr // Statement parameters in positional order.
+	Columns    []*ColumnDescr    // Result columns (empty if the statement does not return a result set).
+}
+
+func newStmtDescr(pr *prepareResult) *StmtDescr {
+	d := &StmtDescr{
+		Parameters: make([]*ParameterDescr, len(pr.parameterFields)),
+		Columns:    newColumnDescrs(pr.resultFields),
+	}
+	for i, f := range pr.parameterFields {
+		d.Parameters[i] = newParameterDescr(f)
+	}
+	return d
+}
+
+func newColumnDescrs(fields []*p.ResultField) []*ColumnDescr {
+	descrs := make([]*ColumnDescr, len(fields))
+	for i, f := range fields {
+		descrs[i] = newColumnDescr(f)
+	}
+	return descrs
+}
+
+/*
+RowsColumnDescr is implemented by go-hdb query results (driver.Rows) and provides the full
+descriptions of the result columns including the source schema, table and column names.
+
+As database/sql does not give access to the driver rows, the driver connection needs to be used
+directly via sql.Conn.Raw. Please see the example for details.
+*/
+type RowsColumnDescr interface {
+	ColumnDescrs() []*ColumnDescr
+}
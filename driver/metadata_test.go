@@ -0,0 +1,44 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"testing"
+
+	p "github.com/SAP/go-hdb/driver/internal/protocol"
+)
+
+func TestDataTypeNames(t *testing.T) {
+	for dt := DtUnknown; dt <= DtRows; dt++ {
+		if dt.String() != p.DataType(dt).String() {
+			t.Fatalf("data type %d - got %s - expected %s", dt, dt, p.DataType(dt))
+		}
+	}
+	if DtRows.String() != "DtRows" {
+		t.Fatalf("got %s - expected DtRows", DtRows)
+	}
+}
+
+func TestDataTypeOfTypeName(t *testing.T) {
+	testData := []struct {
+		typeName string
+		dt       DataType
+	}{
+		{"BOOLEAN", DtBoolean},
+		{"integer", DtInteger},
+		{"SMALLDECIMAL", DtDecimal},
+		{"SECONDDATE", DtTime},
+		{"NVARCHAR", DtString},
+		{"VARBINARY", DtBytes},
+		{"NCLOB", DtLob},
+		{"UNKNOWN", DtUnknown},
+	}
+
+	for _, d := range testData {
+		if dt := DataTypeOfTypeName(d.typeName); dt != d.dt {
+			t.Fatalf("type name %s - got %s - expected %s", d.typeName, dt, d.dt)
+		}
+	}
+}
@@ -28,6 +28,8 @@ const (
 const (
 	counterBytesRead = iota
 	counterBytesWritten
+	counterTokenRefresh
+	counterTokenRefreshError
 	numCounter
 )
 
@@ -149,11 +151,13 @@ func (m *metrics) stats() Stats {
 		timeStats[i] = th.stats()
 	}
 	return Stats{
-		OpenConnections:  int(m.gauges[gaugeConn].value()),
-		OpenTransactions: int(m.gauges[gaugeTx].value()),
-		OpenStatements:   int(m.gauges[gaugeStmt].value()),
-		BytesRead:        m.counters[counterBytesRead].value(),
-		BytesWritten:     m.counters[counterBytesWritten].value(),
-		TimeStats:        timeStats,
+		OpenConnections:    int(m.gauges[gaugeConn].value()),
+		OpenTransactions:   int(m.gauges[gaugeTx].value()),
+		OpenStatements:     int(m.gauges[gaugeStmt].value()),
+		BytesRead:          m.counters[counterBytesRead].value(),
+		BytesWritten:       m.counters[counterBytesWritten].value(),
+		TokenRefreshes:     m.counters[counterTokenRefresh].value(),
+		TokenRefreshErrors: m.counters[counterTokenRefreshError].value(),
+		TimeStats:          timeStats,
 	}
 }
@@ -0,0 +1,136 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package migrate implements versioned database schema migrations.
+
+Migrations are SQL or SQLScript files named <version>_<name>.up.sql and (optionally) <version>_<name>.down.sql,
+where version is a positive integer defining the order of the migrations. A migration file can contain multiple
+statements separated by semicolons, including SQLScript blocks (e.g. CREATE PROCEDURE ... BEGIN ... END).
+
+The applied migrations are recorded with version, name and checksum in a history table which is created in the
+current schema if it does not exist. While migrating, a table lock prevents concurrent migrations of other instances.
+
+Each migration is executed in a transaction with DDL auto-commit switched off, so that a failing migration is
+rolled back completely. Migrations containing statements which cannot be executed in a transaction can opt out by
+the comment line
+
+	-- migrate:no-transaction
+*/
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/SAP/go-hdb/driver/internal/protocol/scanner"
+)
+
+// noTransactionMarker is the comment line disabling the transaction of a migration script.
+const noTransactionMarker = "-- migrate:no-transaction"
+
+// Script is an up or down migration script.
+type Script struct {
+	Statements    []string
+	NoTransaction bool // Execute statements without transaction.
+}
+
+func newScript(content string) (*Script, error) {
+	stmts, err := scanner.Split(content)
+	if err != nil {
+		return nil, err
+	}
+	s := &Script{Statements: make([]string, len(stmts))}
+	for i, stmt := range stmts {
+		s.Statements[i] = stmt.Query
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == noTransactionMarker {
+			s.NoTransaction = true
+			break
+		}
+	}
+	return s, nil
+}
+
+// Migration is a versioned migration.
+type Migration struct {
+	Version  int64
+	Name     string
+	Checksum string  // SHA-256 checksum of the up script (hex encoded).
+	Up       *Script // Up migration script.
+	Down     *Script // Down migration script (nil if no down script exists).
+}
+
+func (m *Migration) String() string { return fmt.Sprintf("%d_%s", m.Version, m.Name) }
+
+var reFilename = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Load reads the migrations from the root directory of fsys ordered by version.
+// Files not matching the migration file name pattern are ignored.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := map[int64]*Migration{}
+	downs := map[int64]*Script{}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := reFilename.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%s: invalid version %s", entry.Name(), match[1])
+		}
+		b, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		script, err := newScript(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+
+		if match[3] == "down" {
+			if _, ok := downs[version]; ok {
+				return nil, fmt.Errorf("%s: duplicate down migration version %d", entry.Name(), version)
+			}
+			downs[version] = script
+			continue
+		}
+		if _, ok := migrations[version]; ok {
+			return nil, fmt.Errorf("%s: duplicate migration version %d", entry.Name(), version)
+		}
+		checksum := sha256.Sum256(b)
+		migrations[version] = &Migration{Version: version, Name: match[2], Checksum: hex.EncodeToString(checksum[:]), Up: script}
+	}
+
+	for version, script := range downs {
+		m, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("missing up migration for down migration version %d", version)
+		}
+		m.Down = script
+	}
+
+	result := make([]*Migration, 0, len(migrations))
+	for _, m := range migrations {
+		result = append(result, m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"10_add_index.up.sql":     {Data: []byte("-- migrate:no-transaction\ncreate index i1 on t1 (a);")},
+		"2_create_table.up.sql":   {Data: []byte("create table t1 (a integer);\ninsert into t1 values (1);\n")},
+		"2_create_table.down.sql": {Data: []byte("drop table t1;")},
+		"README.md":               {Data: []byte("ignored")},
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 {
+		t.Fatalf("got %d migrations - expected %d", len(migrations), 2)
+	}
+
+	m := migrations[0]
+	if m.Version != 2 || m.Name != "create_table" || m.String() != "2_create_table" {
+		t.Fatalf("invalid migration %s", m)
+	}
+	if !reflect.DeepEqual(m.Up.Statements, []string{"create table t1 (a integer)", "insert into t1 values (1)"}) || m.Up.NoTransaction {
+		t.Fatalf("invalid up script %v", m.Up)
+	}
+	if m.Down == nil || !reflect.DeepEqual(m.Down.Statements, []string{"drop table t1"}) {
+		t.Fatalf("invalid down script %v", m.Down)
+	}
+	if len(m.Checksum) != 64 {
+		t.Fatalf("invalid checksum %s", m.Checksum)
+	}
+
+	m = migrations[1]
+	if m.Version != 10 || m.Down != nil || !m.Up.NoTransaction {
+		t.Fatalf("invalid migration %s", m)
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	testData := []fstest.MapFS{
+		{"1_a.up.sql": {}, "01_b.up.sql": {}},                  // duplicate version
+		{"1_a.down.sql": {}},                                   // missing up migration
+		{"0_a.up.sql": {}},                                     // invalid version
+		{"1_a.up.sql": {Data: []byte("select 'a from dummy")}}, // invalid script
+	}
+
+	for i, fsys := range testData {
+		if _, err := Load(fsys); err == nil {
+			t.Fatalf("%d: expected error", i)
+		}
+	}
+}
+
+func TestMaxOpenConns(t *testing.T) {
+	// the connector is never connected as the pool size is checked first
+	db := sql.OpenDB(driver.NewBasicAuthConnector("localhost:0", "user", "password"))
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	m := New(db, nil, nil)
+	if _, err := m.Up(context.Background(), 0); !errors.Is(err, ErrMaxOpenConns) {
+		t.Fatalf("got error %v - expected %v", err, ErrMaxOpenConns)
+	}
+	if _, err := m.Down(context.Background(), 0); !errors.Is(err, ErrMaxOpenConns) {
+		t.Fatalf("got error %v - expected %v", err, ErrMaxOpenConns)
+	}
+}
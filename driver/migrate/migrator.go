@@ -0,0 +1,347 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+// default values.
+const (
+	defaultHistoryTable = "SCHEMA_MIGRATIONS"
+	defaultLockTimeout  = time.Minute
+)
+
+// minOpenConns is the minimal number of open connections needed by Up and Down:
+// one connection holds the migration lock while the migrations run on another one.
+const minOpenConns = 2
+
+// hdb error codes.
+const (
+	errCodeDuplicateTableName = 288
+)
+
+// ErrChecksum is returned if the checksum of an applied migration does not match the checksum of its up script.
+var ErrChecksum = errors.New("migration checksum mismatch")
+
+// ErrMaxOpenConns is returned by Up and Down if the maximum number of open connections of the database is less than 2.
+var ErrMaxOpenConns = errors.New("maximum number of open connections too small for migration")
+
+// Options are the migration options.
+type Options struct {
+	HistoryTable string        // Name of the history table (default SCHEMA_MIGRATIONS).
+	LockTimeout  time.Duration // Maximum time to wait for the migration lock (default 1 minute).
+	DryRun       bool          // Log the statements instead of executing them.
+	// Logf is called for each migration and (dry run only) statement. Logf defaults to no logging.
+	Logf func(format string, v ...interface{})
+}
+
+// Status is the status of a migration.
+type Status struct {
+	Version          int64
+	Name             string
+	Applied          bool
+	AppliedAt        time.Time // Time the migration was applied (UTC).
+	ChecksumMismatch bool      // Up script was changed after the migration was applied.
+	Missing          bool      // Migration is recorded as applied but does not exist in the migrations.
+}
+
+type historyRecord struct {
+	version   int64
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// A Migrator applies and reverts migrations.
+type Migrator struct {
+	db           *sql.DB
+	migrations   []*Migration
+	historyTable string
+	lockTimeout  time.Duration
+	dryRun       bool
+	logf         func(format string, v ...interface{})
+}
+
+// New returns a new Migrator instance for the migrations (see Load).
+// Up and Down hold the migration lock on a connection of db while running the migrations on another
+// connection. Therefore the maximum number of open connections of db (see sql.DB.SetMaxOpenConns)
+// needs to be unlimited or at least 2.
+func New(db *sql.DB, migrations []*Migration, opts *Options) *Migrator {
+	m := &Migrator{db: db, migrations: migrations, historyTable: defaultHistoryTable, lockTimeout: defaultLockTimeout, logf: func(string, ...interface{}) {}}
+	if opts != nil {
+		if opts.HistoryTable != "" {
+			m.historyTable = opts.HistoryTable
+		}
+		if opts.LockTimeout > 0 {
+			m.lockTimeout = opts.LockTimeout
+		}
+		m.dryRun = opts.DryRun
+		if opts.Logf != nil {
+			m.logf = opts.Logf
+		}
+	}
+	return m
+}
+
+func (m *Migrator) tableIdentifier() string { return driver.Identifier(m.historyTable).String() }
+func (m *Migrator) lockIdentifier() string {
+	return driver.Identifier(m.historyTable + "_LOCK").String()
+}
+
+func (m *Migrator) tableExists(ctx context.Context, name string) (bool, error) {
+	var n int
+	if err := m.db.QueryRowContext(ctx, "select count(*) from sys.tables where schema_name = current_schema and table_name = ?", name).Scan(&n); err != nil {
+		return false, err
+	}
+	return n != 0, nil
+}
+
+func (m *Migrator) createTable(ctx context.Context, name, query string) error {
+	exists, err := m.tableExists(ctx, name)
+	if err != nil || exists {
+		return err
+	}
+	if _, err := m.db.ExecContext(ctx, query); err != nil {
+		var dbErr driver.Error
+		if errors.As(err, &dbErr) && dbErr.Code() == errCodeDuplicateTableName { // created concurrently
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (m *Migrator) createTables(ctx context.Context) error {
+	if err := m.createTable(ctx, m.historyTable, fmt.Sprintf(
+		"create column table %s (version bigint primary key, name nvarchar(256) not null, checksum varchar(64) not null, applied_at timestamp not null, execution_time bigint not null)",
+		m.tableIdentifier(),
+	)); err != nil {
+		return err
+	}
+	return m.createTable(ctx, m.historyTable+"_LOCK", fmt.Sprintf("create column table %s (id integer primary key)", m.lockIdentifier()))
+}
+
+// lock acquires the migration lock. The lock is held by an open transaction and released by the returned function
+// (or by the database in case the connection gets lost).
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("lock table %s in exclusive mode wait %d", m.lockIdentifier(), int(m.lockTimeout.Seconds()))); err != nil {
+		tx.Rollback()
+		conn.Close()
+		return nil, fmt.Errorf("cannot acquire migration lock: %w", err)
+	}
+	return func() {
+		tx.Rollback()
+		conn.Close()
+	}, nil
+}
+
+func (m *Migrator) history(ctx context.Context) (map[int64]*historyRecord, error) {
+	records := map[int64]*historyRecord{}
+	exists, err := m.tableExists(ctx, m.historyTable)
+	if err != nil || !exists { // dry run
+		return records, err
+	}
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf("select version, name, checksum, applied_at from %s", m.tableIdentifier()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r := &historyRecord{}
+		if err := rows.Scan(&r.version, &r.name, &r.checksum, &r.appliedAt); err != nil {
+			return nil, err
+		}
+		records[r.version] = r
+	}
+	return records, rows.Err()
+}
+
+// prepare creates the history tables, acquires the lock and reads the history.
+func (m *Migrator) prepare(ctx context.Context) (map[int64]*historyRecord, func(), error) {
+	unlock := func() {}
+	if !m.dryRun {
+		if maxOpenConns := m.db.Stats().MaxOpenConnections; maxOpenConns != 0 && maxOpenConns < minOpenConns {
+			return nil, nil, fmt.Errorf("%d less than %d: %w", maxOpenConns, minOpenConns, ErrMaxOpenConns)
+		}
+		if err := m.createTables(ctx); err != nil {
+			return nil, nil, err
+		}
+		var err error
+		if unlock, err = m.lock(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	history, err := m.history(ctx)
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return history, unlock, nil
+}
+
+// Status returns the status of the migrations ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]*Status, error) {
+	history, err := m.history(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var status []*Status
+	for _, mg := range m.migrations {
+		s := &Status{Version: mg.Version, Name: mg.Name}
+		if r, ok := history[mg.Version]; ok {
+			s.Applied, s.AppliedAt, s.ChecksumMismatch = true, r.appliedAt, r.checksum != mg.Checksum
+			delete(history, mg.Version)
+		}
+		status = append(status, s)
+	}
+	for _, r := range history {
+		status = append(status, &Status{Version: r.version, Name: r.name, Applied: true, AppliedAt: r.appliedAt, Missing: true})
+	}
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+	return status, nil
+}
+
+// Up applies all pending migrations with a version less or equal than target in version order.
+// If target is less or equal zero all pending migrations are applied.
+// Up returns the applied migrations.
+func (m *Migrator) Up(ctx context.Context, target int64) ([]*Migration, error) {
+	history, unlock, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	for _, mg := range m.migrations {
+		if r, ok := history[mg.Version]; ok && r.checksum != mg.Checksum {
+			return nil, fmt.Errorf("migration %s: %w", mg, ErrChecksum)
+		}
+	}
+
+	var applied []*Migration
+	for _, mg := range m.migrations {
+		if target > 0 && mg.Version > target {
+			break
+		}
+		if _, ok := history[mg.Version]; ok {
+			continue
+		}
+		m.logf("migrate up %s", mg)
+		if err := m.run(ctx, mg.Up, func(ctx context.Context, ex execer, d time.Duration) error {
+			_, err := ex.ExecContext(ctx, fmt.Sprintf("insert into %s values (?, ?, ?, current_utctimestamp, ?)", m.tableIdentifier()), mg.Version, mg.Name, mg.Checksum, d.Milliseconds())
+			return err
+		}); err != nil {
+			return applied, fmt.Errorf("migration %s: %w", mg, err)
+		}
+		applied = append(applied, mg)
+	}
+	return applied, nil
+}
+
+// Down reverts all applied migrations with a version greater than target in reverse version order.
+// Down returns the reverted migrations.
+func (m *Migrator) Down(ctx context.Context, target int64) ([]*Migration, error) {
+	history, unlock, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	var reverted []*Migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mg := m.migrations[i]
+		if mg.Version <= target {
+			break
+		}
+		if _, ok := history[mg.Version]; !ok {
+			continue
+		}
+		if mg.Down == nil {
+			return reverted, fmt.Errorf("migration %s: missing down migration", mg)
+		}
+		m.logf("migrate down %s", mg)
+		if err := m.run(ctx, mg.Down, func(ctx context.Context, ex execer, d time.Duration) error {
+			_, err := ex.ExecContext(ctx, fmt.Sprintf("delete from %s where version = ?", m.tableIdentifier()), mg.Version)
+			return err
+		}); err != nil {
+			return reverted, fmt.Errorf("migration %s: %w", mg, err)
+		}
+		reverted = append(reverted, mg)
+	}
+	return reverted, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func execStatements(ctx context.Context, ex execer, stmts []string) error {
+	for i, stmt := range stmts {
+		if _, err := ex.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// run executes the script and records the result in the history table via record.
+func (m *Migrator) run(ctx context.Context, s *Script, record func(ctx context.Context, ex execer, d time.Duration) error) error {
+	if m.dryRun {
+		for _, stmt := range s.Statements {
+			m.logf("%s", stmt)
+		}
+		return nil
+	}
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	start := time.Now()
+
+	if s.NoTransaction {
+		if err := execStatements(ctx, conn, s.Statements); err != nil {
+			return err
+		}
+		return record(ctx, conn, time.Since(start))
+	}
+
+	if _, err := conn.ExecContext(ctx, "set transaction autocommit ddl off"); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), "set transaction autocommit ddl on")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := execStatements(ctx, tx, s.Statements); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := record(ctx, tx, time.Since(start)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
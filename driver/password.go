@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	p "github.com/SAP/go-hdb/driver/internal/protocol"
+)
+
+// PasswordChangeReason is the reason for a password change request (see Connector.SetPasswordChange).
+type PasswordChangeReason int
+
+// PasswordChangeReason constants.
+const (
+	// PasswordChangeRequired: the user is forced to change the password (e.g. initial password or password reset).
+	PasswordChangeRequired PasswordChangeReason = iota
+	// PasswordExpiring: the password will expire within few days.
+	PasswordExpiring
+)
+
+func (r PasswordChangeReason) String() string {
+	switch r {
+	case PasswordChangeRequired:
+		return "password change required"
+	case PasswordExpiring:
+		return "password expiring"
+	default:
+		return fmt.Sprintf("PasswordChangeReason(%d)", int(r))
+	}
+}
+
+// logonHooks are the callbacks of a connector called during logon.
+type logonHooks struct {
+	passwordChange func(reason PasswordChangeReason, hdbErr Error) (newPassword string, ok bool)
+	logonWarning   func(warning Error)
+	setPassword    func(password string) // stores the changed password in the connector
+}
+
+// passwordChangeError returns the database error if err is a forced password change error.
+func passwordChangeError(err error) (*p.HdbErrors, bool) {
+	var hdbErrors *p.HdbErrors
+	if errors.As(err, &hdbErrors) && hdbErrors.Code() == p.HdbErrForcePasswordChange {
+		return hdbErrors, true
+	}
+	return nil, false
+}
+
+func isPasswordChangeError(err error) bool { _, ok := passwordChangeError(err); return ok }
+
+// alterPasswordStmt returns the statement changing the password of the session user.
+// The password is always quoted to preserve case and special characters.
+func alterPasswordStmt(password string) string {
+	return fmt.Sprintf(`alter password "%s"`, strings.ReplaceAll(password, `"`, `""`))
+}
+
+// changePassword changes the password of the session user. The statement is executed directly
+// (bypassing sql trace) as it contains the password.
+func (c *conn) changePassword(password string, hooks *logonHooks) error {
+	if _, err := c._execDirect(alterPasswordStmt(password), true); err != nil {
+		return err
+	}
+	if hooks.setPassword != nil {
+		hooks.setPassword(password)
+	}
+	return nil
+}
+
+// handleLogon handles forced password changes (logonErr) and logon warnings.
+func (c *conn) handleLogon(hooks *logonHooks, logonErr error) error {
+	warnings := c.logonWarnings
+	c.logonWarnings = nil
+	return handleLogon(hooks, logonErr, warnings, func(password string) error { return c.changePassword(password, hooks) })
+}
+
+// handleLogon calls the logon hooks for a forced password change (logonErr) and the logon warnings.
+// The password is changed by changePassword if requested by the password change hook.
+func handleLogon(hooks *logonHooks, logonErr error, warnings *p.HdbErrors, changePassword func(password string) error) error {
+	if logonErr != nil { // forced password change
+		hdbErr, ok := passwordChangeError(logonErr)
+		if !ok || hooks.passwordChange == nil {
+			return logonErr
+		}
+		password, ok := hooks.passwordChange(PasswordChangeRequired, hdbErr)
+		if !ok {
+			return logonErr
+		}
+		if err := changePassword(password); err != nil {
+			return fmt.Errorf("%s: %w", PasswordChangeRequired, err)
+		}
+	}
+
+	if warnings == nil {
+		return nil
+	}
+	if hooks.logonWarning != nil {
+		hooks.logonWarning(warnings)
+	}
+	if hooks.passwordChange == nil {
+		return nil
+	}
+	for i := 0; i < warnings.NumError(); i++ {
+		warnings.SetIdx(i)
+		if warnings.Code() != p.HdbWarnPasswordExpiring {
+			continue
+		}
+		password, ok := hooks.passwordChange(PasswordExpiring, warnings)
+		if !ok {
+			return nil
+		}
+		if err := changePassword(password); err != nil {
+			return fmt.Errorf("%s: %w", PasswordExpiring, err)
+		}
+		return nil
+	}
+	return nil
+}
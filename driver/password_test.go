@@ -0,0 +1,230 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver/dial"
+	p "github.com/SAP/go-hdb/driver/internal/protocol"
+)
+
+func TestAlterPasswordStmt(t *testing.T) {
+	tests := []struct {
+		password string
+		stmt     string
+	}{
+		{"Secret123", `alter password "Secret123"`},
+		{"lower case", `alter password "lower case"`},
+		{`Quote"d`, `alter password "Quote""d"`},
+	}
+
+	for _, test := range tests {
+		if stmt := alterPasswordStmt(test.password); stmt != test.stmt {
+			t.Fatalf("password %s: statement %s - expected %s", test.password, stmt, test.stmt)
+		}
+	}
+}
+
+func TestPasswordChange(t *testing.T) {
+	if isPasswordChangeError(errors.New("test error")) {
+		t.Fatal("unexpected password change error")
+	}
+	if PasswordChangeRequired.String() != "password change required" || PasswordExpiring.String() != "password expiring" {
+		t.Fatal("unexpected reason string")
+	}
+
+	c := NewBasicAuthConnector("localhost:39013", "USER", "Initial1")
+	c.SetPasswordChange(func(reason PasswordChangeReason, hdbErr Error) (string, bool) { return "Changed1", true })
+
+	hooks := c.authAttrs.logonHooks()
+	if hooks.passwordChange == nil || hooks.logonWarning != nil {
+		t.Fatal("unexpected logon hooks")
+	}
+	// password changed during logon is used by the connector
+	hooks.setPassword("Changed1")
+	if c.Password() != "Changed1" {
+		t.Fatalf("password %s - expected %s", c.Password(), "Changed1")
+	}
+	// no logon error and warnings
+	if err := (&conn{}).handleLogon(hooks, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type testLogonHooks struct {
+	reasons  []PasswordChangeReason
+	codes    []int
+	warnings int
+	ok       bool
+	changed  []string
+	err      error
+}
+
+func (h *testLogonHooks) hooks() *logonHooks {
+	return &logonHooks{
+		passwordChange: func(reason PasswordChangeReason, hdbErr Error) (string, bool) {
+			h.reasons = append(h.reasons, reason)
+			h.codes = append(h.codes, hdbErr.Code())
+			return "Changed1", h.ok
+		},
+		logonWarning: func(warning Error) { h.warnings++ },
+	}
+}
+
+func (h *testLogonHooks) changePassword(password string) error {
+	h.changed = append(h.changed, password)
+	return h.err
+}
+
+func testHandleLogonForcedChange(t *testing.T) {
+	logonErr := fmt.Errorf("logon: %w", p.NewHdbErrors(p.HdbErrForcePasswordChange, false, "user is forced to change password"))
+	if !isPasswordChangeError(logonErr) {
+		t.Fatal("password change error expected")
+	}
+
+	// password changed
+	h := &testLogonHooks{ok: true}
+	if err := handleLogon(h.hooks(), logonErr, nil, h.changePassword); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h.reasons, []PasswordChangeReason{PasswordChangeRequired}) || !reflect.DeepEqual(h.codes, []int{p.HdbErrForcePasswordChange}) {
+		t.Fatalf("reasons %v codes %v - expected %v %v", h.reasons, h.codes, PasswordChangeRequired, p.HdbErrForcePasswordChange)
+	}
+	if !reflect.DeepEqual(h.changed, []string{"Changed1"}) {
+		t.Fatalf("changed passwords %v - expected %v", h.changed, []string{"Changed1"})
+	}
+
+	// password change declined by hook
+	h = &testLogonHooks{ok: false}
+	if err := handleLogon(h.hooks(), logonErr, nil, h.changePassword); err != logonErr {
+		t.Fatalf("error %v - expected %v", err, logonErr)
+	}
+	if len(h.changed) != 0 {
+		t.Fatal("unexpected password change")
+	}
+
+	// no password change hook
+	if err := handleLogon(&logonHooks{}, logonErr, nil, h.changePassword); err != logonErr {
+		t.Fatalf("error %v - expected %v", err, logonErr)
+	}
+
+	// password change failed
+	h = &testLogonHooks{ok: true, err: errors.New("alter password failed")}
+	if err := handleLogon(h.hooks(), logonErr, nil, h.changePassword); !errors.Is(err, h.err) {
+		t.Fatalf("error %v - expected %v", err, h.err)
+	}
+
+	// other logon errors are returned unchanged
+	otherErr := p.NewHdbErrors(10, false, "authentication failed")
+	h = &testLogonHooks{ok: true}
+	if err := handleLogon(h.hooks(), otherErr, nil, h.changePassword); err != otherErr || len(h.reasons) != 0 {
+		t.Fatalf("error %v reasons %v - expected %v", err, h.reasons, otherErr)
+	}
+}
+
+func testHandleLogonExpiryWarning(t *testing.T) {
+	warnings := p.NewHdbErrors(p.HdbWarnPasswordExpiring, true, "user's password will expire within few days")
+
+	// password changed
+	h := &testLogonHooks{ok: true}
+	if err := handleLogon(h.hooks(), nil, warnings, h.changePassword); err != nil {
+		t.Fatal(err)
+	}
+	if h.warnings != 1 {
+		t.Fatalf("number of logon warning calls %d - expected 1", h.warnings)
+	}
+	if !reflect.DeepEqual(h.reasons, []PasswordChangeReason{PasswordExpiring}) || !reflect.DeepEqual(h.codes, []int{p.HdbWarnPasswordExpiring}) {
+		t.Fatalf("reasons %v codes %v - expected %v %v", h.reasons, h.codes, PasswordExpiring, p.HdbWarnPasswordExpiring)
+	}
+	if !reflect.DeepEqual(h.changed, []string{"Changed1"}) {
+		t.Fatalf("changed passwords %v - expected %v", h.changed, []string{"Changed1"})
+	}
+
+	// password change declined by hook: logon succeeds
+	h = &testLogonHooks{ok: false}
+	if err := handleLogon(h.hooks(), nil, warnings, h.changePassword); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.changed) != 0 || h.warnings != 1 {
+		t.Fatalf("changed passwords %v warnings %d - expected none and 1", h.changed, h.warnings)
+	}
+
+	// password change failed
+	h = &testLogonHooks{ok: true, err: errors.New("alter password failed")}
+	if err := handleLogon(h.hooks(), nil, warnings, h.changePassword); !errors.Is(err, h.err) {
+		t.Fatalf("error %v - expected %v", err, h.err)
+	}
+
+	// other warnings do not trigger a password change
+	h = &testLogonHooks{ok: true}
+	if err := handleLogon(h.hooks(), nil, p.NewHdbErrors(1000, true, "other warning"), h.changePassword); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.reasons) != 0 || h.warnings != 1 {
+		t.Fatalf("reasons %v warnings %d - expected none and 1", h.reasons, h.warnings)
+	}
+}
+
+func TestHandleLogon(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"forcedChange", testHandleLogonForcedChange},
+		{"expiryWarning", testHandleLogonExpiryWarning},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
+
+// testCloseConn records whether the connection was closed.
+type testCloseConn struct {
+	net.Conn
+	closed bool
+}
+
+func (c *testCloseConn) Close() error { c.closed = true; return c.Conn.Close() }
+
+type testPipeDialer struct {
+	client net.Conn
+}
+
+func (d *testPipeDialer) DialContext(ctx context.Context, address string, options dial.DialerOptions) (net.Conn, error) {
+	return d.client, nil
+}
+
+func TestNewConnClose(t *testing.T) {
+	client, server := net.Pipe()
+	conn := &testCloseConn{Conn: client}
+
+	c := NewBasicAuthConnector("localhost:39013", "USER", "Initial1")
+	c.SetDialer(&testPipeDialer{client: conn})
+
+	// server terminates the connection while reading the prolog reply
+	go func() {
+		buf := make([]byte, 14)
+		io.ReadFull(server, buf)
+		server.Write([]byte{0xff})
+		server.Close()
+	}()
+
+	if _, err := c.Connect(context.Background()); err == nil {
+		t.Fatal("connect error expected")
+	}
+	if !conn.closed {
+		t.Fatal("connection not closed after connect error")
+	}
+}
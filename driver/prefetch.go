@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"database/sql/driver"
+
+	p "github.com/SAP/go-hdb/driver/internal/protocol"
+)
+
+// resultsetChunk is a chunk of resultset rows fetched from the database.
+type resultsetChunk struct {
+	fieldValues  []driver.Value
+	decodeErrors p.DecodeErrors
+	attributes   p.PartAttributes
+	numByte      int
+	err          error
+}
+
+/*
+prefetcher fetches resultset chunks in advance (read-ahead).
+
+Connection lock:
+  - as long as a query result is open the connection lock is held by the rows (see rows.onClose)
+  - the prefetcher is the only user of the connection while running
+  - closing the query result stops the prefetcher and waits for a pending fetch
+    before the resultset is closed and the connection lock is released
+*/
+type prefetcher struct {
+	chunks chan *resultsetChunk
+	stop   chan struct{}
+	done   chan struct{}
+	last   *resultsetChunk // last fetched chunk (valid after done is closed)
+}
+
+func newPrefetcher(c *conn, rsID uint64, fields []*p.ResultField, fs fetchSizer, size int) *prefetcher {
+	pf := &prefetcher{
+		chunks: make(chan *resultsetChunk, size),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go pf.run(c, rsID, fields, fs)
+	return pf
+}
+
+func (pf *prefetcher) run(c *conn, rsID uint64, fields []*p.ResultField, fs fetchSizer) {
+	defer close(pf.done)
+	defer close(pf.chunks)
+
+	for {
+		chunk := &resultsetChunk{}
+		chunk.err = c._fetchChunk(rsID, fields, fs.fetchSize, chunk)
+		pf.last = chunk
+
+		select {
+		case pf.chunks <- chunk:
+		case <-pf.stop:
+			return
+		}
+
+		if chunk.err != nil || chunk.attributes.LastPacket() || len(chunk.fieldValues) == 0 {
+			return
+		}
+		fs.adapt(chunk.numByte, len(chunk.fieldValues)/len(fields))
+	}
+}
+
+// next returns the next prefetched chunk or nil if no further chunk is available.
+func (pf *prefetcher) next() *resultsetChunk { return <-pf.chunks }
+
+// close stops prefetching, waits for a pending fetch and returns the last fetched chunk.
+func (pf *prefetcher) close() *resultsetChunk {
+	close(pf.stop)
+	<-pf.done
+	return pf.last
+}
@@ -0,0 +1,122 @@
+//go:build !unit
+// +build !unit
+
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/SAP/go-hdb/driver"
+)
+
+const prefetchNumRow = 1000
+
+func testPrefetchCreateTable(db *sql.DB, t *testing.T) driver.Identifier {
+	table := driver.RandomIdentifier("prefetch")
+
+	if _, err := db.Exec(fmt.Sprintf("create table %s (i integer, s nvarchar(20))", table)); err != nil {
+		t.Fatalf("create table failed: %s", err)
+	}
+	stmt, err := db.Prepare(fmt.Sprintf("insert into %s values (?, ?)", table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	for i := 0; i < prefetchNumRow; i++ {
+		if _, err := stmt.Exec(i, fmt.Sprintf("row %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return table
+}
+
+func testPrefetchRead(db *sql.DB, t *testing.T) {
+	table := testPrefetchCreateTable(db, t)
+
+	rows, err := db.Query(fmt.Sprintf("select * from %s order by i", table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var i int
+	var s string
+	numRow := 0
+	for rows.Next() {
+		if err := rows.Scan(&i, &s); err != nil {
+			t.Fatal(err)
+		}
+		if i != numRow || s != fmt.Sprintf("row %d", numRow) {
+			t.Fatalf("got %d %s - expected %d", i, s, numRow)
+		}
+		numRow++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if numRow != prefetchNumRow {
+		t.Fatalf("got %d rows - expected %d", numRow, prefetchNumRow)
+	}
+}
+
+func testPrefetchEarlyClose(db *sql.DB, t *testing.T) {
+	table := testPrefetchCreateTable(db, t)
+
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx) // guarantee that same connection is used
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	for j := 0; j < 3; j++ {
+		rows, err := conn.QueryContext(ctx, fmt.Sprintf("select * from %s", table))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k := 0; k < 25 && rows.Next(); k++ { // close while prefetching
+		}
+		if err := rows.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// connection must still be usable
+	var numRow int
+	if err := conn.QueryRowContext(ctx, fmt.Sprintf("select count(*) from %s", table)).Scan(&numRow); err != nil {
+		t.Fatal(err)
+	}
+	if numRow != prefetchNumRow {
+		t.Fatalf("got %d rows - expected %d", numRow, prefetchNumRow)
+	}
+}
+
+func TestPrefetch(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(db *sql.DB, t *testing.T)
+	}{
+		{"read", testPrefetchRead},
+		{"earlyClose", testPrefetchEarlyClose},
+	}
+
+	connector := driver.NewTestConnector()
+	connector.SetFetchSize(10)
+	connector.SetPrefetch(3)
+	db := sql.OpenDB(connector)
+	defer db.Close()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(db, t)
+		})
+	}
+}
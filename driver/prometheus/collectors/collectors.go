@@ -32,6 +32,8 @@ type collector struct {
 	openStatements   *prometheus.Desc
 	readBytes        *prometheus.Desc
 	writtenBytes     *prometheus.Desc
+	tokenRefreshes   *prometheus.Desc
+	tokenErrors      *prometheus.Desc
 	timeStats        *prometheus.Desc
 }
 
@@ -70,6 +72,18 @@ func newCollector(s stats, subsystem string, labels prometheus.Labels) prometheu
 			nil,
 			labels,
 		),
+		tokenRefreshes: prometheus.NewDesc(
+			fqName("token_refreshes"),
+			fmt.Sprintf("The total number of JWT token refreshes of %s.", subsystem),
+			nil,
+			labels,
+		),
+		tokenErrors: prometheus.NewDesc(
+			fqName("token_refresh_errors"),
+			fmt.Sprintf("The total number of failed JWT token refreshes of %s.", subsystem),
+			nil,
+			labels,
+		),
 		timeStats: prometheus.NewDesc(
 			fqName("time_stats"),
 			fmt.Sprintf("The spent time measured in milliseconds for the different time categories of %s.", subsystem),
@@ -86,6 +100,8 @@ func (c *collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.openStatements
 	ch <- c.readBytes
 	ch <- c.writtenBytes
+	ch <- c.tokenRefreshes
+	ch <- c.tokenErrors
 	for i := 0; i < statsNumTime; i++ {
 		ch <- c.timeStats
 	}
@@ -107,6 +123,8 @@ func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.openStatements, prometheus.GaugeValue, float64(stats.OpenStatements))
 	ch <- prometheus.MustNewConstMetric(c.readBytes, prometheus.CounterValue, float64(stats.BytesRead))
 	ch <- prometheus.MustNewConstMetric(c.writtenBytes, prometheus.CounterValue, float64(stats.BytesWritten))
+	ch <- prometheus.MustNewConstMetric(c.tokenRefreshes, prometheus.CounterValue, float64(stats.TokenRefreshes))
+	ch <- prometheus.MustNewConstMetric(c.tokenErrors, prometheus.CounterValue, float64(stats.TokenRefreshErrors))
 	for i, timeStat := range stats.TimeStats {
 		ch <- prometheus.MustNewConstHistogram(c.timeStats, timeStat.Count, float64(timeStat.Sum), timeStatBuckets(timeStat), statsTimeTexts[i])
 	}
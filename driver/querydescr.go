@@ -84,6 +84,7 @@ func (d *queryDescr) String() string {
 }
 
 // NewQueryDescr returns a new QueryDescr instance.
+// Leading whitespaces and comments are skipped and cut off the query together with the bulk keyword.
 func newQueryDescr(query string, sc *scanner.Scanner) (*queryDescr, error) {
 	d := &queryDescr{query: query}
 
@@ -111,7 +112,7 @@ func newQueryDescr(query string, sc *scanner.Scanner) (*queryDescr, error) {
 	}
 
 	// command
-	d.query = query[start:] // cut off whitespaces and bulk
+	d.query = query[start:] // cut off whitespaces, comments and bulk
 
 	// result set id query
 	if d.kind == qkID {
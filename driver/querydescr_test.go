@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/SAP/go-hdb/driver/internal/protocol/scanner"
+)
+
+func TestQueryDescr(t *testing.T) {
+	testData := []struct {
+		query  string
+		kind   queryKind
+		isBulk bool
+		id     uint64
+		cmd    string
+	}{
+		{"select * from dummy", qkSelect, false, 0, "select * from dummy"},
+		{"  \n\tinsert into t values (?)", qkInsert, false, 0, "insert into t values (?)"},
+		{"bulk insert into t values (?)", qkInsert, true, 0, "insert into t values (?)"},
+		{"-- comment\nselect * from dummy", qkSelect, false, 0, "select * from dummy"},
+		{"/* comment */ call p(?)", qkCall, false, 0, "call p(?)"},
+		{"/* a */ -- b\n /* c */ bulk /* d */ upsert t values (?)", qkUpsert, true, 0, "upsert t values (?)"},
+		{"id 42", qkID, false, 42, "id 42"},
+		{"grant select on t to u", qkUnknown, false, 0, "grant select on t to u"},
+	}
+
+	sc := &scanner.Scanner{}
+	for _, d := range testData {
+		qd, err := newQueryDescr(d.query, sc)
+		if err != nil {
+			t.Fatalf("%q: %s", d.query, err)
+		}
+		if qd.kind != d.kind || qd.isBulk != d.isBulk || qd.id != d.id || qd.query != d.cmd {
+			t.Fatalf("%q: got %s id %d - expected kind %s isBulk %t id %d query %q", d.query, qd, qd.id, d.kind, d.isBulk, d.id, d.cmd)
+		}
+	}
+
+	for _, query := range []string{"", "-- comment only", "/* unterminated comment select * from dummy", "? = call p", "id x"} {
+		if _, err := newQueryDescr(query, sc); err == nil {
+			t.Fatalf("%q: expected error", query)
+		}
+	}
+}
@@ -26,6 +26,7 @@ var (
 	_ driver.RowsColumnTypePrecisionScale   = (*queryResult)(nil)
 	_ driver.RowsColumnTypeScanType         = (*queryResult)(nil)
 	_ driver.RowsNextResultSet              = (*queryResult)(nil)
+	_ RowsColumnDescr                       = (*queryResult)(nil)
 
 	_ driver.Rows                           = (*callResult)(nil)
 	_ driver.RowsColumnTypeDatabaseTypeName = (*callResult)(nil)
@@ -106,17 +107,21 @@ func (r *noResultType) Next(dest []driver.Value) error { return io.EOF }
 // queryResult represents the resultset of a query.
 type queryResult struct {
 	// field alignment
-	fields       []*p.ResultField
-	fieldValues  []driver.Value
-	decodeErrors p.DecodeErrors
-	_columns     []string
-	lastErr      error
-	conn         *conn
-	rsID         uint64
-	pos          int
-	_onClose     func()
-	attributes   p.PartAttributes
-	closed       bool
+	fields          []*p.ResultField
+	fieldValues     []driver.Value
+	decodeErrors    p.DecodeErrors
+	_columns        []string
+	lastErr         error
+	conn            *conn
+	rsID            uint64
+	pos             int
+	_onClose        func()
+	attributes      p.PartAttributes
+	closed          bool
+	prefetcher      *prefetcher
+	prefetchChecked bool
+	fetchSizer      fetchSizer
+	numByte         int // size of current resultset chunk in bytes
 }
 
 // onClose implements the onCloser interface
@@ -144,6 +149,16 @@ func (qr *queryResult) Close() error {
 	}
 	qr.closed = true
 
+	if qr.prefetcher != nil {
+		if last := qr.prefetcher.close(); last != nil {
+			qr.attributes = last.attributes
+			if last.err != nil {
+				qr.lastErr = last.err
+			}
+		}
+		qr.prefetcher = nil
+	}
+
 	if qr.attributes.ResultsetClosed() {
 		return nil
 	}
@@ -166,13 +181,57 @@ func (qr *queryResult) copyRow(idx int, dest []driver.Value) {
 	copy(dest, qr.fieldValues[idx*cols:(idx+1)*cols])
 }
 
+func (qr *queryResult) hasLob() bool {
+	for _, f := range qr.fields {
+		if f.IsLob() {
+			return true
+		}
+	}
+	return false
+}
+
+// startPrefetch starts prefetching if enabled and applicable.
+func (qr *queryResult) startPrefetch() {
+	qr.prefetchChecked = true // check only once
+	if qr.conn.prefetch == 0 || qr.closed || qr.attributes.LastPacket() || qr.hasLob() {
+		return
+	}
+	fs := qr.fetchSizer
+	fs.adapt(qr.numByte, qr.numRow())
+	qr.prefetcher = newPrefetcher(qr.conn, qr.rsID, qr.fields, fs, qr.conn.prefetch)
+}
+
+func (qr *queryResult) fetchNext() error {
+	if qr.prefetcher == nil {
+		qr.fetchSizer.adapt(qr.numByte, qr.numRow())
+		return qr.conn._fetchNext(qr)
+	}
+	chunk := qr.prefetcher.next()
+	if chunk == nil { // no further chunk
+		qr.fieldValues = nil
+		return nil
+	}
+	if chunk.err != nil {
+		return chunk.err
+	}
+	qr.fieldValues = chunk.fieldValues
+	qr.decodeErrors = chunk.decodeErrors
+	qr.attributes = chunk.attributes
+	qr.numByte = chunk.numByte
+	return nil
+}
+
 // Next implements the driver.Rows interface.
 func (qr *queryResult) Next(dest []driver.Value) error {
+	if !qr.prefetchChecked {
+		qr.startPrefetch()
+	}
+
 	if qr.pos >= qr.numRow() {
 		if qr.attributes.LastPacket() {
 			return io.EOF
 		}
-		if err := qr.conn._fetchNext(qr); err != nil {
+		if err := qr.fetchNext(); err != nil {
 			qr.lastErr = err //fieldValues and attrs are nil
 			return err
 		}
@@ -191,10 +250,24 @@ func (qr *queryResult) Next(dest []driver.Value) error {
 		if v, ok := v.(p.LobsDecoderSetter); ok {
 			v.SetLobsDecoder(qr.conn.decodeLobs)
 		}
+		if v, ok := v.(p.LobReaderSetter); ok {
+			v.SetLobReader(qr.readLob)
+		}
 	}
 	return err
 }
 
+// readLob reads lob chunks as long as the query result is open.
+func (qr *queryResult) readLob(descr *p.LobOutDescr, ofs int64, size int32) ([]byte, int64, error) {
+	if qr.closed {
+		return nil, 0, errLobReaderClosed
+	}
+	return qr.conn.readLob(descr, ofs, size)
+}
+
+// ColumnDescrs implements the RowsColumnDescr interface.
+func (qr *queryResult) ColumnDescrs() []*ColumnDescr { return newColumnDescrs(qr.fields) }
+
 // ColumnTypeDatabaseTypeName implements the driver.RowsColumnTypeDatabaseTypeName interface.
 func (qr *queryResult) ColumnTypeDatabaseTypeName(idx int) string { return qr.fields[idx].TypeName() }
 
@@ -272,10 +345,21 @@ func (cr *callResult) Next(dest []driver.Value) error {
 		if v, ok := v.(p.LobsDecoderSetter); ok {
 			v.SetLobsDecoder(cr.conn.decodeLobs)
 		}
+		if v, ok := v.(p.LobReaderSetter); ok {
+			v.SetLobReader(cr.readLob)
+		}
 	}
 	return err
 }
 
+// readLob reads lob chunks as long as the call result is open.
+func (cr *callResult) readLob(descr *p.LobOutDescr, ofs int64, size int32) ([]byte, int64, error) {
+	if cr.closed {
+		return nil, 0, errLobReaderClosed
+	}
+	return cr.conn.readLob(descr, ofs, size)
+}
+
 // Close implements the driver.Rows interface.
 func (cr *callResult) Close() error {
 	if !cr.closed && cr._onClose != nil {
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlscript_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/SAP/go-hdb/driver/sqlscript"
+)
+
+// ExampleSplit demonstrates the splitting of a SQL script into statements.
+func ExampleSplit() {
+	const script = `
+-- create table
+create table t (a integer);
+
+create procedure p as
+begin
+  insert into t values (1);
+end;`
+
+	stmts, err := sqlscript.Split(script)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, stmt := range stmts {
+		fmt.Printf("line %d: %s\n", stmt.Line, stmt.Query)
+	}
+
+	// output: line 3: create table t (a integer)
+	// line 5: create procedure p as
+	// begin
+	//   insert into t values (1);
+	// end
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlscript
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// An Execer is implemented by sql.DB, sql.Conn and sql.Tx.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Options are the script execution options.
+type Options struct {
+	// ContinueOnError continues the script execution with the next statement in case of an error.
+	// Default: the execution stops at the first failing statement.
+	ContinueOnError bool
+	// Report is called after the execution of each statement with the statement execution error (nil on success).
+	Report func(stmt *Statement, err error)
+}
+
+// StmtError is the execution error of a script statement.
+type StmtError struct {
+	Stmt *Statement
+	Err  error
+}
+
+func (e *StmtError) Error() string { return fmt.Sprintf("line %d: %s", e.Stmt.Line, e.Err) }
+
+// Unwrap returns the statement execution error.
+func (e *StmtError) Unwrap() error { return e.Err }
+
+// Errors is the list of statement errors returned by Exec in case the execution is continued on error.
+type Errors []*StmtError
+
+func (e Errors) Error() string {
+	errs := make([]string, len(e))
+	for i, err := range e {
+		errs[i] = err.Error()
+	}
+	return strings.Join(errs, "\n")
+}
+
+// Exec splits the script into statements and executes them in order.
+// Exec returns the number of successfully executed statements.
+// The error is a *StmtError if the execution stopped at a failing statement or
+// an Errors list of all failing statements if the execution continued on error (see Options).
+// A canceled context stops the execution in both cases.
+func Exec(ctx context.Context, db Execer, script string, opts *Options) (int, error) {
+	stmts, err := Split(script)
+	if err != nil {
+		return 0, err
+	}
+	return ExecStatements(ctx, db, stmts, opts)
+}
+
+// ExecStatements executes statements returned by Split (see Exec).
+func ExecStatements(ctx context.Context, db Execer, stmts []*Statement, opts *Options) (int, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	numExec := 0
+	var errs Errors
+	for _, stmt := range stmts {
+		if err := ctx.Err(); err != nil {
+			if errs != nil {
+				return numExec, append(errs, &StmtError{Stmt: stmt, Err: err})
+			}
+			return numExec, &StmtError{Stmt: stmt, Err: err}
+		}
+
+		_, err := db.ExecContext(ctx, stmt.Query)
+		if opts.Report != nil {
+			opts.Report(stmt, err)
+		}
+		if err == nil {
+			numExec++
+			continue
+		}
+		stmtErr := &StmtError{Stmt: stmt, Err: err}
+		if !opts.ContinueOnError {
+			return numExec, stmtErr
+		}
+		errs = append(errs, stmtErr)
+	}
+	if errs != nil {
+		return numExec, errs
+	}
+	return numExec, nil
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package sqlscript
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testExecer struct {
+	queries []string
+}
+
+var errTestExec = errors.New("exec error")
+
+func (e *testExecer) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	e.queries = append(e.queries, query)
+	if strings.HasPrefix(query, "fail") {
+		return nil, errTestExec
+	}
+	return nil, nil
+}
+
+const testScript = `create table t (a integer);
+fail 1;
+create procedure p as
+begin
+  insert into t values (1);
+end;
+fail 2;
+insert into t values (2)`
+
+func TestExecStop(t *testing.T) {
+	e := &testExecer{}
+	n, err := Exec(context.Background(), e, testScript, nil)
+	if n != 1 || len(e.queries) != 2 {
+		t.Fatalf("executed %d statements, %d queries - expected 1 and 2", n, len(e.queries))
+	}
+	var stmtErr *StmtError
+	if !errors.As(err, &stmtErr) || stmtErr.Stmt.Line != 2 || !errors.Is(err, errTestExec) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestExecContinue(t *testing.T) {
+	e := &testExecer{}
+	var reported []int
+	opts := &Options{
+		ContinueOnError: true,
+		Report:          func(stmt *Statement, err error) { reported = append(reported, stmt.Line) },
+	}
+	n, err := Exec(context.Background(), e, testScript, opts)
+	if n != 3 || len(e.queries) != 5 || len(reported) != 5 {
+		t.Fatalf("executed %d statements, %d queries, %d reported - expected 3, 5 and 5", n, len(e.queries), len(reported))
+	}
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 2 || errs[0].Stmt.Line != 2 || errs[1].Stmt.Line != 7 {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestExecCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	e := &testExecer{}
+	if _, err := Exec(ctx, e, testScript, nil); !errors.Is(err, context.Canceled) || len(e.queries) != 0 {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package sqlscript implements the processing of SQL scripts consisting of multiple statements.
+
+Statements are separated by semicolons. Semicolons in comments, string literals, quoted identifiers,
+SQLScript blocks (BEGIN ... END) and CASE expressions do not terminate a statement.
+
+Exec executes the statements of a script in order, either stopping at the first failing statement
+or continuing with the next statement and reporting all statement errors.
+*/
+package sqlscript
+
+import (
+	"github.com/SAP/go-hdb/driver/internal/protocol/scanner"
+)
+
+// ErrToken is returned by Split for unterminated comments, string literals and quoted identifiers.
+var ErrToken = scanner.ErrToken
+
+// Statement is a statement of a SQL script.
+type Statement struct {
+	Query      string // Statement text without terminating semicolon.
+	Line       int    // Line number of the statement start in the script (1-based).
+	Terminated bool   // Statement is terminated by a semicolon.
+}
+
+// Split splits a SQL script into statements.
+// Statements only consisting of whitespaces and comments are skipped.
+func Split(script string) ([]*Statement, error) {
+	stmts, err := scanner.Split(script)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Statement, len(stmts))
+	for i, stmt := range stmts {
+		result[i] = &Statement{Query: stmt.Query, Line: stmt.Line, Terminated: stmt.Terminated}
+	}
+	return result, nil
+}
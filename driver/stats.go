@@ -33,8 +33,10 @@ type Stats struct {
 	OpenTransactions int // The number of open driver transactions.
 	OpenStatements   int // The number of open driver database statements.
 	// Counter
-	BytesRead    uint64 // Total bytes read by client connection.
-	BytesWritten uint64 // Total bytes written by client connection.
+	BytesRead          uint64 // Total bytes read by client connection.
+	BytesWritten       uint64 // Total bytes written by client connection.
+	TokenRefreshes     uint64 // Total number of proactive and failure triggered JWT token refreshes.
+	TokenRefreshErrors uint64 // Total number of failed JWT token refreshes.
 	//
 	ReadTime  *TimeStat
 	WriteTime *TimeStat
@@ -49,6 +51,8 @@ func (s Stats) String() string {
 	sb.WriteString(fmt.Sprintf("\nopenStatements   %d", s.OpenStatements))
 	sb.WriteString(fmt.Sprintf("\nbytesRead        %d", s.BytesRead))
 	sb.WriteString(fmt.Sprintf("\nbytesWritten     %d", s.BytesWritten))
+	sb.WriteString(fmt.Sprintf("\ntokenRefreshes   %d", s.TokenRefreshes))
+	sb.WriteString(fmt.Sprintf("\ntokenRefreshErrors %d", s.TokenRefreshErrors))
 	sb.WriteString("\nTimes")
 	for i, timeStat := range s.TimeStats {
 		sb.WriteString(fmt.Sprintf("\n  %-12s %s", statsCfg.TimeTexts[i], timeStat.String()))
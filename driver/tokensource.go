@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A TokenSource provides JWT tokens for token based authentication.
+// Please see Connector.SetTokenSource for details.
+type TokenSource interface {
+	Token() (token string, err error)
+}
+
+// TokenSourceFunc is an adapter to allow the use of an ordinary function as TokenSource.
+type TokenSourceFunc func() (string, error)
+
+// Token implements the TokenSource interface.
+func (f TokenSourceFunc) Token() (string, error) { return f() }
+
+// fileTokenSource returns a token source reading the token from file.
+func fileTokenSource(file string) TokenSource {
+	return TokenSourceFunc(func() (string, error) {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	})
+}
+
+const (
+	tokenExpiryMargin = 30 * time.Second // tokens expiring within the margin are refreshed before connecting.
+	tokenMinBackoff   = time.Second      // minimum wait time after a failed token refresh.
+	tokenMaxBackoff   = time.Minute      // maximum wait time after a failed token refresh.
+)
+
+// jwtExpiry returns the expiry time of a JWT token (exp claim).
+func jwtExpiry(token string) (time.Time, bool) {
+	claims, ok := parseJWTClaims(token)
+	if !ok || claims.Exp == nil {
+		return time.Time{}, false
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	sec := int64(exp)
+	return time.Unix(sec, int64((exp-float64(sec))*1e9)), true
+}
+
+// jwtSubject returns the subject (sub claim) of a JWT token or an empty string
+// if the token does not provide a subject.
+func jwtSubject(token string) string {
+	claims, ok := parseJWTClaims(token)
+	if !ok {
+		return ""
+	}
+	return claims.Sub
+}
+
+type jwtClaims struct {
+	Sub string       `json:"sub"`
+	Exp *json.Number `json:"exp"`
+}
+
+// parseJWTClaims decodes the payload of a JWT token without verifying the signature.
+func parseJWTClaims(token string) (*jwtClaims, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, false
+	}
+	claims := &jwtClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, false
+	}
+	return claims, true
+}
+
+// tokenExpires returns true if the JWT token expires within the expiry margin.
+func tokenExpires(token string, now time.Time) bool {
+	expiry, ok := jwtExpiry(token)
+	return ok && !now.Before(expiry.Add(-tokenExpiryMargin))
+}
+
+// tokenCache caches the token of a token source until shortly before it expires.
+// Failed refreshes are retried with exponential backoff.
+type tokenCache struct {
+	mu      sync.Mutex
+	src     TokenSource
+	now     func() time.Time
+	token   string
+	expiry  time.Time // zero if token does not provide an expiry time
+	stale   bool      // token was rejected by the database
+	backoff time.Duration
+	nextTry time.Time
+	lastErr error
+}
+
+func newTokenCache(src TokenSource) *tokenCache { return &tokenCache{src: src, now: time.Now} }
+
+// valid returns true if the cached token can be used at time t.
+func (c *tokenCache) valid(t time.Time) bool {
+	return c.token != "" && !c.stale && (c.expiry.IsZero() || t.Before(c.expiry))
+}
+
+// get returns the cached token or a new token of the token source if the cached token is about to expire.
+func (c *tokenCache) get(m *metrics) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.valid(now.Add(tokenExpiryMargin)) {
+		return c.token, nil
+	}
+
+	if now.Before(c.nextTry) { // backoff
+		if c.valid(now) {
+			return c.token, nil
+		}
+		return "", fmt.Errorf("token source: retry in %s: %w", c.nextTry.Sub(now).Round(time.Millisecond), c.lastErr)
+	}
+
+	m.addCounterValue(counterTokenRefresh, 1)
+	token, err := c.src.Token()
+	if err == nil && token == "" {
+		err = fmt.Errorf("empty token")
+	}
+	if err != nil {
+		m.addCounterValue(counterTokenRefreshError, 1)
+		c.backoff *= 2
+		if c.backoff < tokenMinBackoff {
+			c.backoff = tokenMinBackoff
+		}
+		if c.backoff > tokenMaxBackoff {
+			c.backoff = tokenMaxBackoff
+		}
+		c.nextTry = now.Add(c.backoff)
+		c.lastErr = err
+		if c.valid(now) { // use cached token as long as it is valid
+			return c.token, nil
+		}
+		return "", fmt.Errorf("token source: %w", err)
+	}
+
+	c.token, c.stale = token, false
+	c.expiry, _ = jwtExpiry(token)
+	c.backoff, c.nextTry, c.lastErr = 0, time.Time{}, nil
+	return c.token, nil
+}
+
+// invalidate marks the cached token as rejected so that the next get call refreshes the token.
+func (c *tokenCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stale = true
+}
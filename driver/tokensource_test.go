@@ -0,0 +1,145 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func testJWT(exp int64) string {
+	enc := base64.RawURLEncoding
+	return fmt.Sprintf("%s.%s.%s",
+		enc.EncodeToString([]byte(`{"alg":"none"}`)),
+		enc.EncodeToString([]byte(fmt.Sprintf(`{"sub":"test","exp":%d}`, exp))),
+		enc.EncodeToString([]byte("signature")),
+	)
+}
+
+func testJWTExpiry(t *testing.T) {
+	exp := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	expiry, ok := jwtExpiry(testJWT(exp.Unix()))
+	if !ok || !expiry.Equal(exp) {
+		t.Fatalf("expiry %s ok %t - expected %s", expiry, ok, exp)
+	}
+
+	for _, token := range []string{"", "invalid", "a.b.c", "eyJhbGciOiJub25lIn0.eyJzdWIiOiJ0ZXN0In0.c2ln"} {
+		if _, ok := jwtExpiry(token); ok {
+			t.Fatalf("token %q: unexpected expiry", token)
+		}
+	}
+
+	if tokenExpires(testJWT(exp.Unix()), exp.Add(-time.Hour)) {
+		t.Fatal("token should not expire")
+	}
+	if !tokenExpires(testJWT(exp.Unix()), exp.Add(-tokenExpiryMargin/2)) {
+		t.Fatal("token should expire")
+	}
+	if tokenExpires("no jwt", exp) {
+		t.Fatal("token without expiry should not expire")
+	}
+}
+
+type testTokenSource struct {
+	calls int
+	exp   int64
+	err   error
+}
+
+func (s *testTokenSource) Token() (string, error) {
+	s.calls++
+	if s.err != nil {
+		return "", s.err
+	}
+	return testJWT(s.exp), nil
+}
+
+func testTokenCache(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	src := &testTokenSource{exp: now.Add(time.Hour).Unix()}
+	m := newMetrics(nil, nil)
+
+	c := newTokenCache(src)
+	c.now = func() time.Time { return now }
+
+	get := func() (string, error) {
+		t.Helper()
+		return c.get(m)
+	}
+
+	// initial token
+	token, err := get()
+	if err != nil || token != testJWT(src.exp) || src.calls != 1 {
+		t.Fatalf("token %s error %v calls %d", token, err, src.calls)
+	}
+	// cached token
+	if _, err := get(); err != nil || src.calls != 1 {
+		t.Fatalf("error %v calls %d - expected cached token", err, src.calls)
+	}
+
+	// token about to expire: refresh fails - cached token is used
+	now = now.Add(time.Hour - tokenExpiryMargin/2)
+	src.err = errors.New("test error")
+	if token2, err := get(); err != nil || token2 != token || src.calls != 2 {
+		t.Fatalf("error %v calls %d - expected cached token", err, src.calls)
+	}
+	// backoff: no token source call
+	if _, err := get(); err != nil || src.calls != 2 {
+		t.Fatalf("error %v calls %d - expected backoff", err, src.calls)
+	}
+
+	// token expired after backoff: token source error
+	now = now.Add(tokenExpiryMargin)
+	if _, err := get(); !errors.Is(err, src.err) || src.calls != 3 {
+		t.Fatalf("error %v calls %d - expected token source error", err, src.calls)
+	}
+	if c.backoff != 2*tokenMinBackoff {
+		t.Fatalf("backoff %s - expected %s", c.backoff, 2*tokenMinBackoff)
+	}
+	// token expired and backoff: error without token source call
+	now = now.Add(tokenMinBackoff)
+	if _, err := get(); err == nil || src.calls != 3 {
+		t.Fatalf("error %v calls %d - expected backoff error", err, src.calls)
+	}
+
+	// successful refresh resets backoff
+	now = now.Add(c.backoff)
+	src.err = nil
+	src.exp = now.Add(time.Hour).Unix()
+	if token, err := get(); err != nil || token != testJWT(src.exp) || src.calls != 4 || c.backoff != 0 {
+		t.Fatalf("token %s error %v calls %d backoff %s", token, err, src.calls, c.backoff)
+	}
+
+	// invalidated token gets refreshed
+	c.invalidate()
+	if _, err := get(); err != nil || src.calls != 5 {
+		t.Fatalf("error %v calls %d - expected refresh", err, src.calls)
+	}
+
+	stats := m.stats()
+	if stats.TokenRefreshes != 5 || stats.TokenRefreshErrors != 2 {
+		t.Fatalf("refreshes %d errors %d - expected 5 and 2", stats.TokenRefreshes, stats.TokenRefreshErrors)
+	}
+}
+
+func TestTokenSource(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"jwtExpiry", testJWTExpiry},
+		{"tokenCache", testTokenCache},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
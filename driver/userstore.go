@@ -0,0 +1,304 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// EnvUserStore is the environment variable overriding the default user store directory (see DefaultUserStoreDir).
+const EnvUserStore = "HDB_USERSTORE"
+
+const (
+	userStoreDataFile = "userstore.dat"
+	userStoreKeyFile  = "userstore.key"
+	userStoreKeySize  = 32 // AES-256
+)
+
+// ErrUserStoreKeyNotFound is returned if a user store key does not exist.
+var ErrUserStoreKeyNotFound = errors.New("user store key not found")
+
+// DefaultUserStoreDir returns the user store directory defined by the environment variable EnvUserStore
+// or, if not set, the directory go-hdb in the user configuration directory (see os.UserConfigDir).
+func DefaultUserStoreDir() (string, error) {
+	if dir, ok := os.LookupEnv(EnvUserStore); ok && dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go-hdb"), nil
+}
+
+/*
+A UserStoreEntry holds the connection data of a user store key.
+Credentials are either a password or references to certificate or token files.
+*/
+type UserStoreEntry struct {
+	Host                string `json:"host"`
+	Username            string `json:"username,omitempty"`
+	Password            string `json:"password,omitempty"`
+	DefaultSchema       string `json:"defaultSchema,omitempty"`
+	ClientCertFile      string `json:"clientCertFile,omitempty"`
+	ClientKeyFile       string `json:"clientKeyFile,omitempty"`
+	PKCS12File          string `json:"pkcs12File,omitempty"`
+	ClientKeyPassphrase string `json:"clientKeyPassphrase,omitempty"`
+	TokenFile           string `json:"tokenFile,omitempty"`
+}
+
+// Config returns a connector configuration based on the entry (see NewConnectorFromConfig).
+func (e *UserStoreEntry) Config() *Config {
+	return &Config{
+		Host:                e.Host,
+		Username:            e.Username,
+		Password:            e.Password,
+		DefaultSchema:       e.DefaultSchema,
+		ClientCertFile:      e.ClientCertFile,
+		ClientKeyFile:       e.ClientKeyFile,
+		PKCS12File:          e.PKCS12File,
+		ClientKeyPassphrase: e.ClientKeyPassphrase,
+		TokenFile:           e.TokenFile,
+	}
+}
+
+/*
+A UserStore is an encrypted local credential store keyed by name, so that passwords do not need to be part
+of DSNs or configuration files. The entries are encrypted with AES-256-GCM by a random key which is created
+together with the store. Both the encrypted data file and the key file are only accessible by the owner
+(permission 0600), the store directory is created with permission 0700.
+
+The encryption protects the credentials against accidental disclosure, e.g. by copying the data file.
+The key file (userstore.key) is stored next to the data file, so backups only protect the credentials if
+the key file is excluded from the backup - a backup containing both files discloses the credentials.
+The encryption does not protect against other processes running as the same operating system user.
+
+Updates of concurrent processes are serialized by a lock file (userstore.dat.lock).
+*/
+type UserStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// OpenUserStore opens the user store in directory dir. The directory is created if it does not exist.
+func OpenUserStore(dir string) (*UserStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &UserStore{dir: dir}, nil
+}
+
+// OpenDefaultUserStore opens the user store in the default directory (see DefaultUserStoreDir).
+func OpenDefaultUserStore() (*UserStore, error) {
+	dir, err := DefaultUserStoreDir()
+	if err != nil {
+		return nil, err
+	}
+	return OpenUserStore(dir)
+}
+
+// Dir returns the directory of the user store.
+func (s *UserStore) Dir() string { return s.dir }
+
+func (s *UserStore) dataPath() string { return filepath.Join(s.dir, userStoreDataFile) }
+func (s *UserStore) keyPath() string  { return filepath.Join(s.dir, userStoreKeyFile) }
+
+// aead returns the store cipher. If create is true a new key is created in case the store does not have one yet.
+func (s *UserStore) aead(create bool) (cipher.AEAD, error) {
+	key, err := os.ReadFile(s.keyPath())
+	switch {
+	case errors.Is(err, fs.ErrNotExist) && create:
+		if _, err := os.Stat(s.dataPath()); err == nil {
+			return nil, fmt.Errorf("user store key file %s is missing", s.keyPath())
+		}
+		if key, err = s.createKey(); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	}
+	if len(key) != userStoreKeySize {
+		return nil, fmt.Errorf("invalid user store key file %s", s.keyPath())
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// createKey creates a new key file exclusively (O_EXCL), so that processes creating the store
+// concurrently cannot overwrite each other's key. If the key file exists already the existing key is returned.
+func (s *UserStore) createKey() ([]byte, error) {
+	key := make([]byte, userStoreKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(s.keyPath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return os.ReadFile(s.keyPath())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(key); err != nil {
+		f.Close()
+		os.Remove(s.keyPath())
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(s.keyPath())
+		return nil, err
+	}
+	return key, nil
+}
+
+// update reads the entries, applies fn and writes the entries while holding the file lock.
+func (s *UserStore) update(fn func(entries map[string]*UserStoreEntry) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(s.dataPath())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	entries, err := s.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(entries); err != nil {
+		return err
+	}
+	return s.write(entries)
+}
+
+func (s *UserStore) read() (map[string]*UserStoreEntry, error) {
+	data, err := os.ReadFile(s.dataPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]*UserStoreEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	aead, err := s.aead(false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("invalid user store data file %s", s.dataPath())
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt user store data file %s: %w", s.dataPath(), err)
+	}
+	entries := map[string]*UserStoreEntry{}
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return nil, fmt.Errorf("invalid user store data file %s: %w", s.dataPath(), err)
+	}
+	return entries, nil
+}
+
+func (s *UserStore) write(entries map[string]*UserStoreEntry) error {
+	plaintext, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	aead, err := s.aead(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	return writeFileAtomic(s.dataPath(), aead.Seal(nonce, nonce, plaintext, nil))
+}
+
+// Keys returns the sorted user store keys.
+func (s *UserStore) Keys() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Get returns the entry of key. ErrUserStoreKeyNotFound is returned if key does not exist.
+func (s *UserStore) Get(key string) (*UserStoreEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	e, ok := entries[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUserStoreKeyNotFound, key)
+	}
+	return e, nil
+}
+
+// Set validates the entry (see Config.Validate) and adds or replaces the entry of key.
+func (s *UserStore) Set(key string, e *UserStoreEntry) error {
+	if key == "" {
+		return errors.New("invalid empty user store key")
+	}
+	if err := e.Config().Validate(); err != nil {
+		return err
+	}
+	return s.update(func(entries map[string]*UserStoreEntry) error {
+		entry := *e
+		entries[key] = &entry
+		return nil
+	})
+}
+
+// Delete deletes the entry of key. ErrUserStoreKeyNotFound is returned if key does not exist.
+func (s *UserStore) Delete(key string) error {
+	return s.update(func(entries map[string]*UserStoreEntry) error {
+		if _, ok := entries[key]; !ok {
+			return fmt.Errorf("%w: %s", ErrUserStoreKeyNotFound, key)
+		}
+		delete(entries, key)
+		return nil
+	})
+}
+
+// NewConnector creates a connector based on the entry of key.
+func (s *UserStore) NewConnector(key string) (*Connector, error) {
+	e, err := s.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return NewConnectorFromConfig(e.Config())
+}
+
+// NewUserStoreConnector creates a connector based on the entry of key in the default user store (see DefaultUserStoreDir).
+func NewUserStoreConnector(key string) (*Connector, error) {
+	s, err := OpenDefaultUserStore()
+	if err != nil {
+		return nil, err
+	}
+	return s.NewConnector(key)
+}
@@ -0,0 +1,193 @@
+// SPDX-FileCopyrightText: 2014-2022 SAP SE
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func testUserStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+	s, err := OpenUserStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keys, err := s.Keys(); err != nil || len(keys) != 0 {
+		t.Fatalf("keys %v error %v - expected empty store", keys, err)
+	}
+
+	e1 := &UserStoreEntry{Host: "localhost:39013", Username: "myuser", Password: "mypassword"}
+	e2 := &UserStoreEntry{Host: "otherhost:30015", Username: "other", Password: "otherpassword", DefaultSchema: "MYSCHEMA"}
+	if err := s.Set("prod", e1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("dev", e2); err != nil {
+		t.Fatal(err)
+	}
+
+	// password must not be stored in plain text
+	data, err := os.ReadFile(filepath.Join(dir, userStoreDataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("mypassword")) || bytes.Contains(data, []byte("myuser")) {
+		t.Fatal("user store data not encrypted")
+	}
+	for _, name := range []string{userStoreDataFile, userStoreKeyFile} {
+		fi, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := fi.Mode().Perm(); perm&0077 != 0 {
+			t.Fatalf("file %s permission %o - expected owner access only", name, perm)
+		}
+	}
+
+	// reopen
+	if s, err = OpenUserStore(dir); err != nil {
+		t.Fatal(err)
+	}
+	if keys, err := s.Keys(); err != nil || !reflect.DeepEqual(keys, []string{"dev", "prod"}) {
+		t.Fatalf("keys %v error %v - expected [dev prod]", keys, err)
+	}
+	e, err := s.Get("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(e, e1) {
+		t.Fatalf("entry %#v - expected %#v", e, e1)
+	}
+
+	c, err := s.NewConnector("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Host() != e2.Host || c.Username() != e2.Username || c.Password() != e2.Password || c.DefaultSchema() != e2.DefaultSchema {
+		t.Fatal("unexpected connector settings")
+	}
+
+	if err := s.Delete("dev"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("dev"); !errors.Is(err, ErrUserStoreKeyNotFound) {
+		t.Fatalf("expected error %v - got %v", ErrUserStoreKeyNotFound, err)
+	}
+	if err := s.Delete("dev"); !errors.Is(err, ErrUserStoreKeyNotFound) {
+		t.Fatalf("expected error %v - got %v", ErrUserStoreKeyNotFound, err)
+	}
+
+	// invalid entries
+	var cfgErr *ConfigError
+	if err := s.Set("invalid", &UserStoreEntry{Host: "localhost:39013", Password: "mypassword"}); !errors.As(err, &cfgErr) {
+		t.Fatalf("expected config error - got %v", err)
+	}
+	if err := s.Set("", e1); err == nil {
+		t.Fatal("expected empty key error")
+	}
+
+	// default user store
+	t.Setenv(EnvUserStore, dir)
+	if c, err = NewUserStoreConnector("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host() != e1.Host || c.Password() != e1.Password {
+		t.Fatal("unexpected connector settings")
+	}
+}
+
+func testUserStoreKey(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenUserStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("prod", &UserStoreEntry{Host: "localhost:39013", Username: "myuser", Password: "mypassword"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// different key
+	if err := os.WriteFile(filepath.Join(dir, userStoreKeyFile), make([]byte, userStoreKeySize), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("prod"); err == nil {
+		t.Fatal("expected decryption error")
+	}
+
+	// missing key
+	if err := os.Remove(filepath.Join(dir, userStoreKeyFile)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Keys(); err == nil {
+		t.Fatal("expected missing key error")
+	}
+	if err := s.Delete("prod"); err == nil {
+		t.Fatal("expected missing key error")
+	}
+}
+
+func testUserStoreConcurrent(t *testing.T) {
+	const numWorker = 10
+
+	// concurrent store instances (processes) creating the store share one key and do not lose updates
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	errs := make(chan error, numWorker)
+	for i := 0; i < numWorker; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := OpenUserStore(dir)
+			if err != nil {
+				errs <- err
+				return
+			}
+			errs <- s.Set(fmt.Sprintf("key%d", i), &UserStoreEntry{Host: "localhost:39013", Username: "myuser", Password: "mypassword"})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := OpenUserStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := s.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != numWorker {
+		t.Fatalf("number of keys %d - expected %d", len(keys), numWorker)
+	}
+}
+
+func TestUserStore(t *testing.T) {
+	tests := []struct {
+		name string
+		fct  func(t *testing.T)
+	}{
+		{"userStore", testUserStore},
+		{"userStoreKey", testUserStoreKey},
+		{"userStoreConcurrent", testUserStoreConcurrent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.fct(t)
+		})
+	}
+}
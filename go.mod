@@ -6,6 +6,7 @@ require (
 	github.com/prometheus/client_golang v1.13.0
 	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d
 	golang.org/x/exp v0.0.0-20220713135740-79cabaa25d75
+	golang.org/x/term v0.0.0-20220722155259-a9ba230a4035
 	golang.org/x/text v0.3.7
 )
 